@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+)
+
+const configUsage = `usage: config print
+
+commands:
+  print  list the effective value of every setting and whether it came from the default,
+         the config file (-config or CONFIG_FILE) or an environment variable`
+
+// ConfigPrinter shows the service's configuration
+type ConfigPrinter struct {
+	Path string
+	Out  io.Writer
+}
+
+// NewConfigPrinter creates a ConfigPrinter for the config file at path, which may be empty
+func NewConfigPrinter(path string, out io.Writer) *ConfigPrinter {
+	return &ConfigPrinter{Path: path, Out: out}
+}
+
+// Run executes the config command described by args
+func (c *ConfigPrinter) Run(args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errors.New(configUsage)
+	}
+
+	settings, err := config.Settings(c.Path)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(c.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tVALUE\tSOURCE")
+	for _, s := range settings {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Name, s.Value, s.Source)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConfigPrinter(t *testing.T) {
+	Convey("Given a config file", t, func() {
+		path := filepath.Join(t.TempDir(), "config.toml")
+		So(os.WriteFile(path, []byte("site_domain = \"ons.localhost\"\nservice_auth_token = \"secret\"\n"), 0o600), ShouldBeNil)
+
+		out := &bytes.Buffer{}
+		cmd := NewConfigPrinter(path, out)
+
+		Convey("When the config is printed", func() {
+			err := cmd.Run([]string{"print"})
+
+			Convey("Then every setting is shown with where it came from", func() {
+				So(err, ShouldBeNil)
+				So(out.String(), ShouldStartWith, "SETTING")
+				So(out.String(), ShouldContainSubstring, "ons.localhost")
+				So(out.String(), ShouldContainSubstring, "file\n")
+				So(out.String(), ShouldContainSubstring, "default\n")
+			})
+
+			Convey("Then the service auth token is not shown", func() {
+				So(out.String(), ShouldNotContainSubstring, "secret")
+			})
+		})
+
+		Convey("When another config command is run", func() {
+			err := cmd.Run([]string{"show"})
+
+			Convey("Then the usage is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldStartWith, "usage: config print")
+			})
+		})
+	})
+}
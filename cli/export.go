@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/export"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+)
+
+const exportUsage = `usage: export [flags]
+
+flags:
+  -format      csv or ndjson (default csv)
+  -o           file to write the export to instead of standard output
+  -since       only include feedback received on or after this date (YYYY-MM-DD or RFC3339)
+  -until       only include feedback received before this date (YYYY-MM-DD or RFC3339)
+  -type        only include page, whole-site or new-service feedback
+  -url-prefix  only include feedback about URLs starting with this prefix
+  -lang        only include feedback in this language
+  -tag         only include feedback with this tag, may be repeated`
+
+// Exporter writes stored feedback to a file or standard output
+type Exporter struct {
+	Store *store.Store
+	Out   io.Writer
+}
+
+// NewExporter creates an Exporter for the feedback store in cfg
+func NewExporter(cfg *config.Config, out io.Writer) (*Exporter, error) {
+	if cfg.FeedbackStorePath == "" {
+		return nil, errors.New("no feedback store configured, set FEEDBACK_STORE_PATH")
+	}
+	st, err := store.New(cfg.FeedbackStorePath)
+	if err != nil {
+		return nil, err
+	}
+	return &Exporter{Store: st, Out: out}, nil
+}
+
+// Run executes the export command described by args
+func (e *Exporter) Run(_ context.Context, args []string) error {
+	var f export.Filter
+	var tags tagFlags
+
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	format := fs.String("format", export.FormatCSV, "")
+	output := fs.String("o", "", "")
+	fs.StringVar(&f.Since, "since", "", "")
+	fs.StringVar(&f.Until, "until", "", "")
+	fs.StringVar(&f.Type, "type", "", "")
+	fs.StringVar(&f.URLPrefix, "url-prefix", "", "")
+	fs.StringVar(&f.Lang, "lang", "", "")
+	fs.Var(&tags, "tag", "")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w\n\n%s", err, exportUsage)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v\n\n%s", fs.Args(), exportUsage)
+	}
+
+	if !export.IsValidFormat(*format) {
+		return fmt.Errorf("unknown export format %q\n\n%s", *format, exportUsage)
+	}
+	f.Tags = tags
+	filter, err := f.StoreFilter()
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = export.Write(e.Out, *format, e.Store, filter)
+		return err
+	}
+
+	file, err := os.OpenFile(*output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	n, err := export.Write(file, *format, e.Store, filter)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("export failed after %d items: %w", n, err)
+	}
+
+	fmt.Fprintf(e.Out, "exported %d items to %s\n", n, *output)
+	return nil
+}
+
+// tagFlags collects each use of a repeated flag
+type tagFlags []string
+
+func (t *tagFlags) String() string {
+	return strings.Join(*t, ",")
+}
+
+func (t *tagFlags) Set(value string) error {
+	*t = append(*t, value)
+	return nil
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a feedback store with tagged and untagged feedback", t, func() {
+		st, err := store.New(filepath.Join(t.TempDir(), "feedback"))
+		So(err, ShouldBeNil)
+		tagged, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "tagged"}, Tags: []string{"search"}}, "en")
+		untagged, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "untagged"}}, "en")
+
+		out := &bytes.Buffer{}
+		exporter := &Exporter{Store: st, Out: out}
+
+		Convey("When the export command is run with a tag", func() {
+			err := exporter.Run(ctx, []string{"-format", "ndjson", "-tag", "search"})
+
+			Convey("Then only the tagged feedback is written", func() {
+				So(err, ShouldBeNil)
+				So(out.String(), ShouldContainSubstring, tagged.ID)
+				So(out.String(), ShouldNotContainSubstring, untagged.ID)
+			})
+		})
+
+		Convey("When the export command is run with an output file", func() {
+			path := filepath.Join(t.TempDir(), "feedback.csv")
+			err := exporter.Run(ctx, []string{"-o", path})
+
+			Convey("Then every item is written to the file as CSV", func() {
+				So(err, ShouldBeNil)
+				So(out.String(), ShouldEqual, "exported 2 items to "+path+"\n")
+				b, err := os.ReadFile(path)
+				So(err, ShouldBeNil)
+				So(string(b), ShouldStartWith, "id,received_at,")
+				So(string(b), ShouldContainSubstring, untagged.ID)
+			})
+		})
+
+		Convey("When the export command is run with an unknown format", func() {
+			err := exporter.Run(ctx, []string{"-format", "xlsx"})
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
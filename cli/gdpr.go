@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/deadletter"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/gdpr"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+)
+
+const gdprUsage = `usage: gdpr -email address [flags]
+
+Writes all the feedback left with the email address as JSON, for a subject access request,
+or removes the contact details from it while keeping the feedback.
+
+flags:
+  -email         the email address of the person making the request
+  -o             file to write the feedback to instead of standard output
+  -erase         erase the name and email address from their feedback
+  -pseudonymise  replace the name and email address with a pseudonym shared by their feedback
+  -author        who is making the change, recorded against the feedback (default the current user)`
+
+// GDPR finds, exports and erases the contact details given with stored and dead-lettered feedback
+type GDPR struct {
+	Store       *store.Store
+	DeadLetters *deadletter.Store
+	Out         io.Writer
+}
+
+// NewGDPR creates a GDPR command for the feedback store and any dead-letter store in cfg
+func NewGDPR(cfg *config.Config, out io.Writer) (*GDPR, error) {
+	if cfg.FeedbackStorePath == "" {
+		return nil, errors.New("no feedback store configured, set FEEDBACK_STORE_PATH")
+	}
+	st, err := store.New(cfg.FeedbackStorePath)
+	if err != nil {
+		return nil, err
+	}
+	g := &GDPR{Store: st, Out: out}
+	if cfg.DeadLetterPath != "" {
+		g.DeadLetters = deadletter.New(cfg.DeadLetterPath)
+	}
+	return g, nil
+}
+
+// Run executes the gdpr command described by args
+func (g *GDPR) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("gdpr", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	email := fs.String("email", "", "")
+	output := fs.String("o", "", "")
+	erase := fs.Bool("erase", false, "")
+	pseudonymise := fs.Bool("pseudonymise", false, "")
+	author := fs.String("author", currentUser(), "")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w\n\n%s", err, gdprUsage)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v\n\n%s", fs.Args(), gdprUsage)
+	}
+
+	address, err := gdpr.ParseEmail(*email)
+	if err != nil {
+		return fmt.Errorf("%w\n\n%s", err, gdprUsage)
+	}
+
+	switch {
+	case *erase && *pseudonymise:
+		return fmt.Errorf("only one of -erase and -pseudonymise can be given\n\n%s", gdprUsage)
+	case *erase:
+		return g.erase(ctx, address, gdpr.ModeErase, *author)
+	case *pseudonymise:
+		return g.erase(ctx, address, gdpr.ModePseudonymise, *author)
+	}
+
+	if *output == "" {
+		_, err = gdpr.WriteSubjectAccess(g.Out, g.Store, g.DeadLetters, address, time.Now())
+		return err
+	}
+
+	file, err := os.OpenFile(*output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create subject access file: %w", err)
+	}
+	n, err := gdpr.WriteSubjectAccess(file, g.Store, g.DeadLetters, address, time.Now())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write subject access file: %w", err)
+	}
+
+	fmt.Fprintf(g.Out, "wrote %d items to %s\n", n, *output)
+	return nil
+}
+
+func (g *GDPR) erase(ctx context.Context, email, mode, author string) error {
+	if author == "" {
+		return fmt.Errorf("-author is required\n\n%s", gdprUsage)
+	}
+	n, err := gdpr.Erase(ctx, g.Store, g.DeadLetters, email, mode, author)
+	if err != nil {
+		return fmt.Errorf("erasure failed after %d items: %w", n, err)
+	}
+	fmt.Fprintf(g.Out, "%s contact details in %d items\n", pastTense[mode], n)
+	return nil
+}
+
+var pastTense = map[string]string{
+	gdpr.ModeErase:        "erased",
+	gdpr.ModePseudonymise: "pseudonymised",
+}
+
+// currentUser is the name of the user running the command, or empty if it cannot be found
+func currentUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGDPR(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a feedback store with feedback from several people", t, func() {
+		st, err := store.New(filepath.Join(t.TempDir(), "feedback"))
+		So(err, ShouldBeNil)
+		jo, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "census", Name: "Jo", EmailAddress: "jo@example.com"}}, "en")
+		sam, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "economy", EmailAddress: "sam@example.com"}}, "en")
+
+		out := &bytes.Buffer{}
+		cmd := &GDPR{Store: st, Out: out}
+
+		Convey("When the gdpr command is run with an email address", func() {
+			err := cmd.Run(ctx, []string{"-email", "jo@example.com"})
+
+			Convey("Then their feedback is written", func() {
+				So(err, ShouldBeNil)
+				So(out.String(), ShouldContainSubstring, jo.ID)
+				So(out.String(), ShouldNotContainSubstring, sam.ID)
+			})
+		})
+
+		Convey("When the gdpr command is run with an output file", func() {
+			path := filepath.Join(t.TempDir(), "jo.json")
+			err := cmd.Run(ctx, []string{"-email", "jo@example.com", "-o", path})
+
+			Convey("Then their feedback is written to the file", func() {
+				So(err, ShouldBeNil)
+				So(out.String(), ShouldEqual, "wrote 1 items to "+path+"\n")
+				b, err := os.ReadFile(path)
+				So(err, ShouldBeNil)
+				So(string(b), ShouldContainSubstring, jo.ID)
+			})
+		})
+
+		Convey("When the gdpr command is run to erase contact details", func() {
+			err := cmd.Run(ctx, []string{"-email", "jo@example.com", "-erase", "-author", "dpo"})
+
+			Convey("Then their contact details are removed", func() {
+				So(err, ShouldBeNil)
+				So(out.String(), ShouldEqual, "erased contact details in 1 items\n")
+				item, _ := st.Get(jo.ID)
+				So(item.Submission.Name, ShouldBeEmpty)
+				So(item.Erasure.Author, ShouldEqual, "dpo")
+			})
+		})
+
+		Convey("When the gdpr command is run with invalid flags", func() {
+			Convey("Then an error is returned", func() {
+				for _, args := range [][]string{{}, {"-email", "jo"}, {"-email", "jo@example.com", "-erase", "-pseudonymise"}} {
+					So(cmd.Run(ctx, args), ShouldNotBeNil)
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/deadletter"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/export"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/handlers"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+)
+
+const replayUsage = `usage: replay <command> [flags] [id ...]
+
+commands:
+  list   list dead-lettered feedback
+  show   print a dead-lettered submission in full
+  send   resend dead-lettered feedback to the Feedback API
+
+flags:
+  -since  only include feedback that failed on or after this date (YYYY-MM-DD or RFC3339)
+  -until  only include feedback that failed before this date (YYYY-MM-DD or RFC3339)
+  -dry-run  (send only) report what would be sent without sending it`
+
+// Replayer lists, inspects and resends feedback held in the dead-letter store
+type Replayer struct {
+	Store     *deadletter.Store
+	API       handlers.FeedbackAPIClient
+	AuthToken string
+	Out       io.Writer
+}
+
+// NewReplayer creates a Replayer for the dead-letter store and Feedback API in cfg
+func NewReplayer(cfg *config.Config, out io.Writer) (*Replayer, error) {
+	if cfg.DeadLetterPath == "" {
+		return nil, errors.New("no dead-letter store configured, set DEAD_LETTER_PATH")
+	}
+	return &Replayer{
+		Store:     deadletter.New(cfg.DeadLetterPath),
+		API:       submission.NewClient(feedbackAPI.New(cfg.APIRouterURL)),
+		AuthToken: cfg.ServiceAuthToken,
+		Out:       out,
+	}, nil
+}
+
+// Run executes the replay command described by args
+func (r *Replayer) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(replayUsage)
+	}
+
+	fs := flag.NewFlagSet("replay "+args[0], flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	since := fs.String("since", "", "")
+	until := fs.String("until", "", "")
+	dryRun := fs.Bool("dry-run", false, "")
+	if err := fs.Parse(args[1:]); err != nil {
+		return fmt.Errorf("%w\n\n%s", err, replayUsage)
+	}
+
+	filter, err := parseFilter(*since, *until)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		return r.list(filter)
+	case "show":
+		if fs.NArg() != 1 {
+			return errors.New("replay show requires exactly one id")
+		}
+		return r.show(fs.Arg(0))
+	case "send":
+		return r.send(ctx, filter, fs.Args(), *dryRun)
+	default:
+		return fmt.Errorf("unknown replay command %q\n\n%s", args[0], replayUsage)
+	}
+}
+
+func (r *Replayer) list(filter deadletter.Filter) error {
+	entries, err := r.Store.List(filter)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(r.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tFAILED AT\tSTATUS\tURL\tERROR")
+	for _, e := range entries {
+		// an entry edited by hand may have lost its feedback, which is shown so that it can be removed
+		onsURL := "(no feedback)"
+		if e.Feedback != nil {
+			onsURL = e.Feedback.OnsURL
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", e.ID, e.FailedAt.Format(time.RFC3339), e.StatusCode, onsURL, e.Error)
+	}
+	return tw.Flush()
+}
+
+func (r *Replayer) show(id string) error {
+	e, err := r.Store.Get(id)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(r.Out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(e)
+}
+
+func (r *Replayer) send(ctx context.Context, filter deadletter.Filter, ids []string, dryRun bool) error {
+	entries, err := r.Store.List(filter)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) > 0 {
+		entries, err = selectEntries(entries, ids)
+		if err != nil {
+			return err
+		}
+	}
+
+	opts := feedbackAPI.Options{AuthToken: r.AuthToken}
+	failed := 0
+	for _, e := range entries {
+		if e.Feedback == nil {
+			fmt.Fprintf(r.Out, "cannot send %s: it has no feedback\n", e.ID)
+			failed++
+			continue
+		}
+		if dryRun {
+			fmt.Fprintf(r.Out, "would send %s\n", e.ID)
+			continue
+		}
+		if sendErr := r.API.PostSubmission(ctx, e.Feedback, opts); sendErr != nil {
+			fmt.Fprintf(r.Out, "failed to send %s: %s\n", e.ID, sendErr.Error())
+			failed++
+			continue
+		}
+		fmt.Fprintf(r.Out, "sent %s\n", e.ID)
+
+		// each entry is removed as soon as it is sent, so stopping part way does not send it again
+		if err := r.Store.Remove(e.ID); err != nil {
+			return fmt.Errorf("feedback %s was resent but could not be removed from the dead-letter store: %w", e.ID, err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d dead-lettered submissions could not be sent", failed, len(entries))
+	}
+	return nil
+}
+
+// selectEntries returns the entries with the requested ids, in the order they were requested
+func selectEntries(entries []*deadletter.Entry, ids []string) ([]*deadletter.Entry, error) {
+	byID := make(map[string]*deadletter.Entry, len(entries))
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+
+	selected := make([]*deadletter.Entry, 0, len(ids))
+	for _, id := range ids {
+		e, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", deadletter.ErrNotFound, id)
+		}
+		selected = append(selected, e)
+	}
+	return selected, nil
+}
+
+func parseFilter(since, until string) (filter deadletter.Filter, err error) {
+	if filter.Since, err = export.ParseDate(since); err != nil {
+		return filter, fmt.Errorf("invalid -since: %w", err)
+	}
+	if filter.Until, err = export.ParseDate(until); err != nil {
+		return filter, fmt.Errorf("invalid -until: %w", err)
+	}
+	return filter, nil
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
+	sdkError "github.com/ONSdigital/dp-feedback-api/sdk/errors"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/deadletter"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/handlers"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a dead-letter store with two failed submissions", t, func() {
+		store := deadletter.New(filepath.Join(t.TempDir(), "dead-letter.jsonl"))
+		first, _ := store.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "first", OnsURL: "https://www.ons.gov.uk/one"}}, errors.New("timeout"), http.StatusGatewayTimeout)
+		second, _ := store.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "second"}}, errors.New("timeout"), http.StatusGatewayTimeout)
+
+		mockAPI := &handlers.FeedbackAPIClientMock{
+			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				return nil
+			},
+		}
+		out := &bytes.Buffer{}
+		replayer := &Replayer{Store: store, API: mockAPI, AuthToken: "token", Out: out}
+
+		Convey("When the list command is run", func() {
+			err := replayer.Run(ctx, []string{"list"})
+
+			Convey("Then both submissions are listed", func() {
+				So(err, ShouldBeNil)
+				So(out.String(), ShouldContainSubstring, first.ID)
+				So(out.String(), ShouldContainSubstring, second.ID)
+				So(out.String(), ShouldContainSubstring, "https://www.ons.gov.uk/one")
+			})
+		})
+
+		Convey("When the list command is filtered to a future date", func() {
+			err := replayer.Run(ctx, []string{"list", "-since", "2999-01-01"})
+
+			Convey("Then no submissions are listed", func() {
+				So(err, ShouldBeNil)
+				So(out.String(), ShouldNotContainSubstring, first.ID)
+				So(out.String(), ShouldNotContainSubstring, second.ID)
+			})
+		})
+
+		Convey("When the show command is run for a submission", func() {
+			err := replayer.Run(ctx, []string{"show", first.ID})
+
+			Convey("Then the full submission is printed", func() {
+				So(err, ShouldBeNil)
+				So(out.String(), ShouldContainSubstring, `"feedback": "first"`)
+				So(out.String(), ShouldContainSubstring, `"error": "timeout"`)
+			})
+		})
+
+		Convey("When the show command is run for an unknown submission", func() {
+			err := replayer.Run(ctx, []string{"show", "unknown"})
+
+			Convey("Then a not found error is returned", func() {
+				So(err, ShouldEqual, deadletter.ErrNotFound)
+			})
+		})
+
+		Convey("When the send command is run as a dry run", func() {
+			err := replayer.Run(ctx, []string{"send", "-dry-run"})
+
+			Convey("Then nothing is sent or removed", func() {
+				So(err, ShouldBeNil)
+				So(mockAPI.PostSubmissionCalls(), ShouldBeEmpty)
+				So(out.String(), ShouldContainSubstring, "would send "+first.ID)
+				entries, _ := store.List(deadletter.Filter{})
+				So(entries, ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When the send command is run for one submission", func() {
+			err := replayer.Run(ctx, []string{"send", second.ID})
+
+			Convey("Then only that submission is sent with the service auth token", func() {
+				So(err, ShouldBeNil)
+				So(mockAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+				So(mockAPI.PostSubmissionCalls()[0].S.Feedback.Feedback, ShouldEqual, "second")
+				So(mockAPI.PostSubmissionCalls()[0].Options.AuthToken, ShouldEqual, "token")
+			})
+
+			Convey("Then it is removed from the dead-letter store", func() {
+				entries, _ := store.List(deadletter.Filter{})
+				So(entries, ShouldHaveLength, 1)
+				So(entries[0].ID, ShouldEqual, first.ID)
+			})
+		})
+
+		Convey("When the send command is run and the feedback API rejects a submission", func() {
+			mockAPI.PostSubmissionFunc = func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				if s.Feedback.Feedback == "first" {
+					return &sdkError.StatusError{Err: errors.New("still down"), Code: http.StatusBadGateway}
+				}
+				return nil
+			}
+			err := replayer.Run(ctx, []string{"send"})
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("Then only the rejected submission remains in the dead-letter store", func() {
+				entries, _ := store.List(deadletter.Filter{})
+				So(entries, ShouldHaveLength, 1)
+				So(entries[0].ID, ShouldEqual, first.ID)
+			})
+		})
+
+		Convey("When the send command is run", func() {
+			var remaining [][]*deadletter.Entry
+			mockAPI.PostSubmissionFunc = func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				entries, _ := store.List(deadletter.Filter{})
+				remaining = append(remaining, entries)
+				return nil
+			}
+			err := replayer.Run(ctx, []string{"send"})
+
+			Convey("Then each submission is removed as soon as it is sent", func() {
+				So(err, ShouldBeNil)
+				So(remaining, ShouldHaveLength, 2)
+				So(remaining[0], ShouldHaveLength, 2)
+				So(remaining[1], ShouldHaveLength, 1)
+				So(remaining[1][0].ID, ShouldEqual, second.ID)
+			})
+		})
+
+		Convey("When an unknown command is run", func() {
+			err := replayer.Run(ctx, []string{"purge"})
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When an invalid date is given", func() {
+			err := replayer.Run(ctx, []string{"list", "-until", "yesterday"})
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+	Convey("Given a dead-letter file with an entry that has lost its feedback", t, func() {
+		path := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+		So(os.WriteFile(path, []byte(`{"id":"edited","error":"timeout","failed_at":"2024-03-14T12:00:00Z"}`+"\n"), 0o600), ShouldBeNil)
+		store := deadletter.New(path)
+		mockAPI := &handlers.FeedbackAPIClientMock{
+			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				return nil
+			},
+		}
+		out := &bytes.Buffer{}
+		replayer := &Replayer{Store: store, API: mockAPI, AuthToken: "token", Out: out}
+
+		Convey("When the list command is run", func() {
+			err := replayer.Run(ctx, []string{"list"})
+
+			Convey("Then the entry is listed as having no feedback", func() {
+				So(err, ShouldBeNil)
+				So(out.String(), ShouldContainSubstring, "edited")
+				So(out.String(), ShouldContainSubstring, "(no feedback)")
+			})
+		})
+
+		Convey("When the send command is run", func() {
+			err := replayer.Run(ctx, []string{"send"})
+
+			Convey("Then the entry is reported as not sent and kept", func() {
+				So(err, ShouldNotBeNil)
+				So(out.String(), ShouldContainSubstring, "cannot send edited: it has no feedback")
+				So(mockAPI.PostSubmissionCalls(), ShouldBeEmpty)
+				entries, err := store.List(deadletter.Filter{})
+				So(err, ShouldBeNil)
+				So(entries, ShouldHaveLength, 1)
+			})
+		})
+	})
+}
@@ -1,9 +1,18 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
 	"time"
 
+	"github.com/ONSdigital/dp-frontend-feedback-controller/draft"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/duplicate"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/flags"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/variant"
 	"github.com/kelseyhightower/envconfig"
 )
 
@@ -13,46 +22,222 @@ type Config struct {
 	BindAddr                    string         `envconfig:"BIND_ADDR"`
 	CacheUpdateInterval         *time.Duration `envconfig:"CACHE_UPDATE_INTERVAL"`
 	CensusTopicID               string         `envconfig:"CENSUS_TOPIC_ID"`
+	ConfigFile                  string         `envconfig:"CONFIG_FILE"`
+	ConfigReloadInterval        time.Duration  `envconfig:"CONFIG_RELOAD_INTERVAL"`
+	ContactRetentionPeriod      time.Duration  `envconfig:"CONTACT_RETENTION_PERIOD"`
+	CookieSecret                string         `envconfig:"COOKIE_SECRET"           json:"-"`
+	DeadLetterPath              string         `envconfig:"DEAD_LETTER_PATH"`
 	Debug                       bool           `envconfig:"DEBUG"`
+	DraftExpiry                 time.Duration  `envconfig:"DRAFT_EXPIRY"`
+	DuplicateAction             string         `envconfig:"DUPLICATE_ACTION"`
+	DuplicateHistorySize        int            `envconfig:"DUPLICATE_HISTORY_SIZE"`
+	DuplicateThreshold          float64        `envconfig:"DUPLICATE_THRESHOLD"`
+	DuplicateWindow             time.Duration  `envconfig:"DUPLICATE_WINDOW"`
 	EnableCensusTopicSubsection bool           `envconfig:"ENABLE_CENSUS_TOPIC_SUBSECTION"`
+	EnableFeedbackWizard        bool           `envconfig:"ENABLE_FEEDBACK_WIZARD"`
 	EnableNewNavBar             bool           `envconfig:"ENABLE_NEW_NAVBAR"`
+	FeatureFlagOverrides        bool           `envconfig:"FEATURE_FLAG_OVERRIDES"  reload:"true"`
+	FeatureFlags                []string       `envconfig:"FEATURE_FLAGS"           reload:"true"`
+	FeedbackFrom                string         `envconfig:"FEEDBACK_FROM"           reload:"true"`
+	FeedbackStorePath           string         `envconfig:"FEEDBACK_STORE_PATH"`
+	FormVariants                []string       `envconfig:"FORM_VARIANTS"`
 	GracefulShutdownTimeout     time.Duration  `envconfig:"GRACEFUL_SHUTDOWN_TIMEOUT"`
 	HealthCheckInterval         time.Duration  `envconfig:"HEALTHCHECK_INTERVAL"`
 	HealthCheckCriticalTimeout  time.Duration  `envconfig:"HEALTHCHECK_CRITICAL_TIMEOUT"`
+	IdempotencyCacheSize        int            `envconfig:"IDEMPOTENCY_CACHE_SIZE"`
+	IdempotencyWindow           time.Duration  `envconfig:"IDEMPOTENCY_WINDOW"`
 	IsPublishing                bool           `envconfig:"IS_PUBLISHING"`
+	MailHost                    string         `envconfig:"MAIL_HOST"`
+	MailPassword                string         `envconfig:"MAIL_PASSWORD"           json:"-"`
+	MailPort                    string         `envconfig:"MAIL_PORT"`
+	MailUser                    string         `envconfig:"MAIL_USER"`
 	PatternLibraryAssetsPath    string         `envconfig:"PATTERN_LIBRARY_ASSETS_PATH"`
-	ServiceAuthToken            string         `envconfig:"SERVICE_AUTH_TOKEN"   json:"-"`
+	ReplyTemplatesPath          string         `envconfig:"REPLY_TEMPLATES_PATH"`
+	RetentionInterval           time.Duration  `envconfig:"RETENTION_INTERVAL"`
+	RoutingRulesPath            string         `envconfig:"ROUTING_RULES_PATH"      reload:"true"`
+	ServiceAuthToken            string         `envconfig:"SERVICE_AUTH_TOKEN"      json:"-" reload:"true"`
 	SiteDomain                  string         `envconfig:"SITE_DOMAIN"`
 	SupportedLanguages          []string       `envconfig:"SUPPORTED_LANGUAGES"`
+	TaggingRulesPath            string         `envconfig:"TAGGING_RULES_PATH"      reload:"true"`
+	WidgetAllowedOrigins        []string       `envconfig:"WIDGET_ALLOWED_ORIGINS"  reload:"true"`
+	WizardSessionExpiry         time.Duration  `envconfig:"WIZARD_SESSION_EXPIRY"`
 	OTExporterOTLPEndpoint      string         `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT"`
 	OTServiceName               string         `envconfig:"OTEL_SERVICE_NAME"`
 	OTBatchTimeout              time.Duration  `envconfig:"OTEL_BATCH_TIMEOUT"`
 	OtelEnabled                 bool           `envconfig:"OTEL_ENABLED"`
 }
 
-var cfg *Config
 var RendererVersion = "v0.2.0" // default value, overridden at build
 
-// Get returns the default config with any modifications through environment
-// variables
+// FileEnvVar is the environment variable naming a config file, when one is not given with the -config flag
+const FileEnvVar = "CONFIG_FILE"
+
+// Get returns the default config with any modifications through the file named by CONFIG_FILE and environment
+// variables. Each call reads the config afresh, so callers should pass the config they are given to whatever needs it.
 func Get() (*Config, error) {
-	if cfg != nil {
-		return cfg, nil
+	return Read(os.Getenv(FileEnvVar))
+}
+
+// Read returns the default config with any modifications through the TOML file at path, if path is not empty, and
+// then environment variables
+func Read(path string) (*Config, error) {
+	loaded, _, err := load(path)
+	return loaded, err
+}
+
+// Reloaded returns a copy of c with the settings tagged `reload:"true"` taken from next, which can change while the
+// service runs. It also returns the environment variables of any other settings that differ in next, which need a
+// restart to change.
+func (c *Config) Reloaded(next *Config) (*Config, []string) {
+	reloaded := *c
+	v, nv := reflect.ValueOf(&reloaded).Elem(), reflect.ValueOf(next).Elem()
+	t := v.Type()
+
+	var fixed []string
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("reload") == "true" {
+			v.Field(i).Set(nv.Field(i))
+		} else if !reflect.DeepEqual(v.Field(i).Interface(), nv.Field(i).Interface()) {
+			fixed = append(fixed, t.Field(i).Tag.Get("envconfig"))
+		}
 	}
+	return &reloaded, fixed
+}
 
-	envCfg, err := get()
+// load reads the config, returning where each setting came from along with it
+func load(path string) (*Config, map[string]Source, error) {
+	cfg, err := get()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if envCfg.Debug {
-		envCfg.PatternLibraryAssetsPath = "http://localhost:9002/dist/assets"
+	sources := make(map[string]Source)
+	if path != "" {
+		fromFile := &Config{}
+		*fromFile = *cfg
+		names, err := readFile(fromFile, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		// environment variables were applied by get, so they are applied again over the file
+		if err := envconfig.Process("", fromFile); err != nil {
+			return nil, nil, err
+		}
+		cfg = fromFile
+		for _, name := range names {
+			sources[name] = SourceFile
+		}
+	}
+	for _, name := range envNames() {
+		if _, ok := os.LookupEnv(name); ok {
+			sources[name] = SourceEnv
+		}
+	}
+
+	if cfg.Debug {
+		cfg.PatternLibraryAssetsPath = "http://localhost:9002/dist/assets"
 	} else {
-		envCfg.PatternLibraryAssetsPath = fmt.Sprintf("//cdn.ons.gov.uk/dis-design-system-go/%s", RendererVersion)
+		cfg.PatternLibraryAssetsPath = fmt.Sprintf("//cdn.ons.gov.uk/dis-design-system-go/%s", RendererVersion)
+	}
+	sources["PATTERN_LIBRARY_ASSETS_PATH"] = SourceDerived
+
+	// the file may have been given with the -config flag rather than CONFIG_FILE
+	if cfg.ConfigFile != path {
+		cfg.ConfigFile = path
+		sources["CONFIG_FILE"] = SourceDerived
+	}
+
+	return cfg, sources, nil
+}
+
+// Validate checks the settings that envconfig accepts but the service cannot run with, returning every problem found
+// joined into one error. assetFn loads the service's assets, which must include a locale file for each supported
+// language.
+func (c *Config) Validate(assetFn func(name string) ([]byte, error)) error {
+	var errs []error
+
+	if c.SiteDomain == "" {
+		errs = append(errs, errors.New("SITE_DOMAIN must be set"))
+	}
+
+	if u, err := url.Parse(c.APIRouterURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("API_ROUTER_URL must be an absolute http or https URL, got %q", c.APIRouterURL))
+	}
+
+	if c.GracefulShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("GRACEFUL_SHUTDOWN_TIMEOUT must be positive, got %s", c.GracefulShutdownTimeout))
+	}
+
+	if c.HealthCheckInterval <= 0 {
+		errs = append(errs, fmt.Errorf("HEALTHCHECK_INTERVAL must be positive, got %s", c.HealthCheckInterval))
+	} else if c.HealthCheckCriticalTimeout < c.HealthCheckInterval {
+		errs = append(errs, fmt.Errorf("HEALTHCHECK_CRITICAL_TIMEOUT (%s) must not be shorter than HEALTHCHECK_INTERVAL (%s)", c.HealthCheckCriticalTimeout, c.HealthCheckInterval))
+	}
+
+	if len(c.SupportedLanguages) == 0 {
+		errs = append(errs, errors.New("SUPPORTED_LANGUAGES must list at least one language"))
+	}
+	for _, lang := range c.SupportedLanguages {
+		if _, err := assetFn(fmt.Sprintf("locales/service.%s.toml", lang)); err != nil {
+			errs = append(errs, fmt.Errorf("SUPPORTED_LANGUAGES includes %q, which has no locale file", lang))
+		}
+	}
+
+	if c.DraftExpiry < 0 {
+		errs = append(errs, fmt.Errorf("DRAFT_EXPIRY must not be negative, got %s", c.DraftExpiry))
+	}
+	if c.EnableFeedbackWizard && c.WizardSessionExpiry <= 0 {
+		errs = append(errs, fmt.Errorf("WIZARD_SESSION_EXPIRY must be positive when ENABLE_FEEDBACK_WIZARD is on, got %s", c.WizardSessionExpiry))
+	}
+
+	switch c.DuplicateAction {
+	case duplicate.ActionOff:
+	case duplicate.ActionFlag, duplicate.ActionDrop:
+		if c.DuplicateThreshold <= 0 || c.DuplicateThreshold > 1 {
+			errs = append(errs, fmt.Errorf("DUPLICATE_THRESHOLD must be greater than 0 and at most 1, got %v", c.DuplicateThreshold))
+		}
+		if c.DuplicateWindow <= 0 {
+			errs = append(errs, fmt.Errorf("DUPLICATE_WINDOW must be positive, got %s", c.DuplicateWindow))
+		}
+		if c.DuplicateHistorySize <= 0 {
+			errs = append(errs, fmt.Errorf("DUPLICATE_HISTORY_SIZE must be positive, got %d", c.DuplicateHistorySize))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("DUPLICATE_ACTION must be %s, %s or %s, got %q", duplicate.ActionOff, duplicate.ActionFlag, duplicate.ActionDrop, c.DuplicateAction))
+	}
+
+	if c.ContactRetentionPeriod < 0 {
+		errs = append(errs, fmt.Errorf("CONTACT_RETENTION_PERIOD must not be negative, got %s", c.ContactRetentionPeriod))
+	} else if c.ContactRetentionPeriod > 0 && c.RetentionInterval <= 0 {
+		errs = append(errs, fmt.Errorf("RETENTION_INTERVAL must be positive when CONTACT_RETENTION_PERIOD is set, got %s", c.RetentionInterval))
+	}
+
+	if c.IdempotencyCacheSize <= 0 {
+		errs = append(errs, fmt.Errorf("IDEMPOTENCY_CACHE_SIZE must be positive, got %d", c.IdempotencyCacheSize))
+	}
+	if c.IdempotencyWindow <= 0 {
+		errs = append(errs, fmt.Errorf("IDEMPOTENCY_WINDOW must be positive, got %s", c.IdempotencyWindow))
+	}
+
+	if c.DeadLetterPath != "" && !filepath.IsAbs(c.DeadLetterPath) {
+		errs = append(errs, fmt.Errorf("DEAD_LETTER_PATH must be an absolute path so the service and replay command share it, got %q", c.DeadLetterPath))
+	}
+
+	if c.CookieSecret != "" && len(c.CookieSecret) < draft.MinSecretLength {
+		errs = append(errs, fmt.Errorf("COOKIE_SECRET must be at least %d characters", draft.MinSecretLength))
+	} else if c.CookieSecret == "" && c.EnableFeedbackWizard {
+		errs = append(errs, errors.New("COOKIE_SECRET must be set when ENABLE_FEEDBACK_WIZARD is on, as the wizard keeps answers in a cookie sealed with it"))
+	}
+
+	if _, err := flags.Parse(c.FeatureFlags); err != nil {
+		errs = append(errs, fmt.Errorf("FEATURE_FLAGS is not valid: %w", err))
+	}
+
+	if _, err := variant.Parse(c.FormVariants); err != nil {
+		errs = append(errs, fmt.Errorf("FORM_VARIANTS is not valid: %w", err))
 	}
 
-	cfg = envCfg
-	return cfg, nil
+	return errors.Join(errs...)
 }
 
 func get() (*Config, error) {
@@ -60,16 +245,44 @@ func get() (*Config, error) {
 		APIRouterURL:                "http://localhost:23200/v1",
 		BindAddr:                    ":25200",
 		CensusTopicID:               "4445",
+		ConfigFile:                  "",
+		ConfigReloadInterval:        30 * time.Second,
+		ContactRetentionPeriod:      365 * 24 * time.Hour,
+		CookieSecret:                "",
+		DeadLetterPath:              "",
 		Debug:                       false,
+		DraftExpiry:                 30 * time.Minute,
+		DuplicateAction:             "flag",
+		DuplicateHistorySize:        50,
+		DuplicateThreshold:          0.9,
+		DuplicateWindow:             time.Hour,
 		EnableCensusTopicSubsection: false,
+		EnableFeedbackWizard:        false,
 		EnableNewNavBar:             false,
+		FeatureFlagOverrides:        false,
+		FeatureFlags:                []string{},
+		FeedbackFrom:                "",
+		FeedbackStorePath:           "",
+		FormVariants:                []string{},
 		GracefulShutdownTimeout:     5 * time.Second,
 		HealthCheckInterval:         30 * time.Second,
 		HealthCheckCriticalTimeout:  90 * time.Second,
+		IdempotencyCacheSize:        10000,
+		IdempotencyWindow:           10 * time.Minute,
 		IsPublishing:                false,
+		MailHost:                    "",
+		MailPassword:                "",
+		MailPort:                    "",
+		MailUser:                    "",
+		ReplyTemplatesPath:          "",
+		RetentionInterval:           time.Hour,
+		RoutingRulesPath:            "",
 		ServiceAuthToken:            "",
 		SiteDomain:                  "localhost",
 		SupportedLanguages:          []string{"en", "cy"},
+		TaggingRulesPath:            "",
+		WidgetAllowedOrigins:        []string{},
+		WizardSessionExpiry:         time.Hour,
 		OTExporterOTLPEndpoint:      "localhost:4317",
 		OTServiceName:               "dp-frontend-feedback-controller",
 		OTBatchTimeout:              5 * time.Second,
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,20 +21,269 @@ func TestConfig(t *testing.T) {
 				So(cfg.GracefulShutdownTimeout, ShouldEqual, 5*time.Second)
 				So(cfg.HealthCheckInterval, ShouldEqual, 30*time.Second)
 				So(cfg.HealthCheckCriticalTimeout, ShouldEqual, 90*time.Second)
+				So(cfg.IdempotencyCacheSize, ShouldEqual, 10000)
+				So(cfg.IdempotencyWindow, ShouldEqual, 10*time.Minute)
 				So(cfg.SiteDomain, ShouldEqual, "localhost")
 				So(cfg.Debug, ShouldEqual, false)
+				So(cfg.CookieSecret, ShouldEqual, "")
+				So(cfg.DraftExpiry, ShouldEqual, 30*time.Minute)
+				So(cfg.DuplicateAction, ShouldEqual, "flag")
+				So(cfg.DuplicateHistorySize, ShouldEqual, 50)
+				So(cfg.DuplicateThreshold, ShouldEqual, 0.9)
+				So(cfg.DuplicateWindow, ShouldEqual, time.Hour)
 				So(cfg.SupportedLanguages, ShouldResemble, []string{"en", "cy"})
+				So(cfg.TaggingRulesPath, ShouldEqual, "")
+				So(cfg.WidgetAllowedOrigins, ShouldBeEmpty)
+				So(cfg.EnableFeedbackWizard, ShouldBeFalse)
+				So(cfg.WizardSessionExpiry, ShouldEqual, time.Hour)
+				So(cfg.RoutingRulesPath, ShouldEqual, "")
 				So(cfg.IsPublishing, ShouldEqual, false)
 				So(cfg.EnableCensusTopicSubsection, ShouldEqual, false)
+				So(cfg.DeadLetterPath, ShouldEqual, "")
+				So(cfg.FeatureFlagOverrides, ShouldBeFalse)
+				So(cfg.FeatureFlags, ShouldBeEmpty)
+				So(cfg.FeedbackFrom, ShouldEqual, "")
+				So(cfg.FeedbackStorePath, ShouldEqual, "")
+				So(cfg.FormVariants, ShouldBeEmpty)
+				So(cfg.MailHost, ShouldEqual, "")
+				So(cfg.MailPassword, ShouldEqual, "")
+				So(cfg.MailPort, ShouldEqual, "")
+				So(cfg.MailUser, ShouldEqual, "")
+				So(cfg.ReplyTemplatesPath, ShouldEqual, "")
+				So(cfg.ContactRetentionPeriod, ShouldEqual, 365*24*time.Hour)
+				So(cfg.ConfigFile, ShouldEqual, "")
+				So(cfg.ConfigReloadInterval, ShouldEqual, 30*time.Second)
+				So(cfg.RetentionInterval, ShouldEqual, time.Hour)
 				So(cfg.OTExporterOTLPEndpoint, ShouldEqual, "localhost:4317")
 				So(cfg.OTServiceName, ShouldEqual, "dp-frontend-feedback-controller")
 				So(cfg.OTBatchTimeout, ShouldEqual, 5*time.Second)
 			})
 
-			Convey("Then a second call to config should return the same config", func() {
+			Convey("Then a second call to config should return an equal config that is not shared", func() {
 				newCfg, newErr := Get()
 				So(newErr, ShouldBeNil)
 				So(newCfg, ShouldResemble, cfg)
+				So(newCfg, ShouldNotPointTo, cfg)
+			})
+		})
+	})
+}
+
+func TestValidate(t *testing.T) {
+	// assetFn has locale files for English and Welsh only
+	assetFn := func(name string) ([]byte, error) {
+		switch name {
+		case "locales/service.en.toml", "locales/service.cy.toml":
+			return []byte{}, nil
+		}
+		return nil, fmt.Errorf("asset %s not found", name)
+	}
+
+	Convey("Given the default config", t, func() {
+		cfg, err := get()
+		So(err, ShouldBeNil)
+
+		Convey("Then it is valid", func() {
+			So(cfg.Validate(assetFn), ShouldBeNil)
+		})
+	})
+
+	tests := []struct {
+		name   string
+		change func(cfg *Config)
+		errors []string
+	}{
+		{
+			name:   "the site domain is empty",
+			change: func(cfg *Config) { cfg.SiteDomain = "" },
+			errors: []string{"SITE_DOMAIN must be set"},
+		},
+		{
+			name:   "the API router URL has no scheme",
+			change: func(cfg *Config) { cfg.APIRouterURL = "localhost:23200/v1" },
+			errors: []string{`API_ROUTER_URL must be an absolute http or https URL, got "localhost:23200/v1"`},
+		},
+		{
+			name:   "the API router URL has no host",
+			change: func(cfg *Config) { cfg.APIRouterURL = "http:///v1" },
+			errors: []string{"API_ROUTER_URL must be an absolute http or https URL"},
+		},
+		{
+			name:   "the graceful shutdown timeout is not positive",
+			change: func(cfg *Config) { cfg.GracefulShutdownTimeout = 0 },
+			errors: []string{"GRACEFUL_SHUTDOWN_TIMEOUT must be positive, got 0s"},
+		},
+		{
+			name:   "the health check interval is not positive",
+			change: func(cfg *Config) { cfg.HealthCheckInterval = -time.Second },
+			errors: []string{"HEALTHCHECK_INTERVAL must be positive, got -1s"},
+		},
+		{
+			name:   "the health check critical timeout is shorter than the interval",
+			change: func(cfg *Config) { cfg.HealthCheckCriticalTimeout = 10 * time.Second },
+			errors: []string{"HEALTHCHECK_CRITICAL_TIMEOUT (10s) must not be shorter than HEALTHCHECK_INTERVAL (30s)"},
+		},
+		{
+			name:   "no languages are supported",
+			change: func(cfg *Config) { cfg.SupportedLanguages = nil },
+			errors: []string{"SUPPORTED_LANGUAGES must list at least one language"},
+		},
+		{
+			name:   "a supported language has no locale file",
+			change: func(cfg *Config) { cfg.SupportedLanguages = []string{"en", "fr"} },
+			errors: []string{`SUPPORTED_LANGUAGES includes "fr", which has no locale file`},
+		},
+		{
+			name:   "a feature flag has a percentage over 100",
+			change: func(cfg *Config) { cfg.FeatureFlags = []string{"new-form:150"} },
+			errors: []string{`FEATURE_FLAGS is not valid: feature flag "new-form:150": percentage must be a whole number from 0 to 100`},
+		},
+		{
+			name:   "the draft expiry is negative",
+			change: func(cfg *Config) { cfg.DraftExpiry = -time.Minute },
+			errors: []string{"DRAFT_EXPIRY must not be negative, got -1m0s"},
+		},
+		{
+			name: "the feedback wizard is on with no session expiry",
+			change: func(cfg *Config) {
+				cfg.EnableFeedbackWizard = true
+				cfg.CookieSecret = strings.Repeat("s", 32)
+				cfg.WizardSessionExpiry = 0
+			},
+			errors: []string{"WIZARD_SESSION_EXPIRY must be positive when ENABLE_FEEDBACK_WIZARD is on, got 0s"},
+		},
+		{
+			name:   "the duplicate action is not known",
+			change: func(cfg *Config) { cfg.DuplicateAction = "block" },
+			errors: []string{`DUPLICATE_ACTION must be off, flag or drop, got "block"`},
+		},
+		{
+			name:   "the duplicate threshold is more than 1",
+			change: func(cfg *Config) { cfg.DuplicateThreshold = 1.5 },
+			errors: []string{"DUPLICATE_THRESHOLD must be greater than 0 and at most 1, got 1.5"},
+		},
+		{
+			name:   "the duplicate threshold is not positive",
+			change: func(cfg *Config) { cfg.DuplicateThreshold = 0 },
+			errors: []string{"DUPLICATE_THRESHOLD must be greater than 0 and at most 1, got 0"},
+		},
+		{
+			name:   "the duplicate window is not positive",
+			change: func(cfg *Config) { cfg.DuplicateWindow = 0 },
+			errors: []string{"DUPLICATE_WINDOW must be positive, got 0s"},
+		},
+		{
+			name:   "the duplicate history size is not positive",
+			change: func(cfg *Config) { cfg.DuplicateHistorySize = -1 },
+			errors: []string{"DUPLICATE_HISTORY_SIZE must be positive, got -1"},
+		},
+		{
+			name:   "the contact retention period is negative",
+			change: func(cfg *Config) { cfg.ContactRetentionPeriod = -time.Hour },
+			errors: []string{"CONTACT_RETENTION_PERIOD must not be negative, got -1h0m0s"},
+		},
+		{
+			name:   "contact details are erased but the retention interval is not positive",
+			change: func(cfg *Config) { cfg.RetentionInterval = 0 },
+			errors: []string{"RETENTION_INTERVAL must be positive when CONTACT_RETENTION_PERIOD is set, got 0s"},
+		},
+		{
+			name:   "the idempotency cache size is negative",
+			change: func(cfg *Config) { cfg.IdempotencyCacheSize = -1 },
+			errors: []string{"IDEMPOTENCY_CACHE_SIZE must be positive, got -1"},
+		},
+		{
+			name:   "the idempotency window is not positive",
+			change: func(cfg *Config) { cfg.IdempotencyWindow = 0 },
+			errors: []string{"IDEMPOTENCY_WINDOW must be positive, got 0s"},
+		},
+		{
+			name:   "the cookie secret is too short",
+			change: func(cfg *Config) { cfg.CookieSecret = "secret" },
+			errors: []string{"COOKIE_SECRET must be at least 32 characters"},
+		},
+		{
+			name:   "the feedback wizard is on without a cookie secret",
+			change: func(cfg *Config) { cfg.EnableFeedbackWizard = true },
+			errors: []string{"COOKIE_SECRET must be set when ENABLE_FEEDBACK_WIZARD is on"},
+		},
+		{
+			name:   "the dead-letter path is relative",
+			change: func(cfg *Config) { cfg.DeadLetterPath = "dead-letter.jsonl" },
+			errors: []string{`DEAD_LETTER_PATH must be an absolute path so the service and replay command share it, got "dead-letter.jsonl"`},
+		},
+		{
+			name:   "a form variant is not known",
+			change: func(cfg *Config) { cfg.FormVariants = []string{"control:50", "long:50"} },
+			errors: []string{`FORM_VARIANTS is not valid: form variant "long:50": name must be one of control, short`},
+		},
+		{
+			name: "several settings are wrong",
+			change: func(cfg *Config) {
+				cfg.SiteDomain = ""
+				cfg.GracefulShutdownTimeout = 0
+				cfg.SupportedLanguages = []string{"de", "fr"}
+			},
+			errors: []string{
+				"SITE_DOMAIN must be set",
+				"GRACEFUL_SHUTDOWN_TIMEOUT must be positive",
+				`SUPPORTED_LANGUAGES includes "de"`,
+				`SUPPORTED_LANGUAGES includes "fr"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		Convey("Given a config where "+tt.name, t, func() {
+			cfg, err := get()
+			So(err, ShouldBeNil)
+			tt.change(cfg)
+
+			Convey("When it is validated", func() {
+				err := cfg.Validate(assetFn)
+
+				Convey("Then every problem is reported on its own line", func() {
+					So(err, ShouldNotBeNil)
+					lines := strings.Split(err.Error(), "\n")
+					So(lines, ShouldHaveLength, len(tt.errors))
+					for i, expected := range tt.errors {
+						So(lines[i], ShouldStartWith, expected)
+					}
+				})
+			})
+		})
+	}
+}
+
+func TestReloaded(t *testing.T) {
+	Convey("Given the config the service started with", t, func() {
+		started, err := get()
+		So(err, ShouldBeNil)
+
+		Convey("When a config with different settings is reloaded", func() {
+			next := *started
+			next.BindAddr = ":9999"
+			next.SiteDomain = "ons.gov.uk"
+			next.ServiceAuthToken = "rotated"
+			next.WidgetAllowedOrigins = []string{"https://example.com"}
+			next.RoutingRulesPath = "routing.toml"
+
+			reloaded, fixed := started.Reloaded(&next)
+
+			Convey("Then only the settings that can change are taken from it", func() {
+				So(reloaded.ServiceAuthToken, ShouldEqual, "rotated")
+				So(reloaded.WidgetAllowedOrigins, ShouldResemble, []string{"https://example.com"})
+				So(reloaded.RoutingRulesPath, ShouldEqual, "routing.toml")
+				So(reloaded.BindAddr, ShouldEqual, started.BindAddr)
+				So(reloaded.SiteDomain, ShouldEqual, started.SiteDomain)
+			})
+
+			Convey("Then the settings that need a restart are listed", func() {
+				So(fixed, ShouldResemble, []string{"BIND_ADDR", "SITE_DOMAIN"})
+			})
+
+			Convey("Then the config in use is not changed", func() {
+				So(started.ServiceAuthToken, ShouldEqual, "")
 			})
 		})
 	})
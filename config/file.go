@@ -0,0 +1,188 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Source says where the value of a setting came from
+type Source string
+
+// The places a setting's value can come from, each overriding the one before
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceDerived Source = "derived"
+)
+
+// redacted replaces the values of secret settings, those left out of the config when it is logged
+const redacted = "********"
+
+// Setting is the effective value of a setting, named by its environment variable, and where it came from
+type Setting struct {
+	Name   string
+	Value  string
+	Source Source
+}
+
+// Settings returns every setting of the config read from the file at path and environment variables, in the order
+// they are declared, with secrets redacted
+func Settings(path string) ([]Setting, error) {
+	cfg, sources, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	settings := make([]Setting, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("envconfig")
+		source, ok := sources[name]
+		if !ok {
+			source = SourceDefault
+		}
+		value := formatValue(v.Field(i))
+		if t.Field(i).Tag.Get("json") == "-" && value != "" {
+			value = redacted
+		}
+		settings = append(settings, Setting{Name: name, Value: value, Source: source})
+	}
+	return settings, nil
+}
+
+// envNames returns the environment variable of each setting
+func envNames() []string {
+	t := reflect.TypeOf(Config{})
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		names = append(names, t.Field(i).Tag.Get("envconfig"))
+	}
+	return names
+}
+
+// readFile sets the fields of cfg from the TOML file at path, whose keys are the settings' environment variables in
+// any case. It returns the environment variables of the settings that were set.
+func readFile(cfg *Config, path string) ([]string, error) {
+	var values map[string]interface{}
+	if _, err := toml.DecodeFile(path, &values); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	v := reflect.ValueOf(cfg).Elem()
+	fields := make(map[string]int)
+	for i, name := range envNames() {
+		fields[name] = i
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var names []string
+	var errs []error
+	for _, key := range keys {
+		name := strings.ToUpper(key)
+		i, ok := fields[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("config file %s: unknown setting %q", path, key))
+			continue
+		}
+		if err := setValue(v.Field(i), values[key]); err != nil {
+			errs = append(errs, fmt.Errorf("config file %s: %s: %w", path, key, err))
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, errors.Join(errs...)
+}
+
+// setValue sets field to a value decoded from TOML, which must be of a matching type. Durations are strings in
+// time.Duration format.
+func setValue(field reflect.Value, value interface{}) error {
+	if field.Kind() == reflect.Ptr {
+		p := reflect.New(field.Type().Elem())
+		if err := setValue(p.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(p)
+		return nil
+	}
+
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a duration such as \"30s\", got %v", value)
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		if s, ok := value.(string); ok {
+			field.SetString(s)
+			return nil
+		}
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			field.SetBool(b)
+			return nil
+		}
+	case reflect.Int:
+		if n, ok := value.(int64); ok {
+			field.SetInt(n)
+			return nil
+		}
+	case reflect.Float64:
+		switch n := value.(type) {
+		case float64:
+			field.SetFloat(n)
+			return nil
+		case int64:
+			field.SetFloat(float64(n))
+			return nil
+		}
+	case reflect.Slice:
+		if items, ok := value.([]interface{}); ok {
+			list := make([]string, 0, len(items))
+			for _, item := range items {
+				s, ok := item.(string)
+				if !ok {
+					return fmt.Errorf("expected a list of strings, got %v", value)
+				}
+				list = append(list, s)
+			}
+			field.Set(reflect.ValueOf(list))
+			return nil
+		}
+	}
+	return fmt.Errorf("expected %s, got %v", field.Type(), value)
+}
+
+// formatValue writes a setting's value as it would be given in an environment variable
+func formatValue(field reflect.Value) string {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return ""
+		}
+		field = field.Elem()
+	}
+	if list, ok := field.Interface().([]string); ok {
+		return strings.Join(list, ",")
+	}
+	return fmt.Sprint(field.Interface())
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConfigFile(t *testing.T) {
+	writeFile := func(contents string) string {
+		path := filepath.Join(t.TempDir(), "config.toml")
+		So(os.WriteFile(path, []byte(contents), 0o600), ShouldBeNil)
+		return path
+	}
+
+	Convey("Given a config file", t, func() {
+		path := writeFile(`
+site_domain = "ons.localhost"
+GRACEFUL_SHUTDOWN_TIMEOUT = "10s"
+cache_update_interval = "1m"
+duplicate_threshold = 1
+idempotency_cache_size = 20
+enable_new_navbar = true
+supported_languages = ["en"]
+service_auth_token = "from-file"
+`)
+		t.Setenv("IDEMPOTENCY_CACHE_SIZE", "30")
+
+		Convey("When the config is loaded from it", func() {
+			cfg, sources, err := load(path)
+
+			Convey("Then the file's settings are used", func() {
+				So(err, ShouldBeNil)
+				So(cfg.SiteDomain, ShouldEqual, "ons.localhost")
+				So(cfg.GracefulShutdownTimeout, ShouldEqual, 10*time.Second)
+				So(*cfg.CacheUpdateInterval, ShouldEqual, time.Minute)
+				So(cfg.DuplicateThreshold, ShouldEqual, 1)
+				So(cfg.EnableNewNavBar, ShouldBeTrue)
+				So(cfg.SupportedLanguages, ShouldResemble, []string{"en"})
+				So(sources["SITE_DOMAIN"], ShouldEqual, SourceFile)
+			})
+
+			Convey("Then environment variables override the file", func() {
+				So(cfg.IdempotencyCacheSize, ShouldEqual, 30)
+				So(sources["IDEMPOTENCY_CACHE_SIZE"], ShouldEqual, SourceEnv)
+			})
+
+			Convey("Then settings in neither keep their defaults", func() {
+				So(cfg.BindAddr, ShouldEqual, ":25200")
+				So(sources, ShouldNotContainKey, "BIND_ADDR")
+			})
+		})
+
+		Convey("When its settings are listed", func() {
+			settings, err := Settings(path)
+			So(err, ShouldBeNil)
+			byName := make(map[string]Setting)
+			for _, s := range settings {
+				byName[s.Name] = s
+			}
+
+			Convey("Then every setting is listed with where its value came from", func() {
+				So(settings, ShouldHaveLength, len(envNames()))
+				So(settings[0].Name, ShouldEqual, "API_ROUTER_URL")
+				So(byName["SITE_DOMAIN"], ShouldResemble, Setting{Name: "SITE_DOMAIN", Value: "ons.localhost", Source: SourceFile})
+				So(byName["IDEMPOTENCY_CACHE_SIZE"], ShouldResemble, Setting{Name: "IDEMPOTENCY_CACHE_SIZE", Value: "30", Source: SourceEnv})
+				So(byName["BIND_ADDR"], ShouldResemble, Setting{Name: "BIND_ADDR", Value: ":25200", Source: SourceDefault})
+				So(byName["SUPPORTED_LANGUAGES"].Value, ShouldEqual, "en")
+				So(byName["GRACEFUL_SHUTDOWN_TIMEOUT"].Value, ShouldEqual, "10s")
+				So(byName["PATTERN_LIBRARY_ASSETS_PATH"].Source, ShouldEqual, SourceDerived)
+			})
+
+			Convey("Then secrets are redacted", func() {
+				So(byName["SERVICE_AUTH_TOKEN"].Value, ShouldEqual, "********")
+				So(byName["MAIL_PASSWORD"].Value, ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given config files that cannot be used", t, func() {
+		tests := []struct {
+			contents string
+			err      string
+		}{
+			{`site_domain = `, "failed to read config file"},
+			{`site_domian = "localhost"`, `unknown setting "site_domian"`},
+			{`graceful_shutdown_timeout = 5`, `graceful_shutdown_timeout: expected a duration such as "30s", got 5`},
+			{`graceful_shutdown_timeout = "5 seconds"`, "graceful_shutdown_timeout: time: unknown unit"},
+			{`debug = "yes"`, "debug: expected bool, got yes"},
+			{`supported_languages = [1, 2]`, "supported_languages: expected a list of strings"},
+		}
+
+		for _, tt := range tests {
+			Convey("When the config is loaded from "+tt.contents, func() {
+				_, _, err := load(writeFile(tt.contents))
+
+				Convey("Then the problem is reported", func() {
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldContainSubstring, tt.err)
+				})
+			})
+		}
+	})
+
+	Convey("Given a config file that does not exist", t, func() {
+		_, _, err := load(filepath.Join(t.TempDir(), "missing.toml"))
+
+		Convey("Then it cannot be loaded", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
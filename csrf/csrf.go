@@ -0,0 +1,50 @@
+package csrf
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// FormField is the form value that the token is posted in
+const FormField = "csrf-token"
+
+// purpose is signed with the user's access token to give the token, so it cannot be reused for anything else
+const purpose = "dp-frontend-feedback-controller csrf"
+
+// Token returns the token to embed in forms that are posted by the user making req. It is derived from the Florence
+// access token cookie, so only pages served to that user can know it. It is "" when there is no cookie.
+func Token(req *http.Request) string {
+	c, err := req.Cookie(dprequest.FlorenceCookieKey)
+	if err != nil || c.Value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(c.Value))
+	mac.Write([]byte(purpose))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Protect rejects posts that are authenticated by the Florence access token cookie without the token from Token,
+// which another site could make in the user's name. Posts that send their access token in a header cannot be
+// made by another site, so need no token.
+func Protect(handle func(http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost && !hasTokenHeader(req) {
+			expected := Token(req)
+			if expected != "" && !hmac.Equal([]byte(req.PostFormValue(FormField)), []byte(expected)) {
+				log.Warn(req.Context(), "rejecting post without a valid csrf token", log.Data{"path": req.URL.Path})
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+		}
+		handle(w, req)
+	}
+}
+
+func hasTokenHeader(req *http.Request) bool {
+	return req.Header.Get(dprequest.FlorenceHeaderKey) != "" || req.Header.Get(dprequest.AuthHeaderKey) != ""
+}
@@ -0,0 +1,107 @@
+package csrf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestToken(t *testing.T) {
+	Convey("Given requests from two users signed in to Florence", t, func() {
+		first := httptest.NewRequest("GET", "/feedback/moderation/1", http.NoBody)
+		first.AddCookie(&http.Cookie{Name: dprequest.FlorenceCookieKey, Value: "first-access-token"})
+		second := httptest.NewRequest("GET", "/feedback/moderation/1", http.NoBody)
+		second.AddCookie(&http.Cookie{Name: dprequest.FlorenceCookieKey, Value: "second-access-token"})
+
+		Convey("Then each user is given a different token that does not reveal their access token", func() {
+			So(Token(first), ShouldNotBeEmpty)
+			So(Token(first), ShouldEqual, Token(first))
+			So(Token(first), ShouldNotEqual, Token(second))
+			So(Token(first), ShouldNotContainSubstring, "first-access-token")
+		})
+	})
+
+	Convey("Given a request without the Florence cookie", t, func() {
+		req := httptest.NewRequest("GET", "/feedback/moderation/1", http.NoBody)
+
+		Convey("Then there is no token", func() {
+			So(Token(req), ShouldBeEmpty)
+		})
+	})
+}
+
+func TestProtect(t *testing.T) {
+	Convey("Given a protected handler", t, func() {
+		called := false
+		handler := Protect(func(w http.ResponseWriter, req *http.Request) {
+			called = true
+		})
+		cookie := &http.Cookie{Name: dprequest.FlorenceCookieKey, Value: "access-token"}
+		tokenReq := httptest.NewRequest("GET", "/", http.NoBody)
+		tokenReq.AddCookie(cookie)
+		token := Token(tokenReq)
+
+		post := func(form url.Values, prepare func(req *http.Request)) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/feedback/moderation/1", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			prepare(req)
+			w := httptest.NewRecorder()
+			handler(w, req)
+			return w
+		}
+
+		Convey("When a post authenticated by the cookie includes the token", func() {
+			w := post(url.Values{FormField: {token}}, func(req *http.Request) { req.AddCookie(cookie) })
+
+			Convey("Then it is handled", func() {
+				So(called, ShouldBeTrue)
+				So(w.Code, ShouldEqual, http.StatusOK)
+			})
+		})
+
+		Convey("When a post authenticated by the cookie has no token, as when it is made by another site", func() {
+			w := post(url.Values{"status": {"spam"}}, func(req *http.Request) { req.AddCookie(cookie) })
+
+			Convey("Then it is forbidden", func() {
+				So(called, ShouldBeFalse)
+				So(w.Code, ShouldEqual, http.StatusForbidden)
+			})
+		})
+
+		Convey("When a post authenticated by the cookie has the wrong token", func() {
+			w := post(url.Values{FormField: {"0123"}}, func(req *http.Request) { req.AddCookie(cookie) })
+
+			Convey("Then it is forbidden", func() {
+				So(called, ShouldBeFalse)
+				So(w.Code, ShouldEqual, http.StatusForbidden)
+			})
+		})
+
+		Convey("When a post sends the access token in a header", func() {
+			w := post(url.Values{}, func(req *http.Request) {
+				req.AddCookie(cookie)
+				req.Header.Set(dprequest.FlorenceHeaderKey, "access-token")
+			})
+
+			Convey("Then it is handled without a token", func() {
+				So(called, ShouldBeTrue)
+				So(w.Code, ShouldEqual, http.StatusOK)
+			})
+		})
+
+		Convey("When a post sends a service token", func() {
+			post(url.Values{}, func(req *http.Request) {
+				req.Header.Set(dprequest.AuthHeaderKey, dprequest.BearerPrefix+"service-token")
+			})
+
+			Convey("Then it is handled without a token", func() {
+				So(called, ShouldBeTrue)
+			})
+		})
+	})
+}
@@ -0,0 +1,124 @@
+package dashboard
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+)
+
+// TopURLCount is the number of most commented on pages included in a summary
+const TopURLCount = 10
+
+// SatisfiedRating is the lowest rating, on the feedback wizard's scale of 1 to 5, that counts as satisfied
+const SatisfiedRating = 4
+
+// Count is the number of items of feedback with a label, such as a type or language
+type Count struct {
+	Label string
+	Count int
+}
+
+// Week is the number of items of feedback received in the week starting on Monday Start
+type Week struct {
+	Start time.Time
+	Count int
+}
+
+// Summary is an overview of the feedback received over a number of weeks
+type Summary struct {
+	Since     time.Time
+	Until     time.Time
+	Total     int
+	Weeks     []Week
+	Types     []Count
+	Languages []Count
+	TopURLs   []Count
+	// Positive is the number of items with a positive sentiment, out of Scored
+	Positive int
+	Scored   int
+	// Satisfied is the number of items rated at least SatisfiedRating, out of Rated
+	Satisfied int
+	Rated     int
+}
+
+// WeekStart returns midnight UTC on the Monday of the week containing t
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
+
+// Summarise reads the feedback received in the given number of weeks up to and including the week containing now
+func Summarise(st *store.Store, weeks int, now time.Time) (*Summary, error) {
+	until := WeekStart(now).AddDate(0, 0, 7)
+	s := &Summary{
+		Since: until.AddDate(0, 0, -7*weeks),
+		Until: until,
+		Weeks: make([]Week, weeks),
+	}
+	for i := range s.Weeks {
+		s.Weeks[i].Start = s.Since.AddDate(0, 0, 7*i)
+	}
+
+	types := make(map[string]int)
+	languages := make(map[string]int)
+	urls := make(map[string]int)
+
+	err := st.Walk(store.Filter{Since: s.Since, Until: s.Until}, func(item *store.Item) error {
+		s.Total++
+		s.Weeks[int(item.ReceivedAt.Sub(s.Since)/(7*24*time.Hour))].Count++
+		types[item.Type()]++
+		languages[item.Lang]++
+
+		sub := item.Submission
+		if sub == nil {
+			return nil
+		}
+		if sub.OnsURL != "" {
+			urls[sub.OnsURL]++
+		}
+		if sub.Sentiment != nil {
+			s.Scored++
+			if *sub.Sentiment > 0 {
+				s.Positive++
+			}
+		}
+		if sub.Rating != nil {
+			s.Rated++
+			if *sub.Rating >= SatisfiedRating {
+				s.Satisfied++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range store.Types {
+		s.Types = append(s.Types, Count{Label: t, Count: types[t]})
+	}
+	s.Languages = sortedCounts(languages, 0)
+	s.TopURLs = sortedCounts(urls, TopURLCount)
+	return s, nil
+}
+
+// sortedCounts returns the counts largest first, up to limit unless limit is 0
+func sortedCounts(counts map[string]int, limit int) []Count {
+	sorted := make([]Count, 0, len(counts))
+	for label, n := range counts {
+		sorted = append(sorted, Count{Label: label, Count: n})
+	}
+	slices.SortFunc(sorted, func(a, b Count) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Label, b.Label)
+	})
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return sorted
+}
@@ -0,0 +1,90 @@
+package dashboard
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWeekStart(t *testing.T) {
+	Convey("When the start of a week is found", t, func() {
+		Convey("Then it is midnight on the Monday", func() {
+			monday := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+			So(WeekStart(monday), ShouldEqual, monday)
+			So(WeekStart(time.Date(2024, 3, 14, 15, 30, 0, 0, time.UTC)), ShouldEqual, monday)
+			So(WeekStart(time.Date(2024, 3, 17, 23, 59, 0, 0, time.UTC)), ShouldEqual, monday)
+		})
+	})
+}
+
+func TestSummarise(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC) // a Thursday
+
+	Convey("Given a store of feedback received over several weeks", t, func() {
+		st, err := store.New(filepath.Join(t.TempDir(), "feedback"))
+		So(err, ShouldBeNil)
+
+		isGeneral := true
+		positive, negative := 0.5, -0.5
+		rating := func(r int) *int { return &r }
+		add := func(receivedAt time.Time, lang string, sub *submission.Submission) {
+			item, err := st.Add(ctx, sub, lang)
+			So(err, ShouldBeNil)
+			_, err = st.Update(item.ID, func(i *store.Item) error {
+				i.ReceivedAt = receivedAt
+				return nil
+			})
+			So(err, ShouldBeNil)
+		}
+		census := "https://www.ons.gov.uk/census"
+		add(now, "en", &submission.Submission{Feedback: feedbackAPIModel.Feedback{OnsURL: census}, Sentiment: &positive, Rating: rating(5)})
+		add(now.AddDate(0, 0, -1), "cy", &submission.Submission{Feedback: feedbackAPIModel.Feedback{OnsURL: census}, Sentiment: &negative, Rating: rating(2)})
+		add(now.AddDate(0, 0, -7), "en", &submission.Submission{Feedback: feedbackAPIModel.Feedback{IsGeneralFeedback: &isGeneral}, Rating: rating(4)})
+		add(now.AddDate(0, 0, -8), "en", &submission.Submission{Service: "dev", Feedback: feedbackAPIModel.Feedback{OnsURL: "https://www.ons.gov.uk/economy"}})
+		add(now.AddDate(0, 0, -30), "en", &submission.Submission{Feedback: feedbackAPIModel.Feedback{OnsURL: census}})
+
+		Convey("When the last two weeks are summarised", func() {
+			s, err := Summarise(st, 2, now)
+			So(err, ShouldBeNil)
+
+			Convey("Then the period runs from the Monday a week ago to the end of this week", func() {
+				So(s.Since, ShouldEqual, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC))
+				So(s.Until, ShouldEqual, time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC))
+			})
+
+			Convey("Then only feedback from that period is counted, by week", func() {
+				So(s.Total, ShouldEqual, 4)
+				So(s.Weeks, ShouldResemble, []Week{
+					{Start: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), Count: 2},
+					{Start: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), Count: 2},
+				})
+			})
+
+			Convey("Then the feedback is broken down by type and language", func() {
+				So(s.Types, ShouldResemble, []Count{{Label: store.TypePage, Count: 2}, {Label: store.TypeWholeSite, Count: 1}, {Label: store.TypeNewService, Count: 1}})
+				So(s.Languages, ShouldResemble, []Count{{Label: "en", Count: 3}, {Label: "cy", Count: 1}})
+			})
+
+			Convey("Then the pages with the most feedback are listed first", func() {
+				So(s.TopURLs, ShouldResemble, []Count{{Label: census, Count: 2}, {Label: "https://www.ons.gov.uk/economy", Count: 1}})
+			})
+
+			Convey("Then sentiment is only counted where it was scored", func() {
+				So(s.Positive, ShouldEqual, 1)
+				So(s.Scored, ShouldEqual, 2)
+			})
+
+			Convey("Then satisfaction is counted from the ratings of 4 or 5, out of the feedback that was rated", func() {
+				So(s.Satisfied, ShouldEqual, 2)
+				So(s.Rated, ShouldEqual, 3)
+			})
+		})
+	})
+}
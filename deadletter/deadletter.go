@@ -0,0 +1,291 @@
+package deadletter
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// ErrNotFound is returned when no dead-lettered entry exists for the requested ID
+var ErrNotFound = errors.New("dead-letter entry not found")
+
+// Entry represents a feedback submission that could not be sent to the Feedback API
+type Entry struct {
+	ID         string                 `json:"id"`
+	Feedback   *submission.Submission `json:"feedback"`
+	Error      string                 `json:"error"`
+	StatusCode int                    `json:"status_code,omitempty"`
+	FailedAt   time.Time              `json:"failed_at"`
+}
+
+// Filter restricts the entries returned from the store. Zero values are ignored.
+type Filter struct {
+	Since time.Time
+	Until time.Time
+}
+
+// Matches is true when the entry failed within the bounds of the filter
+func (f Filter) Matches(e *Entry) bool {
+	if !f.Since.IsZero() && e.FailedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.FailedAt.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Store persists failed submissions as newline delimited JSON in a single file. The file is shared with the replay
+// command, so every change is made while holding a lock on the file beside it named with a .lock suffix.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// New creates a dead-letter store backed by the file at path
+func New(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the location of the dead-letter file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Add records a failed submission along with the error returned when sending it
+func (s *Store) Add(ctx context.Context, feedback *submission.Submission, sendErr error, statusCode int) (*Entry, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Entry{
+		ID:         id,
+		Feedback:   feedback,
+		StatusCode: statusCode,
+		FailedAt:   time.Now().UTC(),
+	}
+	if sendErr != nil {
+		e.Error = sendErr.Error()
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode dead-letter entry: %w", err)
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write dead-letter entry: %w", err)
+	}
+
+	log.Info(ctx, "feedback submission dead-lettered", log.Data{"id": e.ID, "path": s.path})
+	return e, nil
+}
+
+// List returns the entries matching the filter, oldest first
+func (s *Store) List(filter Filter) ([]*Entry, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]*Entry, 0, len(entries))
+	for _, e := range entries {
+		if filter.Matches(e) {
+			matched = append(matched, e)
+		}
+	}
+	return matched, nil
+}
+
+// Get returns the entry with the given ID
+func (s *Store) Get(id string) (*Entry, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Remove deletes the entries with the given IDs, typically once they have been resent
+func (s *Store) Remove(ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	remove := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	kept := make([]*Entry, 0, len(entries))
+	for _, e := range entries {
+		if !remove[e.ID] {
+			kept = append(kept, e)
+		}
+	}
+	return s.write(kept)
+}
+
+// Update calls change with each entry, oldest first, saving the entries it reports as changed. It returns the
+// number of entries changed.
+func (s *Store) Update(change func(e *Entry) bool) (int, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, e := range entries {
+		if change(e) {
+			changed++
+		}
+	}
+	if changed == 0 {
+		return 0, nil
+	}
+	return changed, s.write(entries)
+}
+
+// lock stops other goroutines and processes changing the dead-letter file until the returned function is called
+func (s *Store) lock() (func(), error) {
+	s.mu.Lock()
+	file, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("failed to open dead-letter lock file: %w", err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		s.mu.Unlock()
+		return nil, fmt.Errorf("failed to lock dead-letter file: %w", err)
+	}
+	return func() {
+		_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+		s.mu.Unlock()
+	}, nil
+}
+
+func (s *Store) read() ([]*Entry, error) {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []*Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("failed to decode dead-letter entry: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dead-letter file: %w", err)
+	}
+	return entries, nil
+}
+
+// write replaces the dead-letter file atomically so a crash cannot lose entries
+func (s *Store) write(entries []*Entry) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary dead-letter file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	encoder := json.NewEncoder(tmp)
+	for _, e := range entries {
+		if err := encoder.Encode(e); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write dead-letter entry: %w", err)
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write dead-letter file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o600); err != nil {
+		return fmt.Errorf("failed to set dead-letter file permissions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace dead-letter file: %w", err)
+	}
+	return nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate dead-letter id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,162 @@
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given an empty dead-letter store", t, func() {
+		store := New(filepath.Join(t.TempDir(), "dead-letter.jsonl"))
+
+		Convey("When the entries are listed", func() {
+			entries, err := store.List(Filter{})
+
+			Convey("Then no entries are returned", func() {
+				So(err, ShouldBeNil)
+				So(entries, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a failed submission is added", func() {
+			feedback := &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "the chart is wrong", OnsURL: "https://www.ons.gov.uk/economy"}}
+			entry, err := store.Add(ctx, feedback, errors.New("connection refused"), 500)
+
+			Convey("Then the entry is recorded with the error and time of failure", func() {
+				So(err, ShouldBeNil)
+				So(entry.ID, ShouldNotBeEmpty)
+				So(entry.Error, ShouldEqual, "connection refused")
+				So(entry.StatusCode, ShouldEqual, 500)
+				So(entry.FailedAt, ShouldHappenWithin, time.Minute, time.Now())
+			})
+
+			Convey("Then the entry can be retrieved by its ID", func() {
+				got, err := store.Get(entry.ID)
+				So(err, ShouldBeNil)
+				So(got.Feedback, ShouldResemble, feedback)
+			})
+
+			Convey("Then the dead-letter file is only readable by its owner", func() {
+				info, err := os.Stat(store.Path())
+				So(err, ShouldBeNil)
+				So(info.Mode().Perm(), ShouldEqual, os.FileMode(0o600))
+			})
+
+			Convey("And it is removed", func() {
+				So(store.Remove(entry.ID), ShouldBeNil)
+
+				Convey("Then it can no longer be found", func() {
+					_, err := store.Get(entry.ID)
+					So(err, ShouldEqual, ErrNotFound)
+				})
+			})
+		})
+	})
+
+	Convey("Given a dead-letter store with several entries", t, func() {
+		store := New(filepath.Join(t.TempDir(), "dead-letter.jsonl"))
+		first, _ := store.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "first"}}, errors.New("failed"), 500)
+		second, _ := store.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "second"}}, errors.New("failed"), 500)
+		third, _ := store.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "third"}}, errors.New("failed"), 500)
+
+		Convey("When one entry is removed", func() {
+			So(store.Remove(second.ID), ShouldBeNil)
+
+			Convey("Then the remaining entries are kept in order", func() {
+				entries, err := store.List(Filter{})
+				So(err, ShouldBeNil)
+				So(entries, ShouldHaveLength, 2)
+				So(entries[0].ID, ShouldEqual, first.ID)
+				So(entries[1].ID, ShouldEqual, third.ID)
+			})
+		})
+
+		Convey("When one entry is updated", func() {
+			n, err := store.Update(func(e *Entry) bool {
+				if e.ID != second.ID {
+					return false
+				}
+				e.Feedback.Feedback.Feedback = "changed"
+				return true
+			})
+
+			Convey("Then only that entry is changed", func() {
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 1)
+				got, _ := store.Get(second.ID)
+				So(got.Feedback.Feedback.Feedback, ShouldEqual, "changed")
+				got, _ = store.Get(first.ID)
+				So(got.Feedback.Feedback.Feedback, ShouldEqual, "first")
+			})
+		})
+	})
+}
+
+func TestSharedFile(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given the service and the replay command using the same dead-letter file", t, func() {
+		path := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+		service, replay := New(path), New(path)
+		sent, _ := service.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "sent"}}, errors.New("failed"), 500)
+
+		Convey("When the service adds entries while the replay command removes one", func() {
+			var wg sync.WaitGroup
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					_, _ = service.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "new"}}, errors.New("failed"), 500)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					_ = replay.Remove(sent.ID)
+				}
+			}()
+			wg.Wait()
+
+			Convey("Then no entry added by the service is lost", func() {
+				entries, err := replay.List(Filter{})
+				So(err, ShouldBeNil)
+				So(entries, ShouldHaveLength, 50)
+			})
+		})
+	})
+}
+
+func TestFilter(t *testing.T) {
+	Convey("Given an entry that failed at midday", t, func() {
+		entry := &Entry{FailedAt: time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)}
+
+		testCases := []struct {
+			description string
+			filter      Filter
+			expected    bool
+		}{
+			{"the filter is empty", Filter{}, true},
+			{"the filter starts before the failure", Filter{Since: time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)}, true},
+			{"the filter starts after the failure", Filter{Since: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)}, false},
+			{"the filter ends after the failure", Filter{Until: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)}, true},
+			{"the filter ends at the failure", Filter{Until: entry.FailedAt}, false},
+		}
+
+		for _, tc := range testCases {
+			Convey("When "+tc.description, func() {
+				So(tc.filter.Matches(entry), ShouldEqual, tc.expected)
+			})
+		}
+	})
+}
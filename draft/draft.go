@@ -0,0 +1,168 @@
+package draft
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+)
+
+// CookieName is the cookie holding a user's draft of the feedback page
+const CookieName = "feedback_draft"
+
+// WizardCookieName is the cookie holding a user's answers to the steps of the feedback wizard
+const WizardCookieName = "feedback_wizard"
+
+// MinSecretLength is the fewest characters a secret that cookies are sealed with may have
+const MinSecretLength = 32
+
+// cookiePath limits the cookie to the feedback pages
+const cookiePath = "/feedback"
+
+// maxValueLength keeps a cookie, with its name and attributes, within the 4096 bytes that browsers store
+const maxValueLength = 3800
+
+// ErrTooLarge is returned by Save when a form is too large to be kept in a cookie
+var ErrTooLarge = errors.New("form is too large to keep in a cookie")
+
+// state is what is sealed in a cookie
+type state struct {
+	Form    model.FeedbackForm `json:"form"`
+	Expires int64              `json:"expires"`
+}
+
+// Drafts keeps partially completed feedback forms for a short time, so people can come back to them. Each form is
+// kept in a cookie, encrypted and signed with a secret shared by every instance of the service, so any instance can
+// read it and the user can neither read nor change it.
+type Drafts struct {
+	name   string
+	expiry time.Duration
+	secure bool
+	aead   cipher.AEAD
+	now    func() time.Time
+}
+
+// New creates drafts kept in the cookie called name, sealed with secret, for expiry after they were last saved.
+// Cookies are only sent over https when secure is true.
+func New(name, secret string, expiry time.Duration, secure bool) (*Drafts, error) {
+	if len(secret) < MinSecretLength {
+		return nil, fmt.Errorf("draft secret must be at least %d characters", MinSecretLength)
+	}
+	if expiry <= 0 {
+		return nil, errors.New("draft expiry must be greater than 0")
+	}
+	// the secret is hashed to give a key of the length AES-256 needs, whatever its length
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Drafts{
+		name:   name,
+		expiry: expiry,
+		secure: secure,
+		aead:   aead,
+		now:    time.Now,
+	}, nil
+}
+
+// Load returns the draft in the request's cookie, if there is one that has not expired
+func (d *Drafts) Load(req *http.Request) (model.FeedbackForm, bool) {
+	c, err := req.Cookie(d.name)
+	if err != nil {
+		return model.FeedbackForm{}, false
+	}
+	s, ok := d.open(c.Value)
+	if !ok || !d.now().Before(time.Unix(s.Expires, 0)) {
+		return model.FeedbackForm{}, false
+	}
+	return s.Form, true
+}
+
+// Save sets the cookie keeping ff as the user's draft, replacing any they already have. It returns ErrTooLarge,
+// leaving any draft they have as it was, when ff does not fit in a cookie.
+func (d *Drafts) Save(w http.ResponseWriter, req *http.Request, ff model.FeedbackForm) error {
+	value, err := d.seal(state{Form: ff, Expires: d.now().Add(d.expiry).Unix()})
+	if err != nil {
+		return err
+	}
+	if len(value) > maxValueLength {
+		return ErrTooLarge
+	}
+	http.SetCookie(w, d.cookie(value, int(d.expiry.Seconds())))
+	return nil
+}
+
+// Discard deletes the user's draft by expiring their cookie
+func (d *Drafts) Discard(w http.ResponseWriter, req *http.Request) {
+	if _, err := req.Cookie(d.name); err == nil {
+		http.SetCookie(w, d.cookie("", -1))
+	}
+}
+
+func (d *Drafts) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     d.name,
+		Value:    value,
+		Path:     cookiePath,
+		MaxAge:   maxAge,
+		Secure:   d.secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// seal compresses and encrypts s, binding it to the cookie name so that one kind of cookie cannot be passed off as
+// another
+func (d *Drafts) seal(s state) (string, error) {
+	var buf bytes.Buffer
+	zw, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if err := json.NewEncoder(zw).Encode(s); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, d.aead.NonceSize(), d.aead.NonceSize()+buf.Len()+d.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(d.aead.Seal(nonce, nonce, buf.Bytes(), []byte(d.name))), nil
+}
+
+// open returns the state sealed in value, if it was sealed by seal with the same secret and cookie name
+func (d *Drafts) open(value string) (state, bool) {
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(sealed) < d.aead.NonceSize() {
+		return state{}, false
+	}
+	nonce, ciphertext := sealed[:d.aead.NonceSize()], sealed[d.aead.NonceSize():]
+	plaintext, err := d.aead.Open(nil, nonce, ciphertext, []byte(d.name))
+	if err != nil {
+		return state{}, false
+	}
+
+	var s state
+	if err := json.NewDecoder(flate.NewReader(bytes.NewReader(plaintext))).Decode(&s); err != nil {
+		return state{}, false
+	}
+	return s, true
+}
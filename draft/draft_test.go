@@ -0,0 +1,159 @@
+package draft
+
+import (
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+func TestDrafts(t *testing.T) {
+	Convey("Given drafts kept for ten minutes", t, func() {
+		now := time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)
+		drafts, err := New(CookieName, secret, 10*time.Minute, true)
+		So(err, ShouldBeNil)
+		drafts.now = func() time.Time { return now }
+
+		// save saves ff, returning the cookie that was set
+		save := func(d *Drafts, ff model.FeedbackForm) *http.Cookie {
+			w := httptest.NewRecorder()
+			So(d.Save(w, httptest.NewRequest("POST", "/feedback/draft", http.NoBody), ff), ShouldBeNil)
+			return w.Result().Cookies()[0]
+		}
+		load := func(d *Drafts, cookie *http.Cookie) (model.FeedbackForm, bool) {
+			req := httptest.NewRequest("GET", "/feedback", http.NoBody)
+			if cookie != nil {
+				req.AddCookie(cookie)
+			}
+			return d.Load(req)
+		}
+
+		Convey("When a draft is saved", func() {
+			cookie := save(drafts, model.FeedbackForm{Description: "half written", Email: "jo@example.com"})
+
+			Convey("Then a cookie holding it is set for the feedback pages", func() {
+				So(cookie.Name, ShouldEqual, CookieName)
+				So(cookie.Path, ShouldEqual, "/feedback")
+				So(cookie.MaxAge, ShouldEqual, 600)
+				So(cookie.HttpOnly, ShouldBeTrue)
+				So(cookie.Secure, ShouldBeTrue)
+			})
+
+			Convey("Then the cookie does not reveal what was written", func() {
+				So(cookie.Value, ShouldNotContainSubstring, "half")
+				So(cookie.Value, ShouldNotContainSubstring, "jo@example.com")
+			})
+
+			Convey("Then it is loaded with the cookie", func() {
+				ff, ok := load(drafts, cookie)
+				So(ok, ShouldBeTrue)
+				So(ff.Description, ShouldEqual, "half written")
+				So(ff.Email, ShouldEqual, "jo@example.com")
+			})
+
+			Convey("Then it is loaded by another instance, or after a restart, given the same secret", func() {
+				other, err := New(CookieName, secret, 10*time.Minute, true)
+				So(err, ShouldBeNil)
+				other.now = drafts.now
+				ff, ok := load(other, cookie)
+				So(ok, ShouldBeTrue)
+				So(ff.Description, ShouldEqual, "half written")
+			})
+
+			Convey("Then it is not loaded with a different secret", func() {
+				other, err := New(CookieName, strings.Repeat("x", MinSecretLength), 10*time.Minute, true)
+				So(err, ShouldBeNil)
+				other.now = drafts.now
+				_, ok := load(other, cookie)
+				So(ok, ShouldBeFalse)
+			})
+
+			Convey("Then it is not loaded as another kind of cookie", func() {
+				wizard, err := New(WizardCookieName, secret, 10*time.Minute, true)
+				So(err, ShouldBeNil)
+				wizard.now = drafts.now
+				_, ok := load(wizard, &http.Cookie{Name: WizardCookieName, Value: cookie.Value})
+				So(ok, ShouldBeFalse)
+			})
+
+			Convey("Then saving it again replaces it", func() {
+				again := save(drafts, model.FeedbackForm{Description: "nearly finished"})
+				ff, _ := load(drafts, again)
+				So(ff.Description, ShouldEqual, "nearly finished")
+			})
+
+			Convey("Then it is not loaded once it has expired, even if the cookie is sent again", func() {
+				now = now.Add(10 * time.Minute)
+				_, ok := load(drafts, cookie)
+				So(ok, ShouldBeFalse)
+			})
+
+			Convey("Then it is not loaded with a cookie that has been tampered with", func() {
+				truncated := cookie.Value[:len(cookie.Value)-1]
+				changed := truncated + "A"
+				if changed == cookie.Value {
+					changed = truncated + "B"
+				}
+				for _, value := range []string{"", truncated, changed, "abc.def", cookie.Value + "."} {
+					_, ok := load(drafts, &http.Cookie{Name: CookieName, Value: value})
+					So(ok, ShouldBeFalse)
+				}
+			})
+
+			Convey("And it is discarded", func() {
+				req := httptest.NewRequest("POST", "/feedback/draft/discard", http.NoBody)
+				req.AddCookie(cookie)
+				w := httptest.NewRecorder()
+				drafts.Discard(w, req)
+
+				Convey("Then the cookie is deleted", func() {
+					So(w.Result().Cookies()[0].Name, ShouldEqual, CookieName)
+					So(w.Result().Cookies()[0].MaxAge, ShouldBeLessThan, 0)
+				})
+			})
+		})
+
+		Convey("When there is no cookie", func() {
+			_, ok := load(drafts, nil)
+			w := httptest.NewRecorder()
+			drafts.Discard(w, httptest.NewRequest("POST", "/feedback/draft/discard", http.NoBody))
+
+			Convey("Then there is no draft and no cookie is set", func() {
+				So(ok, ShouldBeFalse)
+				So(w.Result().Cookies(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a draft too large for a cookie is saved", func() {
+			// random letters, which do not compress well
+			r := rand.New(rand.NewSource(1))
+			description := make([]byte, 8000)
+			for i := range description {
+				description[i] = byte('a' + r.Intn(26))
+			}
+			w := httptest.NewRecorder()
+			err := drafts.Save(w, httptest.NewRequest("POST", "/feedback/draft", http.NoBody), model.FeedbackForm{Description: string(description)})
+
+			Convey("Then it is not saved", func() {
+				So(err, ShouldEqual, ErrTooLarge)
+				So(w.Result().Cookies(), ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given invalid settings", t, func() {
+		Convey("Then no drafts are created", func() {
+			_, err := New(CookieName, "too short", time.Minute, false)
+			So(err, ShouldNotBeNil)
+			_, err = New(CookieName, secret, 0, false)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,221 @@
+package duplicate
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+// Actions that can be taken when a duplicate submission is detected
+const (
+	ActionOff  = "off"
+	ActionFlag = "flag"
+	ActionDrop = "drop"
+)
+
+// shingleSize is the number of consecutive words hashed together when comparing descriptions
+const shingleSize = 3
+
+// sweepEvery is how many descriptions are recorded between removals of expired history for every URL
+const sweepEvery = 256
+
+// Config holds the thresholds used to decide whether a description is a near-duplicate
+type Config struct {
+	Action      string
+	Threshold   float64
+	Window      time.Duration
+	HistorySize int
+}
+
+// Validate returns an error if the configuration cannot be used to create a Detector
+func (c Config) Validate() error {
+	switch c.Action {
+	case ActionOff, ActionFlag, ActionDrop:
+	default:
+		return fmt.Errorf("unknown duplicate action %q, expected one of %s, %s or %s", c.Action, ActionOff, ActionFlag, ActionDrop)
+	}
+	if c.Threshold <= 0 || c.Threshold > 1 {
+		return fmt.Errorf("duplicate threshold must be greater than 0 and at most 1, got %v", c.Threshold)
+	}
+	if c.Window <= 0 {
+		return fmt.Errorf("duplicate window must be positive, got %s", c.Window)
+	}
+	if c.HistorySize <= 0 {
+		return fmt.Errorf("duplicate history size must be positive, got %d", c.HistorySize)
+	}
+	return nil
+}
+
+// Result describes how a description compares with those recently submitted for the same URL
+type Result struct {
+	IsDuplicate bool
+	Similarity  float64
+}
+
+// Stats are running totals of the descriptions checked and the duplicates found
+type Stats struct {
+	Checked    int64 `json:"checked"`
+	Duplicates int64 `json:"duplicates"`
+}
+
+type fingerprint struct {
+	shingles map[uint64]struct{}
+	seenAt   time.Time
+}
+
+// Detector finds near-duplicate descriptions submitted for the same URL within a sliding time window
+type Detector struct {
+	Action string
+
+	threshold   float64
+	window      time.Duration
+	historySize int
+	now         func() time.Time
+
+	mu       sync.Mutex
+	recent   map[string][]fingerprint
+	recorded int
+
+	checked    atomic.Int64
+	duplicates atomic.Int64
+}
+
+// NewDetector creates a Detector from cfg
+func NewDetector(cfg Config) (*Detector, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &Detector{
+		Action:      cfg.Action,
+		threshold:   cfg.Threshold,
+		window:      cfg.Window,
+		historySize: cfg.HistorySize,
+		now:         time.Now,
+		recent:      make(map[string][]fingerprint),
+	}, nil
+}
+
+// Check compares text with the descriptions recently recorded for url. The text is not recorded, so a submission
+// that fails to send is not treated as a duplicate when it is retried.
+func (d *Detector) Check(url, text string) Result {
+	shingles := Shingles(text)
+	cutoff := d.now().Add(-d.window)
+	d.checked.Add(1)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var result Result
+	for _, previous := range expire(d.recent[url], cutoff) {
+		if similarity := Similarity(shingles, previous.shingles); similarity > result.Similarity {
+			result.Similarity = similarity
+		}
+	}
+	result.IsDuplicate = len(shingles) > 0 && result.Similarity >= d.threshold
+	if result.IsDuplicate {
+		d.duplicates.Add(1)
+	}
+	return result
+}
+
+// Record remembers text as submitted for url, once it has been sent, so later submissions are compared against it
+func (d *Detector) Record(url, text string) {
+	fp := fingerprint{shingles: Shingles(text), seenAt: d.now()}
+	cutoff := fp.seenAt.Add(-d.window)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.recorded++; d.recorded%sweepEvery == 0 {
+		d.sweep(cutoff)
+	}
+
+	history := append(expire(d.recent[url], cutoff), fp)
+	if len(history) > d.historySize {
+		history = history[len(history)-d.historySize:]
+	}
+	d.recent[url] = history
+}
+
+// Stats returns the running totals for the detector
+func (d *Detector) Stats() Stats {
+	return Stats{
+		Checked:    d.checked.Load(),
+		Duplicates: d.duplicates.Load(),
+	}
+}
+
+func (d *Detector) sweep(cutoff time.Time) {
+	for url, history := range d.recent {
+		if history = expire(history, cutoff); len(history) == 0 {
+			delete(d.recent, url)
+		} else {
+			d.recent[url] = history
+		}
+	}
+}
+
+// expire drops the fingerprints seen before cutoff, history is ordered oldest first
+func expire(history []fingerprint, cutoff time.Time) []fingerprint {
+	for i, fp := range history {
+		if fp.seenAt.After(cutoff) {
+			return history[i:]
+		}
+	}
+	return nil
+}
+
+// Normalise lower-cases text, removes punctuation and collapses whitespace so trivial edits compare equal
+func Normalise(text string) string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// Shingles returns the hashes of each run of consecutive words in the normalised text
+func Shingles(text string) map[uint64]struct{} {
+	words := strings.Fields(Normalise(text))
+	shingles := make(map[uint64]struct{})
+	if len(words) == 0 {
+		return shingles
+	}
+	if len(words) < shingleSize {
+		shingles[hash(words)] = struct{}{}
+		return shingles
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		shingles[hash(words[i:i+shingleSize])] = struct{}{}
+	}
+	return shingles
+}
+
+// Similarity is the Jaccard index of two sets of shingles, from 0 (nothing shared) to 1 (identical)
+func Similarity(a, b map[uint64]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	shared := 0
+	for shingle := range a {
+		if _, ok := b[shingle]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+func hash(words []string) uint64 {
+	h := fnv.New64a()
+	for _, word := range words {
+		h.Write([]byte(word))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
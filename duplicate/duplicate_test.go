@@ -0,0 +1,158 @@
+package duplicate
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var testConfig = Config{
+	Action:      ActionFlag,
+	Threshold:   0.8,
+	Window:      time.Hour,
+	HistorySize: 3,
+}
+
+func TestNormalise(t *testing.T) {
+	Convey("Given descriptions that differ only in case, punctuation and spacing", t, func() {
+		Convey("Then they normalise to the same text", func() {
+			So(Normalise("The CPI figure   is WRONG!!!"), ShouldEqual, "the cpi figure is wrong")
+			So(Normalise("the cpi figure, is wrong."), ShouldEqual, "the cpi figure is wrong")
+		})
+	})
+
+	Convey("Given a Welsh description", t, func() {
+		Convey("Then accented letters are kept", func() {
+			So(Normalise("Mae'r ffigur yn anghywir, ŵyr!"), ShouldEqual, "mae r ffigur yn anghywir ŵyr")
+		})
+	})
+}
+
+func TestSimilarity(t *testing.T) {
+	Convey("Given the Similarity function", t, func() {
+		testCases := []struct {
+			description string
+			a, b        string
+			expected    float64
+		}{
+			{"identical text", "the download link is broken", "the download link is broken", 1},
+			{"trivially edited text", "The download link is broken!", "the download   link is BROKEN", 1},
+			{"unrelated text", "the download link is broken", "please publish more census data", 0},
+			{"empty text", "", "", 0},
+		}
+
+		for _, tc := range testCases {
+			Convey("When comparing "+tc.description, func() {
+				So(Similarity(Shingles(tc.a), Shingles(tc.b)), ShouldEqual, tc.expected)
+			})
+		}
+
+		Convey("When comparing text with a small change", func() {
+			similarity := Similarity(
+				Shingles("the inflation figures on this page do not match the dataset that can be downloaded"),
+				Shingles("the inflation figures on this page do not match the dataset that can be downloaded today"),
+			)
+			So(similarity, ShouldBeBetween, 0.8, 1)
+		})
+	})
+}
+
+func TestDetector(t *testing.T) {
+	Convey("Given a detector", t, func() {
+		now := time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)
+		detector, err := NewDetector(testConfig)
+		So(err, ShouldBeNil)
+		detector.now = func() time.Time { return now }
+
+		Convey("When a description is checked for the first time", func() {
+			result := detector.Check("https://www.ons.gov.uk/a", "the chart is not loading")
+
+			Convey("Then it is not a duplicate", func() {
+				So(result.IsDuplicate, ShouldBeFalse)
+			})
+
+			Convey("And it is checked again without being recorded, as when sending it failed", func() {
+				result := detector.Check("https://www.ons.gov.uk/a", "the chart is not loading")
+
+				Convey("Then it is not a duplicate", func() {
+					So(result.IsDuplicate, ShouldBeFalse)
+				})
+			})
+		})
+
+		Convey("When a description is recorded", func() {
+			detector.Record("https://www.ons.gov.uk/a", "the chart is not loading")
+
+			Convey("And the same description is submitted for the same URL", func() {
+				result := detector.Check("https://www.ons.gov.uk/a", "The chart is not loading.")
+
+				Convey("Then it is a duplicate", func() {
+					So(result.IsDuplicate, ShouldBeTrue)
+					So(result.Similarity, ShouldEqual, 1)
+					So(detector.Stats(), ShouldResemble, Stats{Checked: 1, Duplicates: 1})
+				})
+			})
+
+			Convey("And the same description is submitted for a different URL", func() {
+				result := detector.Check("https://www.ons.gov.uk/b", "the chart is not loading")
+
+				Convey("Then it is not a duplicate", func() {
+					So(result.IsDuplicate, ShouldBeFalse)
+				})
+			})
+
+			Convey("And the same description is submitted after the window", func() {
+				now = now.Add(2 * time.Hour)
+				result := detector.Check("https://www.ons.gov.uk/a", "the chart is not loading")
+
+				Convey("Then it is not a duplicate", func() {
+					So(result.IsDuplicate, ShouldBeFalse)
+				})
+			})
+
+			Convey("And more descriptions than the history size are submitted", func() {
+				detector.Record("https://www.ons.gov.uk/a", "first other description here")
+				detector.Record("https://www.ons.gov.uk/a", "second other description here")
+				detector.Record("https://www.ons.gov.uk/a", "third other description here")
+				result := detector.Check("https://www.ons.gov.uk/a", "the chart is not loading")
+
+				Convey("Then the oldest description is forgotten", func() {
+					So(result.IsDuplicate, ShouldBeFalse)
+				})
+			})
+		})
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	Convey("Given the Validate function", t, func() {
+		Convey("When the config is valid", func() {
+			So(testConfig.Validate(), ShouldBeNil)
+		})
+
+		Convey("When the action is unknown", func() {
+			cfg := testConfig
+			cfg.Action = "ignore"
+			So(cfg.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("When the threshold is out of range", func() {
+			cfg := testConfig
+			cfg.Threshold = 1.5
+			So(cfg.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("When the window is not positive", func() {
+			cfg := testConfig
+			cfg.Window = 0
+			So(cfg.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("When the history size is not positive", func() {
+			cfg := testConfig
+			cfg.HistorySize = 0
+			So(cfg.Validate(), ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,183 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+)
+
+// Formats that feedback can be exported in
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// ContentTypes are the media types of each export format
+var ContentTypes = map[string]string{
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatNDJSON: "application/x-ndjson",
+}
+
+// Record is a single exported item of feedback
+type Record struct {
+	ID         string    `json:"id"`
+	ReceivedAt time.Time `json:"received_at"`
+	Status     string    `json:"status"`
+	Lang       string    `json:"lang"`
+	Type       string    `json:"type"`
+	URL        string    `json:"url,omitempty"`
+	Feedback   string    `json:"feedback"`
+	Name       string    `json:"name,omitempty"`
+	Email      string    `json:"email,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
+	Topic      string    `json:"topic,omitempty"`
+	Subtopic   string    `json:"subtopic,omitempty"`
+	Team       string    `json:"team,omitempty"`
+	Sentiment  *float64  `json:"sentiment,omitempty"`
+}
+
+var csvHeader = []string{"id", "received_at", "status", "lang", "type", "url", "feedback", "name", "email", "tags", "topic", "subtopic", "team", "sentiment"}
+
+// NewRecord flattens a stored item into an exported record
+func NewRecord(item *store.Item) Record {
+	r := Record{
+		ID:         item.ID,
+		ReceivedAt: item.ReceivedAt,
+		Status:     item.Status,
+		Lang:       item.Lang,
+		Type:       item.Type(),
+	}
+	if s := item.Submission; s != nil {
+		r.URL = s.OnsURL
+		r.Feedback = s.Feedback.Feedback
+		r.Name = s.Name
+		r.Email = s.EmailAddress
+		r.Tags = s.Tags
+		r.Topic = s.Topic
+		r.Subtopic = s.Subtopic
+		r.Team = s.Team
+		r.Sentiment = s.Sentiment
+	}
+	return r
+}
+
+// Filter describes the feedback to export, as given by a user. Dates are either YYYY-MM-DD or RFC3339.
+type Filter struct {
+	Since     string
+	Until     string
+	Type      string
+	URLPrefix string
+	Lang      string
+	Tags      []string
+}
+
+// StoreFilter validates the filter and converts it to a filter on the store
+func (f Filter) StoreFilter() (filter store.Filter, err error) {
+	if filter.Since, err = ParseDate(f.Since); err != nil {
+		return filter, fmt.Errorf("invalid since: %w", err)
+	}
+	if filter.Until, err = ParseDate(f.Until); err != nil {
+		return filter, fmt.Errorf("invalid until: %w", err)
+	}
+	if f.Type != "" && !slices.Contains(store.Types, f.Type) {
+		return filter, fmt.Errorf("invalid type %q, expected one of %s", f.Type, strings.Join(store.Types, ", "))
+	}
+	filter.Type = f.Type
+	filter.URLPrefix = f.URLPrefix
+	filter.Lang = f.Lang
+	filter.Tags = f.Tags
+	return filter, nil
+}
+
+// ParseDate accepts either a calendar date or a full RFC3339 timestamp, an empty value is the zero time
+func ParseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// IsValidFormat is true when format is one of the export formats
+func IsValidFormat(format string) bool {
+	_, ok := ContentTypes[format]
+	return ok
+}
+
+// Write streams the feedback in st matching filter to w in the given format, one item at a time, returning the
+// number of items written
+func Write(w io.Writer, format string, st *store.Store, filter store.Filter) (int, error) {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, st, filter)
+	case FormatNDJSON:
+		return writeNDJSON(w, st, filter)
+	default:
+		return 0, fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+func writeCSV(w io.Writer, st *store.Store, filter store.Filter) (int, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	err := st.Walk(filter, func(item *store.Item) error {
+		r := NewRecord(item)
+		sentiment := ""
+		if r.Sentiment != nil {
+			sentiment = strconv.FormatFloat(*r.Sentiment, 'f', -1, 64)
+		}
+		n++
+		return cw.Write([]string{
+			r.ID,
+			r.ReceivedAt.Format(time.RFC3339),
+			r.Status,
+			r.Lang,
+			r.Type,
+			safeCell(r.URL),
+			safeCell(r.Feedback),
+			safeCell(r.Name),
+			safeCell(r.Email),
+			strings.Join(r.Tags, ";"),
+			r.Topic,
+			r.Subtopic,
+			r.Team,
+			sentiment,
+		})
+	})
+	cw.Flush()
+	if err != nil {
+		return n, err
+	}
+	return n, cw.Error()
+}
+
+func writeNDJSON(w io.Writer, st *store.Store, filter store.Filter) (int, error) {
+	encoder := json.NewEncoder(w)
+	n := 0
+	err := st.Walk(filter, func(item *store.Item) error {
+		n++
+		return encoder.Encode(NewRecord(item))
+	})
+	return n, err
+}
+
+// safeCell stops text written by the public being run as a formula when the export is opened in a spreadsheet
+func safeCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,120 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newTestStore(t *testing.T) *store.Store {
+	st, err := store.New(filepath.Join(t.TempDir(), "feedback"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isGeneral, isPage := true, false
+	sentiment := -0.25
+	ctx := context.Background()
+	if _, err := st.Add(ctx, &submission.Submission{
+		Feedback: feedbackAPIModel.Feedback{Feedback: "=HYPERLINK(\"http://example.com\")", IsGeneralFeedback: &isGeneral},
+	}, "en"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.Add(ctx, &submission.Submission{
+		Feedback:  feedbackAPIModel.Feedback{Feedback: "the map, it's \"broken\"\nagain", IsGeneralFeedback: &isPage, OnsURL: "https://www.ons.gov.uk/census/maps"},
+		Tags:      []string{"data error", "download"},
+		Sentiment: &sentiment,
+	}, "cy"); err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
+
+func TestWrite(t *testing.T) {
+	Convey("Given a store of feedback", t, func() {
+		st := newTestStore(t)
+		out := &bytes.Buffer{}
+
+		Convey("When it is exported as CSV", func() {
+			n, err := Write(out, FormatCSV, st, store.Filter{})
+
+			Convey("Then a header and a row for each item are written", func() {
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 2)
+
+				rows, err := csv.NewReader(out).ReadAll()
+				So(err, ShouldBeNil)
+				So(rows, ShouldHaveLength, 3)
+				So(rows[0], ShouldResemble, csvHeader)
+				So(rows[2][4], ShouldEqual, store.TypePage)
+				So(rows[2][6], ShouldEqual, "the map, it's \"broken\"\nagain")
+				So(rows[2][9], ShouldEqual, "data error;download")
+				So(rows[2][13], ShouldEqual, "-0.25")
+			})
+
+			Convey("Then text that a spreadsheet would run as a formula is escaped", func() {
+				So(out.String(), ShouldContainSubstring, `'=HYPERLINK(`)
+			})
+		})
+
+		Convey("When it is exported as NDJSON", func() {
+			n, err := Write(out, FormatNDJSON, st, store.Filter{Lang: "cy"})
+
+			Convey("Then a JSON record is written on a line for each matching item", func() {
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 1)
+
+				lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+				So(lines, ShouldHaveLength, 1)
+				var r Record
+				So(json.Unmarshal([]byte(lines[0]), &r), ShouldBeNil)
+				So(r.URL, ShouldEqual, "https://www.ons.gov.uk/census/maps")
+				So(r.Lang, ShouldEqual, "cy")
+				So(r.Tags, ShouldResemble, []string{"data error", "download"})
+				So(*r.Sentiment, ShouldEqual, -0.25)
+			})
+		})
+
+		Convey("When it is exported in an unknown format", func() {
+			_, err := Write(out, "xlsx", st, store.Filter{})
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestStoreFilter(t *testing.T) {
+	Convey("Given a filter from a user", t, func() {
+		Convey("When it is valid", func() {
+			filter, err := Filter{Since: "2024-03-01", Until: "2024-03-08T12:00:00Z", Type: store.TypePage, Tags: []string{"search"}}.StoreFilter()
+
+			Convey("Then it is converted to a store filter", func() {
+				So(err, ShouldBeNil)
+				So(filter.Since.Format("2006-01-02"), ShouldEqual, "2024-03-01")
+				So(filter.Until.Hour(), ShouldEqual, 12)
+				So(filter.Type, ShouldEqual, store.TypePage)
+				So(filter.Tags, ShouldResemble, []string{"search"})
+			})
+		})
+
+		Convey("When it has an invalid date or type", func() {
+			Convey("Then an error is returned", func() {
+				_, err := Filter{Since: "last week"}.StoreFilter()
+				So(err, ShouldNotBeNil)
+				_, err = Filter{Type: "survey"}.StoreFilter()
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,192 @@
+package flags
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CookieName is the cookie holding the random id that keeps the flags turned on for a user by percentage the same
+// from one request to the next
+const CookieName = "feature_flags"
+
+// HeaderName is the request header testers use to turn flags on or off, a comma-separated list of flag names with
+// a `!` before those to turn off
+const HeaderName = "X-Feature-Flags"
+
+// cookiePath limits the cookie to the feedback pages
+const cookiePath = "/feedback"
+
+// cookieMaxAge keeps a user's flags the same for as long as a rollout is likely to last
+const cookieMaxAge = 365 * 24 * time.Hour
+
+var (
+	namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+	idPattern   = regexp.MustCompile(`^[0-9a-f]{32}$`)
+)
+
+// Flag is a feature that is turned on for a percentage of users, only for those reading the site in one of
+// Languages when any are given
+type Flag struct {
+	Name       string
+	Percentage int
+	Languages  []string
+}
+
+// Flags decides which features are turned on for each request
+type Flags struct {
+	flags          []Flag
+	allowOverrides bool
+	secure         bool
+}
+
+// Parse reads flags written as `name:percentage`, optionally followed by `:` and the languages the flag is turned
+// on for separated by `|`, such as `new-form:25` or `welsh-rating:100:cy`
+func Parse(specs []string) ([]Flag, error) {
+	var flags []Flag
+	var errs []error
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		parts := strings.Split(spec, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			errs = append(errs, fmt.Errorf("feature flag %q: expected name:percentage[:languages]", spec))
+			continue
+		}
+
+		flag := Flag{Name: parts[0]}
+		if !namePattern.MatchString(flag.Name) {
+			errs = append(errs, fmt.Errorf("feature flag %q: name must be lower case letters, digits and hyphens", spec))
+			continue
+		}
+		if seen[flag.Name] {
+			errs = append(errs, fmt.Errorf("feature flag %q: %s is defined more than once", spec, flag.Name))
+			continue
+		}
+		seen[flag.Name] = true
+
+		percentage, err := strconv.Atoi(parts[1])
+		if err != nil || percentage < 0 || percentage > 100 {
+			errs = append(errs, fmt.Errorf("feature flag %q: percentage must be a whole number from 0 to 100", spec))
+			continue
+		}
+		flag.Percentage = percentage
+
+		if len(parts) == 3 {
+			for _, lang := range strings.Split(parts[2], "|") {
+				if lang = strings.TrimSpace(lang); lang != "" {
+					flag.Languages = append(flag.Languages, lang)
+				}
+			}
+			if len(flag.Languages) == 0 {
+				errs = append(errs, fmt.Errorf("feature flag %q: languages must not be empty", spec))
+				continue
+			}
+		}
+		flags = append(flags, flag)
+	}
+	return flags, errors.Join(errs...)
+}
+
+// New creates Flags that turn on the given flags. Testers can turn flags on or off with the X-Feature-Flags header
+// when allowOverrides is true. The cookie keeping percentages the same is only sent over https when secure is true.
+func New(flags []Flag, allowOverrides, secure bool) *Flags {
+	return &Flags{flags: flags, allowOverrides: allowOverrides, secure: secure}
+}
+
+// Evaluate returns whether each flag is turned on for req from a page in lang. A user is given the cookie keeping
+// their flags the same the first time a flag is turned on for only some users.
+func (f *Flags) Evaluate(w http.ResponseWriter, req *http.Request, lang string) map[string]bool {
+	if f == nil || len(f.flags) == 0 {
+		return nil
+	}
+
+	overrides := f.overrides(req)
+	enabled := make(map[string]bool, len(f.flags))
+	var id string
+	for _, flag := range f.flags {
+		if on, ok := overrides[flag.Name]; ok {
+			enabled[flag.Name] = on
+			continue
+		}
+		if len(flag.Languages) > 0 && !slices.Contains(flag.Languages, lang) {
+			enabled[flag.Name] = false
+			continue
+		}
+		switch flag.Percentage {
+		case 0:
+			enabled[flag.Name] = false
+		case 100:
+			enabled[flag.Name] = true
+		default:
+			if id == "" {
+				id = f.userID(w, req)
+			}
+			enabled[flag.Name] = bucket(flag.Name, id) < flag.Percentage
+		}
+	}
+	return enabled
+}
+
+// overrides returns the flags turned on or off by the X-Feature-Flags header, if testers are allowed to use it
+func (f *Flags) overrides(req *http.Request) map[string]bool {
+	header := req.Header.Get(HeaderName)
+	if !f.allowOverrides || header == "" {
+		return nil
+	}
+	overrides := make(map[string]bool)
+	for _, name := range strings.Split(header, ",") {
+		name = strings.TrimSpace(name)
+		on := !strings.HasPrefix(name, "!")
+		if name = strings.TrimPrefix(name, "!"); name != "" {
+			overrides[name] = on
+		}
+	}
+	return overrides
+}
+
+// userID returns the random id in the user's cookie, giving them a new one if they do not have one
+func (f *Flags) userID(w http.ResponseWriter, req *http.Request) string {
+	if c, err := req.Cookie(CookieName); err == nil && idPattern.MatchString(c.Value) {
+		return c.Value
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// without an id the user is treated as one that has none of the flags turned on by percentage
+		return ""
+	}
+	id := hex.EncodeToString(b)
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    id,
+		Path:     cookiePath,
+		MaxAge:   int(cookieMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   f.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return id
+}
+
+// bucket places the user with id in one of 100 buckets for the flag called name, so that each flag is turned on for
+// a different set of users
+func bucket(name, id string) int {
+	if id == "" {
+		return 100
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name + ":" + id))
+	return int(h.Sum32() % 100)
+}
@@ -0,0 +1,168 @@
+package flags
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParse(t *testing.T) {
+	Convey("Given flags for a percentage of users and for some languages", t, func() {
+		flags, err := Parse([]string{"new-form:25", " welsh-rating:100:cy ", "", "bilingual:50:en|cy"})
+
+		Convey("Then each flag is read", func() {
+			So(err, ShouldBeNil)
+			So(flags, ShouldResemble, []Flag{
+				{Name: "new-form", Percentage: 25},
+				{Name: "welsh-rating", Percentage: 100, Languages: []string{"cy"}},
+				{Name: "bilingual", Percentage: 50, Languages: []string{"en", "cy"}},
+			})
+		})
+	})
+
+	Convey("Given flags that are not valid", t, func() {
+		_, err := Parse([]string{"new-form", "New_Form:10", "rating:101", "score:ten", "welsh:10:", "dup:1", "dup:2"})
+
+		Convey("Then every problem is reported", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, `"new-form": expected name:percentage[:languages]`)
+			So(err.Error(), ShouldContainSubstring, `"New_Form:10": name must be lower case letters, digits and hyphens`)
+			So(err.Error(), ShouldContainSubstring, `"rating:101": percentage must be a whole number from 0 to 100`)
+			So(err.Error(), ShouldContainSubstring, `"score:ten": percentage must be a whole number from 0 to 100`)
+			So(err.Error(), ShouldContainSubstring, `"welsh:10:": languages must not be empty`)
+			So(err.Error(), ShouldContainSubstring, `"dup:2": dup is defined more than once`)
+		})
+	})
+}
+
+func TestEvaluate(t *testing.T) {
+	Convey("Given no flags", t, func() {
+		var f *Flags
+		w := httptest.NewRecorder()
+
+		Convey("Then none are turned on and no cookie is set", func() {
+			So(f.Evaluate(w, httptest.NewRequest("GET", "/feedback", http.NoBody), "en"), ShouldBeEmpty)
+			So(w.Result().Cookies(), ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given flags turned on for everyone, no one and Welsh readers", t, func() {
+		f := New([]Flag{{Name: "on", Percentage: 100}, {Name: "off", Percentage: 0}, {Name: "welsh", Percentage: 100, Languages: []string{"cy"}}}, false, true)
+
+		Convey("When the flags are evaluated for an English page", func() {
+			w := httptest.NewRecorder()
+			enabled := f.Evaluate(w, httptest.NewRequest("GET", "/feedback", http.NoBody), "en")
+
+			Convey("Then only the flag for everyone is turned on", func() {
+				So(enabled, ShouldResemble, map[string]bool{"on": true, "off": false, "welsh": false})
+			})
+
+			Convey("And no cookie is needed", func() {
+				So(w.Result().Cookies(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the flags are evaluated for a Welsh page", func() {
+			enabled := f.Evaluate(httptest.NewRecorder(), httptest.NewRequest("GET", "/feedback", http.NoBody), "cy")
+
+			Convey("Then the flag for Welsh readers is turned on", func() {
+				So(enabled["welsh"], ShouldBeTrue)
+			})
+		})
+
+		Convey("When a tester overrides the flags but overrides are not allowed", func() {
+			req := httptest.NewRequest("GET", "/feedback", http.NoBody)
+			req.Header.Set(HeaderName, "!on, off")
+			enabled := f.Evaluate(httptest.NewRecorder(), req, "en")
+
+			Convey("Then the header is ignored", func() {
+				So(enabled, ShouldResemble, map[string]bool{"on": true, "off": false, "welsh": false})
+			})
+		})
+	})
+
+	Convey("Given flags that testers can override", t, func() {
+		f := New([]Flag{{Name: "on", Percentage: 100}, {Name: "off", Percentage: 0}, {Name: "welsh", Percentage: 100, Languages: []string{"cy"}}}, true, true)
+
+		Convey("When a tester turns flags on and off", func() {
+			req := httptest.NewRequest("GET", "/feedback", http.NoBody)
+			req.Header.Set(HeaderName, "!on, off,welsh,unknown")
+			enabled := f.Evaluate(httptest.NewRecorder(), req, "en")
+
+			Convey("Then the header decides, whatever the percentage or language", func() {
+				So(enabled, ShouldResemble, map[string]bool{"on": false, "off": true, "welsh": true})
+			})
+		})
+	})
+
+	Convey("Given a flag turned on for half of users", t, func() {
+		f := New([]Flag{{Name: "half", Percentage: 50}}, false, true)
+
+		Convey("When a new user opens a page", func() {
+			w := httptest.NewRecorder()
+			first := f.Evaluate(w, httptest.NewRequest("GET", "/feedback", http.NoBody), "en")
+
+			Convey("Then they are given a cookie that is only sent over https", func() {
+				cookies := w.Result().Cookies()
+				So(cookies, ShouldHaveLength, 1)
+				So(cookies[0].Name, ShouldEqual, CookieName)
+				So(cookies[0].Value, ShouldHaveLength, 32)
+				So(cookies[0].Path, ShouldEqual, "/feedback")
+				So(cookies[0].HttpOnly, ShouldBeTrue)
+				So(cookies[0].Secure, ShouldBeTrue)
+
+				Convey("And the flag stays the same when they come back with it", func() {
+					for i := 0; i < 10; i++ {
+						req := httptest.NewRequest("GET", "/feedback", http.NoBody)
+						req.AddCookie(cookies[0])
+						w := httptest.NewRecorder()
+						So(f.Evaluate(w, req, "en"), ShouldResemble, first)
+						So(w.Result().Cookies(), ShouldBeEmpty)
+					}
+				})
+			})
+		})
+
+		Convey("When many users open a page", func() {
+			on := 0
+			for i := 0; i < 1000; i++ {
+				if f.Evaluate(httptest.NewRecorder(), httptest.NewRequest("GET", "/feedback", http.NoBody), "en")["half"] {
+					on++
+				}
+			}
+
+			Convey("Then the flag is turned on for about half of them", func() {
+				So(on, ShouldBeBetween, 400, 600)
+			})
+		})
+
+		Convey("When a user comes back with a cookie that was not given out by the service", func() {
+			req := httptest.NewRequest("GET", "/feedback", http.NoBody)
+			req.AddCookie(&http.Cookie{Name: CookieName, Value: "not-an-id"})
+			w := httptest.NewRecorder()
+			f.Evaluate(w, req, "en")
+
+			Convey("Then they are given a new one", func() {
+				So(w.Result().Cookies(), ShouldHaveLength, 1)
+				So(w.Result().Cookies()[0].Value, ShouldNotEqual, "not-an-id")
+			})
+		})
+	})
+}
+
+func TestBucket(t *testing.T) {
+	Convey("Given a user's id", t, func() {
+		id := "0123456789abcdef0123456789abcdef"
+
+		Convey("Then each flag places them in a bucket from 0 to 99", func() {
+			So(bucket("new-form", id), ShouldBeBetweenOrEqual, 0, 99)
+			So(bucket("new-form", id), ShouldEqual, bucket("new-form", id))
+		})
+
+		Convey("Then a user without an id is in no flag's percentage", func() {
+			So(bucket("new-form", ""), ShouldEqual, 100)
+		})
+	})
+}
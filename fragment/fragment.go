@@ -0,0 +1,41 @@
+package fragment
+
+import (
+	"html/template"
+	"io"
+	"strings"
+
+	render "github.com/ONSdigital/dis-design-system-go"
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	"github.com/ONSdigital/dis-design-system-go/model"
+	unrolled "github.com/unrolled/render"
+)
+
+// NewRenderer returns a renderer for parts of pages that are embedded elsewhere. It works like the renderer for
+// whole pages, with the same templates, functions and development mode, but does not wrap them in the main layout.
+func NewRenderer(assetFn func(name string) ([]byte, error), assetNameFn func() []string, assetsPath, siteDomain string) *render.Render {
+	isDevelopment := strings.Contains(siteDomain, "localhost")
+	return render.New(&client{
+		unrolled: unrolled.New(unrolled.Options{
+			Asset:         assetFn,
+			AssetNames:    assetNameFn,
+			IsDevelopment: isDevelopment,
+			Funcs:         []template.FuncMap{helper.RegisteredFuncs},
+		}),
+	}, assetsPath, siteDomain)
+}
+
+// client renders templates with unrolled/render without a layout
+type client struct {
+	unrolled *unrolled.Render
+}
+
+// BuildHTML renders the template on its own
+func (c *client) BuildHTML(w io.Writer, status int, templateName string, pageModel interface{}) error {
+	return c.unrolled.HTML(w, status, templateName, pageModel)
+}
+
+// SetError writes the error as JSON
+func (c *client) SetError(w io.Writer, status int, errorModel model.ErrorResponse) error {
+	return c.unrolled.JSON(w, status, errorModel)
+}
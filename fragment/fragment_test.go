@@ -0,0 +1,52 @@
+package fragment
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRenderer(t *testing.T) {
+	Convey("Given templates with a main layout", t, func() {
+		templates := map[string]string{
+			"templates/main.tmpl":                 `<html>{{ yield }}</html>`,
+			"templates/widget.tmpl":               `<form>{{ template "partials/fields/name" . }}</form>`,
+			"templates/partials/fields/name.tmpl": `<input value="{{ .Name }}">`,
+		}
+		asset := func(name string) ([]byte, error) {
+			if tmpl, ok := templates[name]; ok {
+				return []byte(tmpl), nil
+			}
+			return nil, errors.New("no such asset")
+		}
+		assetNames := func() []string {
+			names := make([]string, 0, len(templates))
+			for name := range templates {
+				names = append(names, name)
+			}
+			return names
+		}
+		r := NewRenderer(asset, assetNames, "//cdn", "ons.gov.uk")
+
+		Convey("When a template is rendered", func() {
+			w := httptest.NewRecorder()
+			r.BuildPage(w, struct{ Name string }{Name: "<Jo>"}, "widget")
+
+			Convey("Then it is rendered without the layout, using the partials", func() {
+				So(w.Code, ShouldEqual, 200)
+				So(w.Body.String(), ShouldEqual, `<form><input value="&lt;Jo&gt;"></form>`)
+			})
+		})
+
+		Convey("When the base page model is created", func() {
+			p := r.NewBasePageModel()
+
+			Convey("Then it has the assets path and site domain", func() {
+				So(p.PatternLibraryAssetsPath, ShouldEqual, "//cdn")
+				So(p.SiteDomain, ShouldEqual, "ons.gov.uk")
+			})
+		})
+	})
+}
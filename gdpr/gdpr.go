@@ -0,0 +1,292 @@
+package gdpr
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/deadletter"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// Ways of removing contact details from feedback
+const (
+	// ModeErase removes the name and email address
+	ModeErase = "erase"
+	// ModePseudonymise removes the name and email address, recording a pseudonym shared by all the feedback from
+	// the same person so that it can still be linked
+	ModePseudonymise = "pseudonymise"
+)
+
+// Modes lists every way of removing contact details
+var Modes = []string{ModeErase, ModePseudonymise}
+
+// IsValidMode is true for each of the Modes
+func IsValidMode(mode string) bool {
+	return slices.Contains(Modes, mode)
+}
+
+// redacted replaces contact details found in notes and replies
+const redacted = "[redacted]"
+
+// SubjectAccess is all the feedback held that was left with an email address, including feedback waiting in the
+// dead-letter store to be resent
+type SubjectAccess struct {
+	Email       string              `json:"email"`
+	GeneratedAt time.Time           `json:"generated_at"`
+	Items       []*store.Item       `json:"items"`
+	DeadLetters []*deadletter.Entry `json:"dead_letters"`
+}
+
+// ParseEmail checks that email is a bare email address, returning it without surrounding space
+func ParseEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", errors.New("an email address is required")
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", fmt.Errorf("invalid email address %q", email)
+	}
+	return email, nil
+}
+
+// Find returns all the feedback left with the email address, in any case, oldest first. Dead-lettered feedback is
+// also searched unless dl is nil.
+func Find(st *store.Store, dl *deadletter.Store, email string) ([]*store.Item, []*deadletter.Entry, error) {
+	var items []*store.Item
+	err := st.Walk(store.Filter{Email: email}, func(item *store.Item) error {
+		items = append(items, item)
+		return nil
+	})
+	if err != nil || dl == nil {
+		return items, nil, err
+	}
+
+	all, err := dl.List(deadletter.Filter{})
+	if err != nil {
+		return items, nil, err
+	}
+	var entries []*deadletter.Entry
+	for _, e := range all {
+		if isFrom(e, email) {
+			entries = append(entries, e)
+		}
+	}
+	return items, entries, nil
+}
+
+// WriteSubjectAccess writes all the feedback left with the email address to w as JSON, returning the number of items
+// and dead-lettered entries written
+func WriteSubjectAccess(w io.Writer, st *store.Store, dl *deadletter.Store, email string, now time.Time) (int, error) {
+	items, entries, err := Find(st, dl, email)
+	if err != nil {
+		return 0, err
+	}
+
+	sar := SubjectAccess{Email: email, GeneratedAt: now.UTC(), Items: items, DeadLetters: entries}
+	if sar.Items == nil {
+		sar.Items = []*store.Item{}
+	}
+	if sar.DeadLetters == nil {
+		sar.DeadLetters = []*deadletter.Entry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return len(items) + len(entries), enc.Encode(sar)
+}
+
+// Erase removes the name and email address from all the feedback left with the email address, and from the notes
+// and replies recorded against it, keeping the feedback itself. Dead-lettered feedback is changed too unless dl is
+// nil. It returns the number of items and dead-lettered entries changed.
+func Erase(ctx context.Context, st *store.Store, dl *deadletter.Store, email, mode, author string) (int, error) {
+	if !IsValidMode(mode) {
+		return 0, fmt.Errorf("unknown erasure mode %q", mode)
+	}
+	items, _, err := Find(st, nil, email)
+	if err != nil {
+		return 0, err
+	}
+
+	var pseudonym string
+	if mode == ModePseudonymise {
+		if pseudonym, err = newPseudonym(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := erase(st, items, store.Erasure{Mode: mode, Pseudonym: pseudonym, Author: author, ErasedAt: time.Now().UTC()})
+	if err != nil {
+		return n, err
+	}
+
+	if dl != nil {
+		erased, err := dl.Update(func(e *deadletter.Entry) bool {
+			if !isFrom(e, email) {
+				return false
+			}
+			eraseDeadLetter(e)
+			return true
+		})
+		n += erased
+		if err != nil {
+			return n, fmt.Errorf("failed to erase contact details from dead-lettered feedback: %w", err)
+		}
+	}
+
+	log.Info(ctx, "contact details erased from feedback", log.Data{"mode": mode, "author": author, "erased": n})
+	return n, nil
+}
+
+// EraseReceivedBefore erases the name and email address from all feedback received before the cutoff, as Erase
+// does, and from feedback dead-lettered before the cutoff unless dl is nil. It returns the IDs of the items and
+// dead-lettered entries changed. Either store may be nil when it is not configured.
+func EraseReceivedBefore(st *store.Store, dl *deadletter.Store, cutoff time.Time, author string) ([]string, error) {
+	var ids []string
+	if st != nil {
+		var items []*store.Item
+		err := st.Walk(store.Filter{Until: cutoff}, func(item *store.Item) error {
+			if hasContactDetails(item) {
+				items = append(items, item)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := erase(st, items, store.Erasure{Mode: ModeErase, Author: author, ErasedAt: time.Now().UTC()})
+		for _, item := range items[:n] {
+			ids = append(ids, item.ID)
+		}
+		if err != nil {
+			return ids, err
+		}
+	}
+	if dl == nil {
+		return ids, nil
+	}
+
+	var erased []string
+	_, err := dl.Update(func(e *deadletter.Entry) bool {
+		if !e.FailedAt.Before(cutoff) || e.Feedback == nil || (e.Feedback.Name == "" && e.Feedback.EmailAddress == "") {
+			return false
+		}
+		eraseDeadLetter(e)
+		erased = append(erased, e.ID)
+		return true
+	})
+	if err != nil {
+		return ids, fmt.Errorf("failed to erase contact details from dead-lettered feedback: %w", err)
+	}
+	return append(ids, erased...), nil
+}
+
+// erase removes the contact details from each item, recording the erasure, and returns the number of items changed
+func erase(st *store.Store, items []*store.Item, erasure store.Erasure) (int, error) {
+	for i, found := range items {
+		_, err := st.Update(found.ID, func(item *store.Item) error {
+			eraseContactDetails(item)
+			e := erasure
+			item.Erasure = &e
+			return nil
+		})
+		if err != nil {
+			return i, fmt.Errorf("failed to erase contact details from feedback %s: %w", found.ID, err)
+		}
+	}
+	return len(items), nil
+}
+
+// isFrom is true when the dead-lettered feedback was left with the email address, in any case
+func isFrom(e *deadletter.Entry, email string) bool {
+	return e.Feedback != nil && strings.EqualFold(e.Feedback.EmailAddress, email)
+}
+
+// eraseDeadLetter removes the name and email address from dead-lettered feedback. It is sent to the Feedback API as
+// it is when replayed, which has nowhere to keep a pseudonym, so they are removed whatever the erasure mode.
+func eraseDeadLetter(e *deadletter.Entry) {
+	e.Feedback.Name = ""
+	e.Feedback.EmailAddress = ""
+}
+
+func hasContactDetails(item *store.Item) bool {
+	return item.Submission != nil && (item.Submission.Name != "" || item.Submission.EmailAddress != "")
+}
+
+// eraseContactDetails removes the name and email address from the item, and any mention of them in notes and
+// replies
+func eraseContactDetails(item *store.Item) {
+	contact := contactPattern(item.Submission.Name, item.Submission.EmailAddress)
+	item.Submission.Name = ""
+	item.Submission.EmailAddress = ""
+
+	for i := range item.Notes {
+		item.Notes[i].Text = redact(contact, item.Notes[i].Text)
+	}
+	for i := range item.Replies {
+		item.Replies[i].To = ""
+		item.Replies[i].Subject = redact(contact, item.Replies[i].Subject)
+		item.Replies[i].Body = redact(contact, item.Replies[i].Body)
+	}
+}
+
+// contactPattern matches the email address or the name, in any case, or is nil if there are neither
+func contactPattern(name, email string) *regexp.Regexp {
+	var alternatives []string
+	for _, s := range []string{email, name} {
+		if s = strings.TrimSpace(s); s != "" {
+			alternatives = append(alternatives, regexp.QuoteMeta(s))
+		}
+	}
+	if len(alternatives) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)` + strings.Join(alternatives, "|"))
+}
+
+// redact replaces each match of contact in text that is not part of a longer word. Go's \b only knows ASCII
+// letters, so word boundaries are checked here to handle names such as Rhŷs.
+func redact(contact *regexp.Regexp, text string) string {
+	if contact == nil {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range contact.FindAllStringIndex(text, -1) {
+		before, _ := utf8.DecodeLastRuneInString(text[:m[0]])
+		after, _ := utf8.DecodeRuneInString(text[m[1]:])
+		if isWordRune(before) || isWordRune(after) {
+			continue
+		}
+		b.WriteString(text[last:m[0]])
+		b.WriteString(redacted)
+		last = m[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_' || r == '@'
+}
+
+func newPseudonym() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to create pseudonym: %w", err)
+	}
+	return "subject-" + hex.EncodeToString(b), nil
+}
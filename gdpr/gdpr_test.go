@@ -0,0 +1,190 @@
+package gdpr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/deadletter"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseEmail(t *testing.T) {
+	Convey("Given email addresses", t, func() {
+		testCases := []struct {
+			description string
+			email       string
+			expected    string
+			valid       bool
+		}{
+			{"a bare address", "jo@example.com", "jo@example.com", true},
+			{"an address with surrounding space", " jo@example.com ", "jo@example.com", true},
+			{"an address with a display name", "Jo <jo@example.com>", "", false},
+			{"an address without a domain", "jo", "", false},
+			{"nothing", "", "", false},
+		}
+
+		for _, tc := range testCases {
+			Convey("When "+tc.description+" is parsed", func() {
+				email, err := ParseEmail(tc.email)
+
+				Convey("Then it is accepted only if it is valid", func() {
+					So(err == nil, ShouldEqual, tc.valid)
+					So(email, ShouldEqual, tc.expected)
+				})
+			})
+		}
+	})
+}
+
+func TestGDPR(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given feedback from several people", t, func() {
+		st, err := store.New(filepath.Join(t.TempDir(), "feedback"))
+		So(err, ShouldBeNil)
+		first, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "the CPI table is wrong", Name: "Rhŷs", EmailAddress: "rhys@example.com"}}, "cy")
+		second, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "still wrong", EmailAddress: "Rhys@Example.com"}}, "en")
+		other, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "great", Name: "Jo", EmailAddress: "jo@example.com"}}, "en")
+		_, err = st.Update(first.ID, func(i *store.Item) error {
+			i.Notes = append(i.Notes, store.Note{Author: "moderator@ons.gov.uk", Text: "Rhŷs (rhys@example.com) also emailed Rhŷsiau"})
+			i.Replies = append(i.Replies, store.Reply{To: "rhys@example.com", Subject: "Your feedback", Body: "Dear Rhŷs,\nThank you."})
+			return nil
+		})
+		So(err, ShouldBeNil)
+
+		dl := deadletter.New(filepath.Join(t.TempDir(), "dead-letter.jsonl"))
+		failed, _ := dl.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "the CPI table is still wrong", Name: "Rhŷs", EmailAddress: "rhys@example.com"}}, errors.New("failed"), 500)
+		otherFailed, _ := dl.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "also great", Name: "Jo", EmailAddress: "jo@example.com"}}, errors.New("failed"), 500)
+
+		Convey("When the subject access data for an email address is written", func() {
+			var buf bytes.Buffer
+			n, err := WriteSubjectAccess(&buf, st, dl, "RHYS@example.com", time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC))
+
+			Convey("Then it holds all their feedback, regardless of case", func() {
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 3)
+				var sar SubjectAccess
+				So(json.Unmarshal(buf.Bytes(), &sar), ShouldBeNil)
+				So(sar.Email, ShouldEqual, "RHYS@example.com")
+				So(sar.Items, ShouldHaveLength, 2)
+				So(sar.Items[0].ID, ShouldEqual, first.ID)
+				So(sar.Items[0].Notes, ShouldHaveLength, 1)
+				So(sar.Items[1].ID, ShouldEqual, second.ID)
+			})
+
+			Convey("Then it holds their feedback waiting to be resent from the dead-letter store", func() {
+				var sar SubjectAccess
+				So(json.Unmarshal(buf.Bytes(), &sar), ShouldBeNil)
+				So(sar.DeadLetters, ShouldHaveLength, 1)
+				So(sar.DeadLetters[0].ID, ShouldEqual, failed.ID)
+			})
+		})
+
+		Convey("When the subject access data is written without a dead-letter store", func() {
+			var buf bytes.Buffer
+			n, err := WriteSubjectAccess(&buf, st, nil, "rhys@example.com", time.Now())
+
+			Convey("Then it holds their stored feedback only", func() {
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 2)
+				So(buf.String(), ShouldContainSubstring, `"dead_letters": []`)
+			})
+		})
+
+		Convey("When the subject access data for an unknown email address is written", func() {
+			var buf bytes.Buffer
+			n, err := WriteSubjectAccess(&buf, st, dl, "nobody@example.com", time.Now())
+
+			Convey("Then it holds no feedback", func() {
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 0)
+				So(buf.String(), ShouldContainSubstring, `"items": []`)
+			})
+		})
+
+		Convey("When their contact details are erased", func() {
+			n, err := Erase(ctx, st, dl, "rhys@example.com", ModeErase, "dpo@ons.gov.uk")
+
+			Convey("Then the contact details are removed but the feedback is kept", func() {
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 3)
+				item, _ := st.Get(first.ID)
+				So(item.Submission.Name, ShouldBeEmpty)
+				So(item.Submission.EmailAddress, ShouldBeEmpty)
+				So(item.Submission.Feedback.Feedback, ShouldEqual, "the CPI table is wrong")
+				So(item.Erasure.Mode, ShouldEqual, ModeErase)
+				So(item.Erasure.Author, ShouldEqual, "dpo@ons.gov.uk")
+				So(item.Erasure.Pseudonym, ShouldBeEmpty)
+			})
+
+			Convey("Then they are removed from notes and replies", func() {
+				item, _ := st.Get(first.ID)
+				So(item.Notes[0].Text, ShouldEqual, "[redacted] ([redacted]) also emailed Rhŷsiau")
+				So(item.Replies[0].To, ShouldBeEmpty)
+				So(item.Replies[0].Body, ShouldEqual, "Dear [redacted],\nThank you.")
+			})
+
+			Convey("Then they are removed from their dead-lettered feedback, which is kept to be resent", func() {
+				e, _ := dl.Get(failed.ID)
+				So(e.Feedback.Name, ShouldBeEmpty)
+				So(e.Feedback.EmailAddress, ShouldBeEmpty)
+				So(e.Feedback.Feedback.Feedback, ShouldEqual, "the CPI table is still wrong")
+			})
+
+			Convey("Then other people's feedback is unchanged", func() {
+				item, _ := st.Get(other.ID)
+				So(item.Submission.EmailAddress, ShouldEqual, "jo@example.com")
+				So(item.Erasure, ShouldBeNil)
+				e, _ := dl.Get(otherFailed.ID)
+				So(e.Feedback.EmailAddress, ShouldEqual, "jo@example.com")
+			})
+
+			Convey("Then the feedback can no longer be found by their email address", func() {
+				items, entries, err := Find(st, dl, "rhys@example.com")
+				So(err, ShouldBeNil)
+				So(items, ShouldBeEmpty)
+				So(entries, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When their contact details are pseudonymised", func() {
+			n, err := Erase(ctx, st, dl, "rhys@example.com", ModePseudonymise, "dpo@ons.gov.uk")
+
+			Convey("Then all their feedback shares a pseudonym", func() {
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 3)
+				a, _ := st.Get(first.ID)
+				b, _ := st.Get(second.ID)
+				So(a.Submission.EmailAddress, ShouldBeEmpty)
+				So(a.Erasure.Pseudonym, ShouldStartWith, "subject-")
+				So(b.Erasure.Pseudonym, ShouldEqual, a.Erasure.Pseudonym)
+			})
+
+			Convey("Then their contact details are removed from their dead-lettered feedback", func() {
+				e, _ := dl.Get(failed.ID)
+				So(e.Feedback.Name, ShouldBeEmpty)
+				So(e.Feedback.EmailAddress, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When contact details are erased with an unknown mode", func() {
+			_, err := Erase(ctx, st, dl, "rhys@example.com", "delete", "dpo@ons.gov.uk")
+
+			Convey("Then an error is returned and nothing is changed", func() {
+				So(err, ShouldNotBeNil)
+				item, _ := st.Get(first.ID)
+				So(item.Submission.EmailAddress, ShouldEqual, "rhys@example.com")
+				e, _ := dl.Get(failed.ID)
+				So(e.Feedback.EmailAddress, ShouldEqual, "rhys@example.com")
+			})
+		})
+	})
+}
@@ -3,6 +3,7 @@ module github.com/ONSdigital/dp-frontend-feedback-controller
 go 1.24.0
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/ONSdigital/dis-design-system-go v1.3.0
 	github.com/ONSdigital/dp-api-clients-go/v2 v2.270.0
 	github.com/ONSdigital/dp-component-test v0.20.0
@@ -21,11 +22,11 @@ require (
 	github.com/maxcnunes/httpfake v1.2.4
 	github.com/smartystreets/goconvey v1.8.1
 	github.com/stretchr/testify v1.11.1
+	github.com/unrolled/render v1.7.0
 	go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux v0.63.0
 )
 
 require (
-	github.com/BurntSushi/toml v1.5.0 // indirect
 	github.com/ONSdigital/dp-cache v0.6.0 // indirect
 	github.com/ONSdigital/dp-cookies v0.7.0 // indirect
 	github.com/ONSdigital/dp-mongodb-in-memory v1.8.1 // indirect
@@ -76,7 +77,6 @@ require (
 	github.com/smarty/assertions v1.16.0 // indirect
 	github.com/spf13/afero v1.14.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	github.com/unrolled/render v1.7.0 // indirect
 	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
 	github.com/xdg-go/scram v1.1.2 // indirect
 	github.com/xdg-go/stringprep v1.0.4 // indirect
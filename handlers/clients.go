@@ -1,15 +1,24 @@
 package handlers
 
 import (
+	"context"
 	"io"
 
 	"github.com/ONSdigital/dis-design-system-go/model"
+	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
+	sdkError "github.com/ONSdigital/dp-feedback-api/sdk/errors"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
 )
 
-//go:generate moq -out clients_mock.go -pkg handlers . ClientError RenderClient
+//go:generate moq -out clients_mock.go -pkg handlers . ClientError RenderClient FeedbackAPIClient
 
 // RenderClient interface defines page rendering
 type RenderClient interface {
 	BuildPage(w io.Writer, pageModel interface{}, templateName string)
 	NewBasePageModel() model.Page
 }
+
+// FeedbackAPIClient interface defines the Feedback API methods used by the controller
+type FeedbackAPIClient interface {
+	PostSubmission(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError
+}
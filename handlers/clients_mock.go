@@ -4,10 +4,14 @@
 package handlers
 
 import (
+	"context"
 	"io"
 	"sync"
 
 	core "github.com/ONSdigital/dis-design-system-go/model"
+	"github.com/ONSdigital/dp-feedback-api/sdk"
+	"github.com/ONSdigital/dp-feedback-api/sdk/errors"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
 )
 
 // Ensure, that ClientErrorMock does implement ClientError.
@@ -220,3 +224,81 @@ func (mock *RenderClientMock) NewBasePageModelCalls() []struct {
 	mock.lockNewBasePageModel.RUnlock()
 	return calls
 }
+
+// Ensure, that FeedbackAPIClientMock does implement FeedbackAPIClient.
+// If this is not the case, regenerate this file with moq.
+var _ FeedbackAPIClient = &FeedbackAPIClientMock{}
+
+// FeedbackAPIClientMock is a mock implementation of FeedbackAPIClient.
+//
+//	func TestSomethingThatUsesFeedbackAPIClient(t *testing.T) {
+//
+//		// make and configure a mocked FeedbackAPIClient
+//		mockedFeedbackAPIClient := &FeedbackAPIClientMock{
+//			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options sdk.Options) *errors.StatusError {
+//				panic("mock out the PostSubmission method")
+//			},
+//		}
+//
+//		// use mockedFeedbackAPIClient in code that requires FeedbackAPIClient
+//		// and then make assertions.
+//
+//	}
+type FeedbackAPIClientMock struct {
+	// PostSubmissionFunc mocks the PostSubmission method.
+	PostSubmissionFunc func(ctx context.Context, s *submission.Submission, options sdk.Options) *errors.StatusError
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// PostSubmission holds details about calls to the PostSubmission method.
+		PostSubmission []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// S is the s argument value.
+			S *submission.Submission
+			// Options is the options argument value.
+			Options sdk.Options
+		}
+	}
+	lockPostSubmission sync.RWMutex
+}
+
+// PostSubmission calls PostSubmissionFunc.
+func (mock *FeedbackAPIClientMock) PostSubmission(ctx context.Context, s *submission.Submission, options sdk.Options) *errors.StatusError {
+	if mock.PostSubmissionFunc == nil {
+		panic("FeedbackAPIClientMock.PostSubmissionFunc: method is nil but FeedbackAPIClient.PostSubmission was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		S       *submission.Submission
+		Options sdk.Options
+	}{
+		Ctx:     ctx,
+		S:       s,
+		Options: options,
+	}
+	mock.lockPostSubmission.Lock()
+	mock.calls.PostSubmission = append(mock.calls.PostSubmission, callInfo)
+	mock.lockPostSubmission.Unlock()
+	return mock.PostSubmissionFunc(ctx, s, options)
+}
+
+// PostSubmissionCalls gets all the calls that were made to PostSubmission.
+// Check the length with:
+//
+//	len(mockedFeedbackAPIClient.PostSubmissionCalls())
+func (mock *FeedbackAPIClientMock) PostSubmissionCalls() []struct {
+	Ctx     context.Context
+	S       *submission.Submission
+	Options sdk.Options
+} {
+	var calls []struct {
+		Ctx     context.Context
+		S       *submission.Submission
+		Options sdk.Options
+	}
+	mock.lockPostSubmission.RLock()
+	calls = mock.calls.PostSubmission
+	mock.lockPostSubmission.RUnlock()
+	return calls
+}
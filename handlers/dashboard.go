@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/dashboard"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mapper"
+	dphandlers "github.com/ONSdigital/dp-net/v3/handlers"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// The number of weeks of feedback summarised on the dashboard
+const (
+	defaultDashboardWeeks = 12
+	maxDashboardWeeks     = 104
+)
+
+// Dashboard shows trends in the stored feedback over the number of weeks in the weeks query parameter
+func (f *Feedback) Dashboard() http.HandlerFunc {
+	return dphandlers.ControllerHandler(func(w http.ResponseWriter, req *http.Request, lang, _, _ string) {
+		ctx := req.Context()
+
+		weeks, err := parsePositiveInt(req.URL.Query().Get("weeks"), defaultDashboardWeeks)
+		if err != nil || weeks > maxDashboardWeeks {
+			http.Error(w, fmt.Sprintf("invalid weeks, expected 1 to %d", maxDashboardWeeks), http.StatusBadRequest)
+			return
+		}
+
+		summary, err := dashboard.Summarise(f.Store, weeks, time.Now())
+		if err != nil {
+			log.Error(ctx, "failed to summarise stored feedback", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		p := mapper.CreateDashboard(req, f.Render.NewBasePageModel(), lang, summary)
+		f.Render.BuildPage(w, p, "dashboard")
+	})
+}
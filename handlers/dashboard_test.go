@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	coreModel "github.com/ONSdigital/dis-design-system-go/model"
+	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
+	sdkError "github.com/ONSdigital/dp-feedback-api/sdk/errors"
+	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/interfaces/interfacestest"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mocks"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDashboard(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+
+	Convey("Given a store of feedback and the dashboard handler", t, func() {
+		st, err := store.New(filepath.Join(t.TempDir(), "feedback"))
+		So(err, ShouldBeNil)
+		mockRenderer := &interfacestest.RendererMock{
+			BuildPageFunc:        func(w io.Writer, pageModel interface{}, templateName string) {},
+			NewBasePageModelFunc: func() coreModel.Page { return coreModel.Page{} },
+		}
+		mockFeedbackAPI := &FeedbackAPIClientMock{
+			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				return nil
+			},
+		}
+		f := NewFeedback(Dependencies{
+			Render:       mockRenderer,
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{SiteDomain: siteDomain},
+			FeedbackAPI:  mockFeedbackAPI,
+			Store:        st,
+		})
+
+		Convey("When feedback about a new service is sent", func() {
+			req := httptest.NewRequest("POST", "http://localhost/feedback?service=dev", strings.NewReader("description=testing1234&type=The+new+service"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			f.addFeedback(httptest.NewRecorder(), req, lang)
+
+			Convey("And the dashboard is requested", func() {
+				w := httptest.NewRecorder()
+				f.Dashboard()(w, httptest.NewRequest("GET", "/feedback/dashboard?weeks=4", http.NoBody))
+
+				Convey("Then it is counted as feedback about a new service", func() {
+					So(mockFeedbackAPI.PostSubmissionCalls()[0].S.Service, ShouldEqual, "dev")
+					So(w.Code, ShouldEqual, http.StatusOK)
+					So(mockRenderer.BuildPageCalls(), ShouldHaveLength, 1)
+					So(mockRenderer.BuildPageCalls()[0].TemplateName, ShouldEqual, "dashboard")
+					p := mockRenderer.BuildPageCalls()[0].PageModel.(model.Dashboard)
+					So(p.Total, ShouldEqual, 1)
+					So(p.WeeklyCounts, ShouldHaveLength, 4)
+					So(p.Types[2].LocaleKey, ShouldEqual, "DashboardTypeNewService")
+					So(p.Types[2].Count, ShouldEqual, 1)
+				})
+			})
+		})
+
+		Convey("When the dashboard is requested for too many weeks", func() {
+			w := httptest.NewRecorder()
+			f.Dashboard()(w, httptest.NewRequest("GET", "/feedback/dashboard?weeks=500", http.NoBody))
+
+			Convey("Then a 400 is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(mockRenderer.BuildPageCalls(), ShouldBeEmpty)
+			})
+		})
+	})
+}
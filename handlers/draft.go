@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/draft"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/schema"
+)
+
+// SaveDraft keeps the feedback form as it has been filled in so far, restoring it the next time the user opens the
+// feedback page. The page's script sends the form as the user writes and when they leave.
+func (f *Feedback) SaveDraft() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		if err := req.ParseForm(); err != nil {
+			log.Error(ctx, "unable to parse draft form", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		decoder := schema.NewDecoder()
+		decoder.IgnoreUnknownKeys(true)
+
+		var ff model.FeedbackForm
+		if err := decoder.Decode(&ff, req.Form); err != nil {
+			log.Error(ctx, "unable to decode draft form", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if err := f.Drafts.Save(w, req, ff); errors.Is(err, draft.ErrTooLarge) {
+			log.Warn(ctx, "draft is too large to save", log.Data{"description_length": len(ff.Description)})
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		} else if err != nil {
+			log.Error(ctx, "unable to save draft", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// DiscardDraft deletes the user's draft and returns them to an empty feedback form
+func (f *Feedback) DiscardDraft() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		f.discardDraft(w, req)
+		http.Redirect(w, req, "/feedback", http.StatusSeeOther)
+	}
+}
+
+// discardDraft deletes the user's draft, if drafts are kept
+func (f *Feedback) discardDraft(w http.ResponseWriter, req *http.Request) {
+	if f.Drafts != nil {
+		f.Drafts.Discard(w, req)
+	}
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	coreModel "github.com/ONSdigital/dis-design-system-go/model"
+	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
+	sdkError "github.com/ONSdigital/dp-feedback-api/sdk/errors"
+	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/draft"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/interfaces/interfacestest"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mapper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mocks"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// cookieSecret seals the draft and wizard cookies in tests
+const cookieSecret = "0123456789abcdef0123456789abcdef"
+
+func TestDrafts(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+
+	Convey("Given the feedback handlers with drafts", t, func() {
+		mockRenderer := &interfacestest.RendererMock{
+			BuildPageFunc: func(w io.Writer, pageModel interface{}, templateName string) {},
+			NewBasePageModelFunc: func() coreModel.Page {
+				return coreModel.Page{}
+			},
+		}
+		mockFeedbackAPI := &FeedbackAPIClientMock{
+			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				return nil
+			},
+		}
+		drafts, err := draft.New(draft.CookieName, cookieSecret, time.Minute, false)
+		So(err, ShouldBeNil)
+		f := NewFeedback(Dependencies{
+			Render:       mockRenderer,
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{SiteDomain: siteDomain},
+			FeedbackAPI:  mockFeedbackAPI,
+			Drafts:       drafts,
+		})
+
+		post := func(handler http.HandlerFunc, path, body string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			for _, c := range cookies {
+				req.AddCookie(c)
+			}
+			w := httptest.NewRecorder()
+			handler(w, req)
+			return w
+		}
+		getFeedback := func(cookies ...*http.Cookie) model.Feedback {
+			req := httptest.NewRequest("GET", "/feedback", http.NoBody)
+			req.Header.Set("Referer", "https://www.ons.gov.uk/economy")
+			for _, c := range cookies {
+				req.AddCookie(c)
+			}
+			f.GetFeedback()(httptest.NewRecorder(), req)
+			calls := mockRenderer.BuildPageCalls()
+			return calls[len(calls)-1].PageModel.(model.Feedback)
+		}
+
+		Convey("When the feedback page is opened without a draft", func() {
+			p := getFeedback()
+
+			Convey("Then the form is empty and can be saved as a draft", func() {
+				So(p.DescriptionField.Input.Value, ShouldBeEmpty)
+				So(p.PreviousURL, ShouldEqual, "https://www.ons.gov.uk/economy")
+				So(p.Draft, ShouldResemble, model.Draft{CanSave: true})
+			})
+		})
+
+		Convey("When a partly written form is saved", func() {
+			w := post(f.SaveDraft(), "/feedback/draft", "description=half+written&type="+url.QueryEscape(mapper.WholeSite)+"&name=Jo")
+			cookies := w.Result().Cookies()
+
+			Convey("Then a draft cookie is set", func() {
+				So(w.Code, ShouldEqual, http.StatusNoContent)
+				So(cookies, ShouldHaveLength, 1)
+				So(cookies[0].Name, ShouldEqual, draft.CookieName)
+			})
+
+			Convey("Then it is restored when the feedback page is opened again", func() {
+				p := getFeedback(cookies...)
+				So(p.DescriptionField.Input.Value, ShouldEqual, "half written")
+				So(p.Contact[0].Input.Value, ShouldEqual, "Jo")
+				So(p.TypeRadios.Radios[0].Input.IsChecked, ShouldBeTrue)
+				So(p.Draft, ShouldResemble, model.Draft{CanSave: true, IsRestored: true})
+			})
+
+			Convey("And the draft is discarded", func() {
+				w := post(f.DiscardDraft(), "/feedback/draft/discard", "description=half+written", cookies...)
+
+				Convey("Then the user is returned to an empty form", func() {
+					So(w.Code, ShouldEqual, http.StatusSeeOther)
+					So(w.Header().Get("Location"), ShouldEqual, "/feedback")
+					So(w.Result().Cookies()[0].MaxAge, ShouldBeLessThan, 0)
+					So(getFeedback().Draft.IsRestored, ShouldBeFalse)
+					So(mockFeedbackAPI.PostSubmissionCalls(), ShouldBeEmpty)
+				})
+			})
+
+			Convey("And the feedback is sent", func() {
+				w := post(f.AddFeedback(), "/feedback", "description=finished&type="+url.QueryEscape(mapper.WholeSite), cookies...)
+
+				Convey("Then the draft is discarded", func() {
+					So(w.Code, ShouldEqual, http.StatusMovedPermanently)
+					So(w.Result().Cookies()[0].Name, ShouldEqual, draft.CookieName)
+					So(w.Result().Cookies()[0].MaxAge, ShouldBeLessThan, 0)
+				})
+			})
+
+			Convey("And the feedback is sent with errors", func() {
+				post(f.AddFeedback(), "/feedback", "description=&type="+url.QueryEscape(mapper.WholeSite), cookies...)
+
+				Convey("Then the draft is kept", func() {
+					So(getFeedback(cookies...).Draft.IsRestored, ShouldBeTrue)
+				})
+			})
+		})
+	})
+
+	Convey("Given the feedback handlers without drafts", t, func() {
+		mockRenderer := &interfacestest.RendererMock{
+			BuildPageFunc: func(w io.Writer, pageModel interface{}, templateName string) {},
+			NewBasePageModelFunc: func() coreModel.Page {
+				return coreModel.Page{}
+			},
+		}
+		f := NewFeedback(Dependencies{
+			Render:       mockRenderer,
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{SiteDomain: siteDomain},
+			FeedbackAPI:  &FeedbackAPIClientMock{},
+		})
+
+		Convey("When the feedback page is opened", func() {
+			f.GetFeedback()(httptest.NewRecorder(), httptest.NewRequest("GET", "/feedback", http.NoBody))
+
+			Convey("Then the form is not saved as a draft", func() {
+				p := mockRenderer.BuildPageCalls()[0].PageModel.(model.Feedback)
+				So(p.Draft, ShouldResemble, model.Draft{})
+			})
+		})
+	})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/export"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// ExportFeedback streams stored feedback as CSV or NDJSON, chosen by the format query parameter. The since, until,
+// type, url_prefix, lang and tag query parameters filter the feedback exported.
+func (f *Feedback) ExportFeedback() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		query := req.URL.Query()
+
+		format := query.Get("format")
+		if format == "" {
+			format = export.FormatCSV
+		}
+		if !export.IsValidFormat(format) {
+			http.Error(w, fmt.Sprintf("unknown export format %q", format), http.StatusBadRequest)
+			return
+		}
+
+		filter, err := export.Filter{
+			Since:     query.Get("since"),
+			Until:     query.Get("until"),
+			Type:      query.Get("type"),
+			URLPrefix: query.Get("url_prefix"),
+			Lang:      query.Get("lang"),
+			Tags:      query["tag"],
+		}.StoreFilter()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		filename := fmt.Sprintf("feedback-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+		w.Header().Set("Content-Type", export.ContentTypes[format])
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+		// the status has been sent once anything is written, so a failure part way through can only be logged
+		n, err := export.Write(w, format, f.Store, filter)
+		if err != nil {
+			log.Error(ctx, "failed to export feedback", err, log.Data{"exported": n})
+			return
+		}
+		log.Info(ctx, "feedback exported", log.Data{"format": format, "exported": n})
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/interfaces/interfacestest"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExportFeedback(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a store of feedback and the export handler", t, func() {
+		st, err := store.New(filepath.Join(t.TempDir(), "feedback"))
+		So(err, ShouldBeNil)
+		census, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "census", OnsURL: "https://www.ons.gov.uk/census"}}, "en")
+		economy, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "economy", OnsURL: "https://www.ons.gov.uk/economy"}}, "en")
+		f := NewFeedback(Dependencies{
+			Render:       &interfacestest.RendererMock{},
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{},
+			FeedbackAPI:  &FeedbackAPIClientMock{},
+			Store:        st,
+		})
+
+		Convey("When feedback is exported without a format", func() {
+			w := httptest.NewRecorder()
+			f.ExportFeedback()(w, httptest.NewRequest("GET", "/feedback/export", http.NoBody))
+
+			Convey("Then every item is downloaded as CSV", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "text/csv; charset=utf-8")
+				So(w.Header().Get("Content-Disposition"), ShouldStartWith, `attachment; filename="feedback-`)
+				So(strings.Count(w.Body.String(), "\n"), ShouldEqual, 3)
+			})
+		})
+
+		Convey("When feedback for a URL prefix is exported as NDJSON", func() {
+			w := httptest.NewRecorder()
+			f.ExportFeedback()(w, httptest.NewRequest("GET", "/feedback/export?format=ndjson&url_prefix=https://www.ons.gov.uk/census", http.NoBody))
+
+			Convey("Then only the matching feedback is downloaded", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "application/x-ndjson")
+				So(w.Body.String(), ShouldContainSubstring, census.ID)
+				So(w.Body.String(), ShouldNotContainSubstring, economy.ID)
+			})
+		})
+
+		Convey("When the export is requested with an invalid format or filter", func() {
+			Convey("Then a 400 is returned", func() {
+				for _, query := range []string{"format=xlsx", "since=yesterday", "type=survey"} {
+					w := httptest.NewRecorder()
+					f.ExportFeedback()(w, httptest.NewRequest("GET", "/feedback/export?"+query, http.NoBody))
+					So(w.Code, ShouldEqual, http.StatusBadRequest)
+				}
+			})
+		})
+	})
+}
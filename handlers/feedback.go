@@ -3,33 +3,43 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"mime"
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
 
 	core "github.com/ONSdigital/dis-design-system-go/model"
 	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
 	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
 	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
-	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/duplicate"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/idempotency"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/interfaces"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/mapper"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/routing"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
 	dphandlers "github.com/ONSdigital/dp-net/v3/handlers"
 	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/gorilla/schema"
 )
 
+// fragmentMediaType is accepted by the feedback page's script, which sends the form without reloading the page
+const fragmentMediaType = "text/html-fragment"
+
 // FeedbackThanks loads the Feedback Thank you page
 func (f *Feedback) FeedbackThanks() http.HandlerFunc {
 	return dphandlers.ControllerHandler(func(w http.ResponseWriter, req *http.Request, lang, _, _ string) {
-		feedbackThanks(w, req, req.Referer(), f.Render, f.CacheService, lang, f.Config.SiteDomain, f.Config.EnableNewNavBar)
+		cfg := f.current().Config
+		feedbackThanks(w, req, req.Referer(), f.Render, f.CacheService, lang, cfg.SiteDomain, cfg.EnableNewNavBar, f.features(w, req, lang))
 	})
 }
 
-func feedbackThanks(w http.ResponseWriter, req *http.Request, uri string, rend interfaces.Renderer, cacheHelperService *cacheHelper.Helper, lang, siteDomain string, enableNewNavBar bool) {
+func feedbackThanks(w http.ResponseWriter, req *http.Request, uri string, rend interfaces.Renderer, cacheHelperService *cacheHelper.Helper, lang, siteDomain string, enableNewNavBar bool, features model.Features) {
 	basePage := rend.NewBasePageModel()
-	p := mapper.CreateGetFeedbackThanks(req, basePage, lang, uri, siteDomain)
+	p := mapper.CreateGetFeedbackThanks(req, basePage, lang, uri, siteDomain, siteDomain)
+	p.Features = features
 
 	if enableNewNavBar {
 		ctx := req.Context()
@@ -45,13 +55,52 @@ func feedbackThanks(w http.ResponseWriter, req *http.Request, uri string, rend i
 // GetFeedback handles the loading of a feedback page
 func (f *Feedback) GetFeedback() http.HandlerFunc {
 	return dphandlers.ControllerHandler(func(w http.ResponseWriter, req *http.Request, lang, _, _ string) {
-		getFeedback(w, req, []core.ErrorItem{}, model.FeedbackForm{URL: req.Referer()}, lang, f.Render, f.CacheService, f.Config.EnableNewNavBar)
+		if f.Wizard != nil {
+			f.getWizard(w, req, lang)
+			return
+		}
+
+		ff := model.FeedbackForm{URL: req.Referer()}
+		d := model.Draft{CanSave: f.Drafts != nil}
+		if d.CanSave {
+			ff, d.IsRestored = f.Drafts.Load(req)
+			if !d.IsRestored {
+				ff = model.FeedbackForm{URL: req.Referer()}
+			}
+		}
+		f.renderFeedback(w, req, []core.ErrorItem{}, ff, lang, f.current().Config.EnableNewNavBar, d)
 	})
 }
 
-func getFeedback(w http.ResponseWriter, req *http.Request, validationErrors []core.ErrorItem, ff model.FeedbackForm, lang string, rend interfaces.Renderer, cacheHelperService *cacheHelper.Helper, enableNewNavBar bool) {
+// renderFeedback renders the feedback page, or only the form if the request asked for a fragment
+func (f *Feedback) renderFeedback(w http.ResponseWriter, req *http.Request, validationErrors []core.ErrorItem, ff model.FeedbackForm, lang string, enableNewNavBar bool, d model.Draft) {
+	w.Header().Add("Vary", "Accept")
+	features := f.features(w, req, lang)
+
+	// a re-rendered form keeps the variant it was posted from, so the user is not switched part way through
+	formVariant := ff.Variant
+	if !f.Variants.IsTested(formVariant) {
+		formVariant = f.Variants.Assign(w, req)
+	}
+	if formVariant != "" && req.Method == http.MethodGet {
+		log.Info(req.Context(), "feedback form viewed", log.Data{"variant": formVariant})
+	}
+
+	if f.Fragments != nil && isFragmentRequest(req) {
+		p := mapper.CreateGetFeedback(req, f.Fragments.NewBasePageModel(), validationErrors, ff, lang, formVariant)
+		p.Draft = d
+		p.Features = features
+		f.Fragments.BuildPage(w, p, "partials/feedback-form")
+		return
+	}
+	getFeedback(w, req, validationErrors, ff, lang, formVariant, f.Render, f.CacheService, enableNewNavBar, d, features)
+}
+
+func getFeedback(w http.ResponseWriter, req *http.Request, validationErrors []core.ErrorItem, ff model.FeedbackForm, lang, formVariant string, rend interfaces.Renderer, cacheHelperService *cacheHelper.Helper, enableNewNavBar bool, d model.Draft, features model.Features) {
 	basePage := rend.NewBasePageModel()
-	p := mapper.CreateGetFeedback(req, basePage, validationErrors, ff, lang)
+	p := mapper.CreateGetFeedback(req, basePage, validationErrors, ff, lang, formVariant)
+	p.Draft = d
+	p.Features = features
 
 	if enableNewNavBar {
 		ctx := context.Background()
@@ -67,11 +116,11 @@ func getFeedback(w http.ResponseWriter, req *http.Request, validationErrors []co
 // AddFeedback handles a users feedback request
 func (f *Feedback) AddFeedback() http.HandlerFunc {
 	return dphandlers.ControllerHandler(func(w http.ResponseWriter, req *http.Request, lang, _, _ string) {
-		addFeedback(w, req, f.Render, lang, f.Config.SiteDomain, f.CacheService, f.Config)
+		f.addFeedback(w, req, lang)
 	})
 }
 
-func addFeedback(w http.ResponseWriter, req *http.Request, rend interfaces.Renderer, lang, siteDomain string, cacheService *cacheHelper.Helper, cfg *config.Config) {
+func (f *Feedback) addFeedback(w http.ResponseWriter, req *http.Request, lang string) {
 	ctx := req.Context()
 
 	if err := req.ParseForm(); err != nil {
@@ -90,13 +139,104 @@ func addFeedback(w http.ResponseWriter, req *http.Request, rend interfaces.Rende
 		return
 	}
 
-	validationErrors := validateForm(&ff, siteDomain)
+	if f.Wizard != nil && req.Form.Has("wizard-step") {
+		f.addWizardStep(w, req, ff, lang)
+		return
+	}
+
+	if !f.Variants.IsTested(ff.Variant) {
+		ff.Variant = ""
+	}
+
+	validationErrors := validateForm(&ff, f.current().Config.SiteDomain)
 	if len(validationErrors) > 0 {
-		getFeedback(w, req, validationErrors, ff, lang, rend, cacheService, false)
+		f.renderFeedback(w, req, validationErrors, ff, lang, false, model.Draft{CanSave: f.Drafts != nil})
 		return
 	}
 
-	feedbackAPIClient := feedbackAPI.New(cfg.APIRouterURL)
+	f.submit(w, req, &ff, lang, f.discardDraft)
+}
+
+// submit sends validated feedback unless it is a repeat of feedback already received, calling discard to delete the
+// user's saved copy of it once it has been
+func (f *Feedback) submit(w http.ResponseWriter, req *http.Request, ff *model.FeedbackForm, lang string, discard func(http.ResponseWriter, *http.Request)) {
+	ctx := req.Context()
+	redirectURL := thanksURL(ff.URL)
+
+	trackKey := f.Idempotency != nil && idempotency.IsValidKey(ff.IdempotencyKey)
+	if trackKey {
+		if originalURL, isNew := f.Idempotency.Add(ff.IdempotencyKey, redirectURL); !isNew {
+			log.Info(ctx, "suppressing repeated feedback submission", log.Data{"idempotency_key": ff.IdempotencyKey})
+			discard(w, req)
+			f.feedbackReceived(w, req, originalURL, ff.URL, lang)
+			return
+		}
+	}
+
+	if ff.Variant != "" {
+		log.Info(ctx, "feedback form submitted", log.Data{"variant": ff.Variant})
+	}
+
+	if kept, err := f.send(ctx, ff, lang, req.URL.Query().Get("service")); err != nil && !kept {
+		// only a submission that has been kept for replay counts as received, anything else may be retried
+		if trackKey {
+			f.Idempotency.Remove(ff.IdempotencyKey)
+		}
+		if isFragmentRequest(req) {
+			// the page's script then posts the form as normal, which shows what happened as it would without the script
+			w.WriteHeader(http.StatusBadGateway)
+		}
+		return
+	}
+
+	discard(w, req)
+	f.feedbackReceived(w, req, redirectURL, ff.URL, lang)
+}
+
+// feedbackReceived redirects to the thanks page, or renders only the thank you message if the request asked for a
+// fragment
+func (f *Feedback) feedbackReceived(w http.ResponseWriter, req *http.Request, redirectURL, pageURL, lang string) {
+	w.Header().Add("Vary", "Accept")
+	if f.Fragments != nil && isFragmentRequest(req) {
+		siteDomain := f.current().Config.SiteDomain
+		p := mapper.CreateGetFeedbackThanks(req, f.Fragments.NewBasePageModel(), lang, returnTo(pageURL), siteDomain, siteDomain)
+		p.Features = f.features(w, req, lang)
+		f.Fragments.BuildPage(w, p, "partials/feedback-thanks")
+		return
+	}
+	http.Redirect(w, req, redirectURL, http.StatusMovedPermanently)
+}
+
+// isFragmentRequest reports whether the request accepts fragmentMediaType, asking for part of a page to swap into
+// the one already shown
+func isFragmentRequest(req *http.Request) bool {
+	for _, accept := range strings.Split(req.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(accept); err == nil && mediaType == fragmentMediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// thanksURL is the thank you page for feedback on pageURL, which links back to it
+func thanksURL(pageURL string) string {
+	return fmt.Sprintf("/feedback/thanks?returnTo=%s", returnTo(pageURL))
+}
+
+// returnTo is where the user goes after leaving feedback on pageURL
+func returnTo(pageURL string) string {
+	if pageURL == mapper.WholeSite || pageURL == "" {
+		return "https://www.ons.gov.uk"
+	}
+	return pageURL
+}
+
+// send sends validated feedback to the Feedback API and keeps it for moderation. If it cannot be sent the error is
+// returned along with whether the feedback was dead-lettered for replay.
+func (f *Feedback) send(ctx context.Context, ff *model.FeedbackForm, lang, service string) (bool, error) {
+	if f.isDroppedDuplicate(ctx, ff) {
+		return false, nil
+	}
 
 	isPageUsefulVal := false
 	var isGeneralFeedbackVal bool
@@ -107,37 +247,121 @@ func addFeedback(w http.ResponseWriter, req *http.Request, rend interfaces.Rende
 		isGeneralFeedbackVal = false
 	}
 
-	f := &feedbackAPIModel.Feedback{
-		IsPageUseful:      &isPageUsefulVal,
-		IsGeneralFeedback: &isGeneralFeedbackVal,
-		OnsURL:            ff.URL,
-		Feedback:          ff.Description,
-		Name:              ff.Name,
-		EmailAddress:      ff.Email,
+	feedback := &submission.Submission{
+		Feedback: feedbackAPIModel.Feedback{
+			IsPageUseful:      &isPageUsefulVal,
+			IsGeneralFeedback: &isGeneralFeedbackVal,
+			OnsURL:            ff.URL,
+			Feedback:          ff.Description,
+			Name:              ff.Name,
+			EmailAddress:      ff.Email,
+		},
+		Variant: ff.Variant,
+	}
+	if ff.Email != "" {
+		feedback.Consent = &submission.Consent{GivenAt: time.Now().UTC(), Version: mapper.ConsentVersion}
+	}
+	if ff.Rating >= mapper.MinRating && ff.Rating <= mapper.MaxRating {
+		rating := ff.Rating
+		feedback.Rating = &rating
 	}
+	if ff.Type == mapper.NewService {
+		feedback.Service = service
+	}
+	f.enrich(ctx, feedback, lang, service)
 
-	opts := feedbackAPI.Options{AuthToken: cfg.ServiceAuthToken}
+	opts := feedbackAPI.Options{AuthToken: f.current().Config.ServiceAuthToken}
 
-	err := feedbackAPIClient.PostFeedback(ctx, f, opts)
+	err := f.FeedbackAPI.PostSubmission(ctx, feedback, opts)
 
 	if err != nil {
 		statusCode := err.Status()
 		log.Error(ctx, "failed to send feedback", err, log.Data{"code": statusCode})
-		return
+		return f.deadLetter(ctx, feedback, err, statusCode), err
 	}
 
-	returnTo := ff.URL
+	if f.Duplicates != nil {
+		f.Duplicates.Record(ff.URL, ff.Description)
+	}
 
-	if returnTo == mapper.WholeSite || returnTo == "" {
-		returnTo = "https://www.ons.gov.uk"
+	if f.Store != nil {
+		item, err := f.Store.Add(ctx, feedback, lang)
+		if err != nil {
+			log.Error(ctx, "failed to store feedback for moderation", err)
+		} else if f.Search != nil {
+			f.Search.Add(item)
+		}
 	}
+	return false, nil
+}
 
-	redirectURL := fmt.Sprintf("/feedback/thanks?returnTo=%s", returnTo)
-	http.Redirect(w, req, redirectURL, http.StatusMovedPermanently)
+// enrich adds the metadata derived from the feedback that is sent along with it
+func (f *Feedback) enrich(ctx context.Context, s *submission.Submission, lang, service string) {
+	r := f.current()
+	if r.Tagger != nil {
+		s.Tags = r.Tagger.Tag(lang, s.Feedback.Feedback, s.OnsURL)
+	}
+
+	if f.Taxonomy != nil {
+		classification, err := f.Taxonomy.Classify(ctx, s.OnsURL)
+		if err != nil {
+			log.Warn(ctx, "unable to classify feedback url", log.FormatErrors([]error{err}), log.Data{"url": s.OnsURL})
+		}
+		s.Topic, s.Subtopic = classification.Topic, classification.Subtopic
+	}
+
+	if r.Router != nil {
+		s.Team = r.Router.Route(routing.Request{URL: s.OnsURL, Service: service, Tags: s.Tags}).Team
+	}
+
+	if f.Sentiment != nil {
+		score := f.Sentiment.Score(lang, s.Feedback.Feedback)
+		s.Sentiment = &score
+	}
+}
+
+// isDroppedDuplicate checks the description against recent feedback for the same URL, returning true if it should not be sent
+func (f *Feedback) isDroppedDuplicate(ctx context.Context, ff *model.FeedbackForm) bool {
+	if f.Duplicates == nil {
+		return false
+	}
+
+	result := f.Duplicates.Check(ff.URL, ff.Description)
+	if !result.IsDuplicate {
+		return false
+	}
+
+	logData := log.Data{"url": ff.URL, "similarity": result.Similarity, "action": f.Duplicates.Action, "stats": f.Duplicates.Stats()}
+	if f.Duplicates.Action == duplicate.ActionDrop {
+		log.Warn(ctx, "dropping duplicate feedback submission", logData)
+		return true
+	}
+	log.Warn(ctx, "duplicate feedback submission detected", logData)
+	return false
+}
+
+// deadLetter keeps a submission that could not be sent, returning true if it was stored
+func (f *Feedback) deadLetter(ctx context.Context, feedback *submission.Submission, sendErr error, statusCode int) bool {
+	if f.DeadLetters == nil {
+		return false
+	}
+	if _, err := f.DeadLetters.Add(ctx, feedback, sendErr, statusCode); err != nil {
+		log.Error(ctx, "failed to dead-letter feedback", err)
+		return false
+	}
+	return true
 }
 
 // validateForm is a helper function that validates a slice of FeedbackForm to determine if there are form validation errors
 func validateForm(ff *model.FeedbackForm, siteDomain string) (validationErrors []core.ErrorItem) {
+	validationErrors = append(validationErrors, validateType(ff, siteDomain)...)
+	validationErrors = append(validationErrors, validateDescription(ff)...)
+	validationErrors = append(validationErrors, validateContact(ff)...)
+	return validationErrors
+}
+
+// validateType checks what the feedback is about, the type and the URL of a specific page
+func validateType(ff *model.FeedbackForm, siteDomain string) (validationErrors []core.ErrorItem) {
 	if ff.Type == "" && ff.FormLocation != "footer" {
 		validationErrors = append(validationErrors, core.ErrorItem{
 			Description: core.Localisation{
@@ -173,7 +397,11 @@ func validateForm(ff *model.FeedbackForm, siteDomain string) (validationErrors [
 	} else if ff.Type != mapper.ASpecificPage && ff.URL != "" {
 		ff.URL = ""
 	}
+	return validationErrors
+}
 
+// validateDescription checks that there is some feedback
+func validateDescription(ff *model.FeedbackForm) (validationErrors []core.ErrorItem) {
 	ff.Description = strings.TrimSpace(ff.Description)
 	if ff.Description == "" {
 		validationErrors = append(validationErrors, core.ErrorItem{
@@ -185,7 +413,26 @@ func validateForm(ff *model.FeedbackForm, siteDomain string) (validationErrors [
 		})
 		ff.IsDescriptionErr = true
 	}
+	return validationErrors
+}
+
+// validateRating checks that one of the ratings was chosen, it is only asked for by the wizard
+func validateRating(ff *model.FeedbackForm) (validationErrors []core.ErrorItem) {
+	if ff.Rating < mapper.MinRating || ff.Rating > mapper.MaxRating {
+		validationErrors = append(validationErrors, core.ErrorItem{
+			Description: core.Localisation{
+				LocaleKey: "FeedbackChooseRating",
+				Plural:    1,
+			},
+			URL: "#rating-error",
+		})
+		ff.IsRatingErr = true
+	}
+	return validationErrors
+}
 
+// validateContact checks the optional email address and that consent was given to use it
+func validateContact(ff *model.FeedbackForm) (validationErrors []core.ErrorItem) {
 	if ff.Email != "" {
 		if ok, err := regexp.MatchString("^[A-Za-z0-9.`!#$%&'*+-/=?^_{|}~]+@[A-Za-z0-9.-]+\\.[A-Za-z]{2,6}$", ff.Email); !ok || err != nil {
 			validationErrors = append(validationErrors, core.ErrorItem{
@@ -197,6 +444,16 @@ func validateForm(ff *model.FeedbackForm, siteDomain string) (validationErrors [
 			})
 			ff.IsEmailErr = true
 		}
+		if !ff.Consent {
+			validationErrors = append(validationErrors, core.ErrorItem{
+				Description: core.Localisation{
+					LocaleKey: "FeedbackAlertConsent",
+					Plural:    1,
+				},
+				URL: "#consent-error",
+			})
+			ff.IsConsentErr = true
+		}
 	}
 	return validationErrors
 }
@@ -2,23 +2,35 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/ONSdigital/dis-design-system-go/helper"
 	coreModel "github.com/ONSdigital/dis-design-system-go/model"
+	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
+	sdkError "github.com/ONSdigital/dp-feedback-api/sdk/errors"
 	cacheClient "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/client"
 	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/deadletter"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/duplicate"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/idempotency"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/interfaces/interfacestest"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/mapper"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/mocks"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/sentiment"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/tagging"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/taxonomy"
 	topicModel "github.com/ONSdigital/dp-topic-api/models"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -55,7 +67,7 @@ func Test_getFeedback(t *testing.T) {
 				},
 			}}
 		Convey("When getFeedback is called", func() {
-			getFeedback(w, req, []coreModel.ErrorItem{}, ff, lang, mockRenderer, mockNagivationCache, false)
+			getFeedback(w, req, []coreModel.ErrorItem{}, ff, lang, "", mockRenderer, mockNagivationCache, false, model.Draft{}, nil)
 			Convey("Then a 200 request is returned", func() {
 				So(w.Code, ShouldEqual, http.StatusOK)
 			})
@@ -91,8 +103,143 @@ func Test_addFeedback(t *testing.T) {
 				StartBackgroundUpdateFunc: func(ctx context.Context, errorChannel chan error) {
 				},
 			}}
+		mockFeedbackAPI := &FeedbackAPIClientMock{
+			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				return nil
+			},
+		}
+
 		Convey("When addFeedback is called", func() {
-			addFeedback(w, req, mockRenderer, lang, siteDomain, mockNagivationCache, &config.Config{})
+			f := NewFeedback(Dependencies{
+				Render:       mockRenderer,
+				CacheService: mockNagivationCache,
+				Config:       &config.Config{SiteDomain: siteDomain},
+				FeedbackAPI:  mockFeedbackAPI,
+			})
+			f.addFeedback(w, req, lang)
+			Convey("Then the renderer is not called", func() {
+				So(len(mockRenderer.BuildPageCalls()), ShouldEqual, 0)
+			})
+
+			Convey("Then the feedback is sent to the feedback API", func() {
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+				So(mockFeedbackAPI.PostSubmissionCalls()[0].S.Feedback.Feedback, ShouldEqual, "testing1234")
+			})
+
+			Convey("Then the user is redirected to the thanks page", func() {
+				So(w.Code, ShouldEqual, http.StatusMovedPermanently)
+				So(w.Header().Get("Location"), ShouldEqual, "/feedback/thanks?returnTo=https://www.ons.gov.uk")
+			})
+		})
+
+		Convey("When addFeedback is called with a tagger", func() {
+			tagger, err := tagging.New([]tagging.Rule{{Tag: "testing", Keywords: map[string][]string{"en": {"testing1234"}}}})
+			So(err, ShouldBeNil)
+			f := NewFeedback(Dependencies{
+				Render:       mockRenderer,
+				CacheService: mockNagivationCache,
+				Config:       &config.Config{SiteDomain: siteDomain},
+				FeedbackAPI:  mockFeedbackAPI,
+				Tagger:       tagger,
+			})
+			f.addFeedback(w, req, lang)
+
+			Convey("Then the matching tags are sent with the feedback", func() {
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+				So(mockFeedbackAPI.PostSubmissionCalls()[0].S.Tags, ShouldResemble, []string{"testing"})
+			})
+		})
+
+		Convey("When addFeedback is called with a sentiment scorer", func() {
+			scorer, err := sentiment.NewDefaultScorer()
+			So(err, ShouldBeNil)
+			f := NewFeedback(Dependencies{
+				Render:       mockRenderer,
+				CacheService: mockNagivationCache,
+				Config:       &config.Config{SiteDomain: siteDomain},
+				FeedbackAPI:  mockFeedbackAPI,
+				Sentiment:    scorer,
+			})
+			f.addFeedback(w, req, lang)
+
+			Convey("Then a neutral sentiment score is sent with the feedback", func() {
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+				So(mockFeedbackAPI.PostSubmissionCalls()[0].S.Sentiment, ShouldNotBeNil)
+				So(*mockFeedbackAPI.PostSubmissionCalls()[0].S.Sentiment, ShouldEqual, 0)
+			})
+		})
+	})
+
+	Convey("Given a valid request about a page in the site navigation", t, func() {
+		form := url.Values{"description": {"testing1234"}, "type": {mapper.ASpecificPage}, "url": {"https://www.ons.gov.uk/economy/inflationandpriceindices/bulletins"}}
+		req := httptest.NewRequest("POST", "http://localhost", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		navigation := &cacheHelper.Helper{
+			Clienter: &cacheClient.ClienterMock{
+				GetNavigationDataFunc: func(ctx context.Context, lang string) (*topicModel.Navigation, error) {
+					return &topicModel.Navigation{Items: &[]topicModel.TopicNonReferential{{
+						Label: "Economy",
+						URI:   "/economy",
+						SubtopicItems: &[]topicModel.TopicNonReferential{
+							{Label: "Inflation and price indices", URI: "/economy/inflationandpriceindices"},
+						},
+					}}}, nil
+				},
+			}}
+		mockFeedbackAPI := &FeedbackAPIClientMock{
+			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				return nil
+			},
+		}
+
+		Convey("When addFeedback is called with a taxonomy classifier", func() {
+			f := NewFeedback(Dependencies{
+				Render:       &interfacestest.RendererMock{},
+				CacheService: navigation,
+				Config:       &config.Config{SiteDomain: siteDomain},
+				FeedbackAPI:  mockFeedbackAPI,
+				Taxonomy:     taxonomy.NewClassifier(navigation),
+			})
+			f.addFeedback(w, req, lang)
+
+			Convey("Then the topic and subtopic of the page are sent with the feedback", func() {
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+				So(mockFeedbackAPI.PostSubmissionCalls()[0].S.Topic, ShouldEqual, "Economy")
+				So(mockFeedbackAPI.PostSubmissionCalls()[0].S.Subtopic, ShouldEqual, "Inflation and price indices")
+			})
+		})
+	})
+
+	Convey("Given the feedback API fails to accept a valid request", t, func() {
+		body := strings.NewReader("description=testing1234&type=test&email=hello@world.com&consent=true")
+		req := httptest.NewRequest("POST", "http://localhost", body)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+
+		mockRenderer := &interfacestest.RendererMock{
+			BuildPageFunc: func(w io.Writer, pageModel interface{}, templateName string) {},
+			NewBasePageModelFunc: func() coreModel.Page {
+				return coreModel.Page{}
+			},
+		}
+
+		mockFeedbackAPI := &FeedbackAPIClientMock{
+			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				return &sdkError.StatusError{Err: errors.New("feedback API unavailable"), Code: http.StatusBadGateway}
+			},
+		}
+
+		deadLetters := deadletter.New(filepath.Join(t.TempDir(), "dead-letter.jsonl"))
+
+		Convey("When addFeedback is called without a dead-letter store", func() {
+			f := NewFeedback(Dependencies{
+				Render:       mockRenderer,
+				CacheService: &cacheHelper.Helper{},
+				Config:       &config.Config{SiteDomain: siteDomain},
+				FeedbackAPI:  mockFeedbackAPI,
+			})
+			f.addFeedback(w, req, lang)
 			Convey("Then the renderer is not called", func() {
 				So(len(mockRenderer.BuildPageCalls()), ShouldEqual, 0)
 			})
@@ -101,6 +248,155 @@ func Test_addFeedback(t *testing.T) {
 				So(w.Code, ShouldEqual, http.StatusOK)
 			})
 		})
+
+		Convey("When addFeedback is called", func() {
+			f := NewFeedback(Dependencies{
+				Render:       mockRenderer,
+				CacheService: &cacheHelper.Helper{},
+				Config:       &config.Config{SiteDomain: siteDomain},
+				FeedbackAPI:  mockFeedbackAPI,
+				DeadLetters:  deadLetters,
+			})
+			f.addFeedback(w, req, lang)
+
+			Convey("Then the user is thanked, as the feedback has been kept to send later", func() {
+				So(w.Code, ShouldEqual, http.StatusMovedPermanently)
+				So(w.Header().Get("Location"), ShouldStartWith, "/feedback/thanks")
+			})
+
+			Convey("Then the submission is written to the dead-letter store", func() {
+				entries, err := deadLetters.List(deadletter.Filter{})
+				So(err, ShouldBeNil)
+				So(entries, ShouldHaveLength, 1)
+				So(entries[0].Feedback.Feedback.Feedback, ShouldEqual, "testing1234")
+				So(entries[0].Feedback.EmailAddress, ShouldEqual, "hello@world.com")
+				So(entries[0].Feedback.Consent.Version, ShouldEqual, mapper.ConsentVersion)
+				So(entries[0].Feedback.Consent.GivenAt, ShouldNotBeZeroValue)
+				So(entries[0].Error, ShouldEqual, "feedback API unavailable")
+				So(entries[0].StatusCode, ShouldEqual, http.StatusBadGateway)
+			})
+		})
+	})
+
+	Convey("Given a form that is submitted twice with the same idempotency key", t, func() {
+		newRequest := func(url string) *http.Request {
+			body := strings.NewReader("description=testing1234&type=" + mapper.ASpecificPage + "&url=" + url + "&idempotency-key=abc123")
+			req := httptest.NewRequest("POST", "http://localhost", body)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			return req
+		}
+
+		mockRenderer := &interfacestest.RendererMock{
+			BuildPageFunc: func(w io.Writer, pageModel interface{}, templateName string) {},
+			NewBasePageModelFunc: func() coreModel.Page {
+				return coreModel.Page{}
+			},
+		}
+
+		var postErr *sdkError.StatusError
+		mockFeedbackAPI := &FeedbackAPIClientMock{
+			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				return postErr
+			},
+		}
+
+		f := NewFeedback(Dependencies{
+			Render:       mockRenderer,
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{SiteDomain: siteDomain},
+			FeedbackAPI:  mockFeedbackAPI,
+			Idempotency:  idempotency.NewMemoryStore(10, time.Minute),
+		})
+
+		Convey("When both submissions are accepted by the feedback API", func() {
+			first := httptest.NewRecorder()
+			f.addFeedback(first, newRequest("https://www.ons.gov.uk/first"), lang)
+			second := httptest.NewRecorder()
+			f.addFeedback(second, newRequest("https://www.ons.gov.uk/second"), lang)
+
+			Convey("Then the feedback is only sent once", func() {
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+			})
+
+			Convey("Then the repeat is redirected to the original thanks page", func() {
+				So(second.Code, ShouldEqual, http.StatusMovedPermanently)
+				So(second.Header().Get("Location"), ShouldEqual, first.Header().Get("Location"))
+				So(second.Header().Get("Location"), ShouldEqual, "/feedback/thanks?returnTo=https://www.ons.gov.uk/first")
+			})
+		})
+
+		Convey("When the first submission cannot be sent or dead-lettered", func() {
+			postErr = &sdkError.StatusError{Err: errors.New("feedback API unavailable"), Code: http.StatusBadGateway}
+			f.addFeedback(httptest.NewRecorder(), newRequest("https://www.ons.gov.uk/first"), lang)
+			postErr = nil
+			retry := httptest.NewRecorder()
+			f.addFeedback(retry, newRequest("https://www.ons.gov.uk/first"), lang)
+
+			Convey("Then the retry is sent", func() {
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 2)
+				So(retry.Code, ShouldEqual, http.StatusMovedPermanently)
+			})
+		})
+	})
+
+	Convey("Given duplicate detection is set to drop repeated descriptions", t, func() {
+		newRequest := func() *http.Request {
+			body := strings.NewReader("description=this+is+spam&type=" + url.QueryEscape(mapper.WholeSite))
+			req := httptest.NewRequest("POST", "http://localhost", body)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			return req
+		}
+
+		var postErr *sdkError.StatusError
+		mockFeedbackAPI := &FeedbackAPIClientMock{
+			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				return postErr
+			},
+		}
+
+		detector, err := duplicate.NewDetector(duplicate.Config{Action: duplicate.ActionDrop, Threshold: 0.9, Window: time.Hour, HistorySize: 10})
+		So(err, ShouldBeNil)
+
+		f := NewFeedback(Dependencies{
+			Render: &interfacestest.RendererMock{
+				BuildPageFunc: func(w io.Writer, pageModel interface{}, templateName string) {},
+				NewBasePageModelFunc: func() coreModel.Page {
+					return coreModel.Page{}
+				},
+			},
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{SiteDomain: siteDomain},
+			FeedbackAPI:  mockFeedbackAPI,
+			Duplicates:   detector,
+		})
+
+		Convey("When the same description is submitted twice", func() {
+			f.addFeedback(httptest.NewRecorder(), newRequest(), lang)
+			w := httptest.NewRecorder()
+			f.addFeedback(w, newRequest(), lang)
+
+			Convey("Then only the first is sent to the feedback API", func() {
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+			})
+
+			Convey("Then the user is still redirected to the thanks page", func() {
+				So(w.Code, ShouldEqual, http.StatusMovedPermanently)
+				So(w.Header().Get("Location"), ShouldEqual, "/feedback/thanks?returnTo=https://www.ons.gov.uk")
+			})
+		})
+
+		Convey("When the first submission fails to send and the user tries again", func() {
+			postErr = &sdkError.StatusError{Err: errors.New("feedback API unavailable"), Code: http.StatusInternalServerError}
+			f.addFeedback(httptest.NewRecorder(), newRequest(), lang)
+			postErr = nil
+			retry := httptest.NewRecorder()
+			f.addFeedback(retry, newRequest(), lang)
+
+			Convey("Then the retry is not dropped as a duplicate", func() {
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 2)
+				So(retry.Code, ShouldEqual, http.StatusMovedPermanently)
+			})
+		})
 	})
 
 	Convey("Given an error returned from the sender", t, func() {
@@ -128,7 +424,13 @@ func Test_addFeedback(t *testing.T) {
 				},
 			}}
 		Convey("When addFeedback is called", func() {
-			addFeedback(w, req, mockRenderer, lang, siteDomain, mockNagivationCache, &config.Config{})
+			f := NewFeedback(Dependencies{
+				Render:       mockRenderer,
+				CacheService: mockNagivationCache,
+				Config:       &config.Config{SiteDomain: siteDomain},
+				FeedbackAPI:  &FeedbackAPIClientMock{},
+			})
+			f.addFeedback(w, req, lang)
 			Convey("Then the renderer is called", func() {
 				So(len(mockRenderer.BuildPageCalls()), ShouldEqual, 1)
 			})
@@ -164,7 +466,13 @@ func Test_addFeedback(t *testing.T) {
 				},
 			}}
 		Convey("When addFeedback is called", func() {
-			addFeedback(w, req, mockRenderer, lang, siteDomain, mockNagivationCache, &config.Config{})
+			f := NewFeedback(Dependencies{
+				Render:       mockRenderer,
+				CacheService: mockNagivationCache,
+				Config:       &config.Config{SiteDomain: siteDomain},
+				FeedbackAPI:  &FeedbackAPIClientMock{},
+			})
+			f.addFeedback(w, req, lang)
 			Convey("Then the renderer is not called", func() {
 				So(len(mockRenderer.BuildPageCalls()), ShouldEqual, 0)
 			})
@@ -202,7 +510,13 @@ func Test_addFeedback(t *testing.T) {
 				},
 			}}
 		Convey("When addFeedback is called", func() {
-			addFeedback(w, req, mockRenderer, lang, siteDomain, mockNagivationCache, &config.Config{})
+			f := NewFeedback(Dependencies{
+				Render:       mockRenderer,
+				CacheService: mockNagivationCache,
+				Config:       &config.Config{SiteDomain: siteDomain},
+				FeedbackAPI:  &FeedbackAPIClientMock{},
+			})
+			f.addFeedback(w, req, lang)
 			Convey("Then the renderer is called to render the feedback page", func() {
 				So(len(mockRenderer.BuildPageCalls()), ShouldEqual, 1)
 			})
@@ -214,6 +528,108 @@ func Test_addFeedback(t *testing.T) {
 	})
 }
 
+func Test_feedbackFragments(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+	Convey("Given the feedback handlers with a fragment renderer", t, func() {
+		mockRenderer := &interfacestest.RendererMock{
+			BuildPageFunc: func(w io.Writer, pageModel interface{}, templateName string) {},
+			NewBasePageModelFunc: func() coreModel.Page {
+				return coreModel.Page{}
+			},
+		}
+		mockFragments := &interfacestest.RendererMock{
+			BuildPageFunc: func(w io.Writer, pageModel interface{}, templateName string) {},
+			NewBasePageModelFunc: func() coreModel.Page {
+				return coreModel.Page{}
+			},
+		}
+		var postErr *sdkError.StatusError
+		mockFeedbackAPI := &FeedbackAPIClientMock{
+			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				return postErr
+			},
+		}
+		f := NewFeedback(Dependencies{
+			Render:       mockRenderer,
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{SiteDomain: siteDomain},
+			FeedbackAPI:  mockFeedbackAPI,
+			Fragments:    mockFragments,
+		})
+
+		post := func(body, accept string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/feedback", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("Accept", accept)
+			w := httptest.NewRecorder()
+			f.addFeedback(w, req, lang)
+			return w
+		}
+		validBody := "description=testing1234&type=" + url.QueryEscape(mapper.ASpecificPage) + "&url=https://www.ons.gov.uk/economy"
+
+		Convey("When the feedback form is requested as a fragment", func() {
+			req := httptest.NewRequest("GET", "/feedback", http.NoBody)
+			req.Header.Set("Accept", "text/html-fragment, */*;q=0.8")
+			w := httptest.NewRecorder()
+			f.GetFeedback()(w, req)
+
+			Convey("Then only the form is rendered", func() {
+				So(mockFragments.BuildPageCalls(), ShouldHaveLength, 1)
+				So(mockFragments.BuildPageCalls()[0].TemplateName, ShouldEqual, "partials/feedback-form")
+				So(mockRenderer.BuildPageCalls(), ShouldBeEmpty)
+				So(w.Header().Get("Vary"), ShouldEqual, "Accept")
+			})
+		})
+
+		Convey("When invalid feedback is sent asking for a fragment", func() {
+			w := post("description=&type="+url.QueryEscape(mapper.WholeSite), "text/html-fragment")
+
+			Convey("Then only the form is rendered with its errors", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockFragments.BuildPageCalls(), ShouldHaveLength, 1)
+				So(mockFragments.BuildPageCalls()[0].TemplateName, ShouldEqual, "partials/feedback-form")
+				p := mockFragments.BuildPageCalls()[0].PageModel.(model.Feedback)
+				So(p.DescriptionField.ValidationErr.HasValidationErr, ShouldBeTrue)
+				So(p.Page.Error.ErrorItems, ShouldHaveLength, 1)
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When valid feedback is sent asking for a fragment", func() {
+			w := post(validBody, "text/html-fragment")
+
+			Convey("Then the thank you message is rendered instead of redirecting", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+				So(mockFragments.BuildPageCalls(), ShouldHaveLength, 1)
+				So(mockFragments.BuildPageCalls()[0].TemplateName, ShouldEqual, "partials/feedback-thanks")
+				p := mockFragments.BuildPageCalls()[0].PageModel.(model.Feedback)
+				So(p.ReturnTo, ShouldEqual, "https://www.ons.gov.uk/economy")
+			})
+		})
+
+		Convey("When valid feedback is sent asking for a fragment but cannot be sent", func() {
+			postErr = &sdkError.StatusError{Err: errors.New("feedback API unavailable"), Code: http.StatusBadGateway}
+			w := post(validBody, "text/html-fragment")
+
+			Convey("Then a 502 is returned so the page can post the form instead", func() {
+				So(w.Code, ShouldEqual, http.StatusBadGateway)
+				So(mockFragments.BuildPageCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When valid feedback is sent without asking for a fragment", func() {
+			w := post(validBody, "text/html")
+
+			Convey("Then the user is redirected to the thanks page as before", func() {
+				So(w.Code, ShouldEqual, http.StatusMovedPermanently)
+				So(w.Header().Get("Location"), ShouldEqual, "/feedback/thanks?returnTo=https://www.ons.gov.uk/economy")
+				So(mockFragments.BuildPageCalls(), ShouldBeEmpty)
+			})
+		})
+	})
+}
+
 func Test_feedbackThanks(t *testing.T) {
 	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
 	config.Get() // need to seed config
@@ -242,7 +658,7 @@ func Test_feedbackThanks(t *testing.T) {
 				},
 			}}
 		Convey("When feedbackThanks is called", func() {
-			feedbackThanks(w, req, url, mockRenderer, mockNagivationCache, lang, siteDomain, false)
+			feedbackThanks(w, req, url, mockRenderer, mockNagivationCache, lang, siteDomain, false, nil)
 			Convey("Then the renderer is called", func() {
 				So(len(mockRenderer.BuildPageCalls()), ShouldEqual, 1)
 			})
@@ -276,7 +692,7 @@ func Test_feedbackThanks(t *testing.T) {
 				},
 			}}
 		Convey("When feedbackThanks is called", func() {
-			feedbackThanks(w, req, url, mockRenderer, mockNagivationCache, lang, siteDomain, false)
+			feedbackThanks(w, req, url, mockRenderer, mockNagivationCache, lang, siteDomain, false, nil)
 			Convey("Then the handler sanitises the request text to the referrer", func() {
 				dataSentToRender := mockRenderer.BuildPageCalls()[0].PageModel.(model.Feedback)
 				returnToURL := dataSentToRender.ReturnTo
@@ -449,6 +865,7 @@ func TestValidateForm(t *testing.T) {
 					Type:        mapper.WholeSite,
 					Description: "A description",
 					Email:       "a.string",
+					Consent:     true,
 				},
 				expectedDescription: "an email validation error is returned",
 				expected: []coreModel.ErrorItem{
@@ -467,6 +884,7 @@ func TestValidateForm(t *testing.T) {
 					Type:        mapper.WholeSite,
 					Description: "A description",
 					Email:       "hello(world)@email.com",
+					Consent:     true,
 				},
 				expectedDescription: "an email validation error is returned",
 				expected: []coreModel.ErrorItem{
@@ -485,6 +903,7 @@ func TestValidateForm(t *testing.T) {
 					Type:        mapper.WholeSite,
 					Description: "A description",
 					Email:       "hello@email.c0m",
+					Consent:     true,
 				},
 				expectedDescription: "an email validation error is returned",
 				expected: []coreModel.ErrorItem{
@@ -503,6 +922,7 @@ func TestValidateForm(t *testing.T) {
 					Type:        mapper.WholeSite,
 					Description: "A description",
 					Email:       "hello@world.com",
+					Consent:     true,
 				},
 				expectedDescription: "no validation errors are returned",
 				expected:            []coreModel.ErrorItem(nil),
@@ -513,6 +933,7 @@ func TestValidateForm(t *testing.T) {
 					Type:        mapper.WholeSite,
 					Description: "A description",
 					Email:       "hello.hello'world@email.com",
+					Consent:     true,
 				},
 				expectedDescription: "no validation errors are returned",
 				expected:            []coreModel.ErrorItem(nil),
@@ -523,6 +944,7 @@ func TestValidateForm(t *testing.T) {
 					Type:        mapper.WholeSite,
 					Description: "A description",
 					Email:       "hello.`!#$%&'*+-/=?^_{|}world@email.com",
+					Consent:     true,
 				},
 				expectedDescription: "no validation errors are returned",
 				expected:            []coreModel.ErrorItem(nil),
@@ -558,8 +980,43 @@ func TestValidateForm(t *testing.T) {
 						},
 						URL: "#email-error",
 					},
+					{
+						Description: coreModel.Localisation{
+							LocaleKey: "FeedbackAlertConsent",
+							Plural:    1,
+						},
+						URL: "#consent-error",
+					},
+				},
+			},
+			{
+				givenDescription: "an email address is given without consent",
+				given: &model.FeedbackForm{
+					Type:        mapper.WholeSite,
+					Description: "A description",
+					Email:       "hello@world.com",
+				},
+				expectedDescription: "a consent validation error is returned",
+				expected: []coreModel.ErrorItem{
+					{
+						Description: coreModel.Localisation{
+							LocaleKey: "FeedbackAlertConsent",
+							Plural:    1,
+						},
+						URL: "#consent-error",
+					},
 				},
 			},
+			{
+				givenDescription: "a name is given without an email address or consent",
+				given: &model.FeedbackForm{
+					Type:        mapper.WholeSite,
+					Description: "A description",
+					Name:        "Jo",
+				},
+				expectedDescription: "no consent is needed",
+				expected:            []coreModel.ErrorItem(nil),
+			},
 		}
 		for _, t := range testCases {
 			Convey(fmt.Sprintf("When %s", t.givenDescription), func() {
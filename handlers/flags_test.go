@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	coreModel "github.com/ONSdigital/dis-design-system-go/model"
+	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/flags"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/interfaces/interfacestest"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mocks"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+
+	Convey("Given the feedback handlers with feature flags", t, func() {
+		mockRenderer := &interfacestest.RendererMock{
+			BuildPageFunc: func(w io.Writer, pageModel interface{}, templateName string) {},
+			NewBasePageModelFunc: func() coreModel.Page {
+				return coreModel.Page{}
+			},
+		}
+		cfg := &config.Config{SiteDomain: siteDomain}
+		f := NewFeedback(Dependencies{
+			Render:       mockRenderer,
+			CacheService: &cacheHelper.Helper{},
+			Config:       cfg,
+			FeedbackAPI:  &FeedbackAPIClientMock{},
+			Flags:        flags.New([]flags.Flag{{Name: "new-form", Percentage: 100}, {Name: "welsh-rating", Percentage: 100, Languages: []string{"cy"}}}, true, false),
+		})
+		lastPage := func() model.Feedback {
+			calls := mockRenderer.BuildPageCalls()
+			return calls[len(calls)-1].PageModel.(model.Feedback)
+		}
+
+		Convey("When the feedback page is opened", func() {
+			f.GetFeedback()(httptest.NewRecorder(), httptest.NewRequest("GET", "/feedback", http.NoBody))
+
+			Convey("Then the flags turned on are given to the template", func() {
+				p := lastPage()
+				So(p.Features.On("new-form"), ShouldBeTrue)
+				So(p.Features.On("welsh-rating"), ShouldBeFalse)
+				So(p.Features.On("unknown"), ShouldBeFalse)
+			})
+		})
+
+		Convey("When a tester turns a flag off", func() {
+			req := httptest.NewRequest("GET", "/feedback", http.NoBody)
+			req.Header.Set(flags.HeaderName, "!new-form")
+			f.GetFeedback()(httptest.NewRecorder(), req)
+
+			Convey("Then it is off for the template", func() {
+				So(lastPage().Features.On("new-form"), ShouldBeFalse)
+			})
+		})
+
+		Convey("When the thanks page is opened", func() {
+			f.FeedbackThanks()(httptest.NewRecorder(), httptest.NewRequest("GET", "/feedback/thanks", http.NoBody))
+
+			Convey("Then the flags are given to its template too", func() {
+				So(lastPage().Features.On("new-form"), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the flags are reloaded", func() {
+			f.Reload(Reloadable{Config: cfg, Flags: flags.New([]flags.Flag{{Name: "new-form", Percentage: 0}}, false, false)})
+			f.GetFeedback()(httptest.NewRecorder(), httptest.NewRequest("GET", "/feedback", http.NoBody))
+
+			Convey("Then the new flags are used from the next request", func() {
+				So(lastPage().Features.On("new-form"), ShouldBeFalse)
+			})
+		})
+	})
+
+	Convey("Given the feedback handlers without feature flags", t, func() {
+		mockRenderer := &interfacestest.RendererMock{
+			BuildPageFunc: func(w io.Writer, pageModel interface{}, templateName string) {},
+			NewBasePageModelFunc: func() coreModel.Page {
+				return coreModel.Page{}
+			},
+		}
+		f := NewFeedback(Dependencies{
+			Render:       mockRenderer,
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{SiteDomain: siteDomain},
+			FeedbackAPI:  &FeedbackAPIClientMock{},
+		})
+
+		Convey("When the feedback page is opened", func() {
+			f.GetFeedback()(httptest.NewRecorder(), httptest.NewRequest("GET", "/feedback", http.NoBody))
+
+			Convey("Then no flags are turned on", func() {
+				So(mockRenderer.BuildPageCalls()[0].PageModel.(model.Feedback).Features.On("new-form"), ShouldBeFalse)
+			})
+		})
+	})
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/gdpr"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// erasureResult reports how much feedback had its contact details erased
+type erasureResult struct {
+	Mode   string `json:"mode"`
+	Erased int    `json:"erased"`
+}
+
+// SubjectAccess downloads all the stored feedback left with the email address in the email query parameter, for a
+// subject access request
+func (f *Feedback) SubjectAccess() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		email, err := gdpr.ParseEmail(req.URL.Query().Get("email"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		now := time.Now().UTC()
+		filename := fmt.Sprintf("subject-access-%s.json", now.Format("20060102T150405Z"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+		n, err := gdpr.WriteSubjectAccess(w, f.Store, f.DeadLetters, email, now)
+		if err != nil {
+			log.Error(ctx, "failed to write subject access data", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		log.Info(ctx, "subject access data downloaded", log.Data{"author": moderator(ctx), "items": n})
+	}
+}
+
+// EraseContactDetails erases or pseudonymises the contact details of all the stored feedback left with the email
+// address in the email form value, keeping the feedback itself. The mode form value is erase (the default) or
+// pseudonymise.
+func (f *Feedback) EraseContactDetails() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		if err := req.ParseForm(); err != nil {
+			log.Error(ctx, "unable to parse request form", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		email, err := gdpr.ParseEmail(req.PostForm.Get("email"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mode := req.PostForm.Get("mode")
+		if mode == "" {
+			mode = gdpr.ModeErase
+		}
+		if !gdpr.IsValidMode(mode) {
+			http.Error(w, fmt.Sprintf("unknown erasure mode %q", mode), http.StatusBadRequest)
+			return
+		}
+
+		n, err := gdpr.Erase(ctx, f.Store, f.DeadLetters, email, mode, moderator(ctx))
+		if err != nil {
+			log.Error(ctx, "failed to erase contact details", err, log.Data{"erased": n})
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(erasureResult{Mode: mode, Erased: n}); err != nil {
+			log.Error(ctx, "failed to write erasure result", err)
+		}
+	}
+}
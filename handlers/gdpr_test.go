@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/deadletter"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/gdpr"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/interfaces/interfacestest"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGDPR(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a store of feedback and the GDPR handlers", t, func() {
+		st, err := store.New(filepath.Join(t.TempDir(), "feedback"))
+		So(err, ShouldBeNil)
+		jo, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "census", Name: "Jo", EmailAddress: "jo@example.com"}}, "en")
+		sam, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "economy", Name: "Sam", EmailAddress: "sam@example.com"}}, "en")
+		deadLetters := deadletter.New(filepath.Join(t.TempDir(), "dead-letter.jsonl"))
+		failed, _ := deadLetters.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "census again", Name: "Jo", EmailAddress: "jo@example.com"}}, errors.New("failed"), 500)
+		f := NewFeedback(Dependencies{
+			Render:       &interfacestest.RendererMock{},
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{},
+			FeedbackAPI:  &FeedbackAPIClientMock{},
+			DeadLetters:  deadLetters,
+			Store:        st,
+		})
+
+		erase := func(body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/feedback/gdpr/erase", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req = req.WithContext(dprequest.SetUser(req.Context(), "dpo@ons.gov.uk"))
+			w := httptest.NewRecorder()
+			f.EraseContactDetails()(w, req)
+			return w
+		}
+
+		Convey("When the subject access data for an email address is requested", func() {
+			w := httptest.NewRecorder()
+			f.SubjectAccess()(w, httptest.NewRequest("GET", "/feedback/gdpr?email=jo@example.com", http.NoBody))
+
+			Convey("Then their feedback is downloaded as JSON", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Disposition"), ShouldStartWith, `attachment; filename="subject-access-`)
+				var sar gdpr.SubjectAccess
+				So(json.Unmarshal(w.Body.Bytes(), &sar), ShouldBeNil)
+				So(sar.Items, ShouldHaveLength, 1)
+				So(sar.Items[0].ID, ShouldEqual, jo.ID)
+				So(sar.DeadLetters, ShouldHaveLength, 1)
+				So(sar.DeadLetters[0].ID, ShouldEqual, failed.ID)
+			})
+		})
+
+		Convey("When the subject access data is requested without a valid email address", func() {
+			Convey("Then a 400 is returned", func() {
+				for _, query := range []string{"", "?email=jo"} {
+					w := httptest.NewRecorder()
+					f.SubjectAccess()(w, httptest.NewRequest("GET", "/feedback/gdpr"+query, http.NoBody))
+					So(w.Code, ShouldEqual, http.StatusBadRequest)
+				}
+			})
+		})
+
+		Convey("When contact details are pseudonymised", func() {
+			w := erase("email=jo%40example.com&mode=pseudonymise")
+
+			Convey("Then only that person's contact details are removed, recording who removed them", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.String(), ShouldEqual, `{"mode":"pseudonymise","erased":2}`+"\n")
+
+				item, _ := st.Get(jo.ID)
+				So(item.Submission.EmailAddress, ShouldBeEmpty)
+				So(item.Submission.Feedback.Feedback, ShouldEqual, "census")
+				So(item.Erasure.Author, ShouldEqual, "dpo@ons.gov.uk")
+
+				e, _ := deadLetters.Get(failed.ID)
+				So(e.Feedback.EmailAddress, ShouldBeEmpty)
+
+				item, _ = st.Get(sam.ID)
+				So(item.Submission.EmailAddress, ShouldEqual, "sam@example.com")
+			})
+		})
+
+		Convey("When contact details are erased with an unknown mode", func() {
+			w := erase("email=jo%40example.com&mode=delete")
+
+			Convey("Then a 400 is returned and nothing is changed", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				item, _ := st.Get(jo.ID)
+				So(item.Submission.EmailAddress, ShouldEqual, "jo@example.com")
+			})
+		})
+	})
+}
@@ -2,27 +2,120 @@ package handlers
 
 import (
 	"net/http"
+	"sync/atomic"
 
 	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/deadletter"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/draft"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/duplicate"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/flags"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/idempotency"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/interfaces"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mailer"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/reply"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/routing"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/search"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/sentiment"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/tagging"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/taxonomy"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/variant"
 	"github.com/ONSdigital/log.go/v2/log"
 )
 
+// Reloadable are the settings and rules used by the handlers that can be replaced while the service runs
+type Reloadable struct {
+	Config *config.Config
+	Tagger *tagging.Tagger
+	Router *routing.Router
+	Flags  *flags.Flags
+}
+
 // Feedback represents the handlers required to provide feedback
 type Feedback struct {
+	Render       interfaces.Renderer
+	CacheService *cacheHelper.Helper
+	FeedbackAPI  FeedbackAPIClient
+	DeadLetters  *deadletter.Store
+	Idempotency  idempotency.Store
+	Duplicates   *duplicate.Detector
+	Taxonomy     *taxonomy.Classifier
+	Sentiment    sentiment.Scorer
+	Store        *store.Store
+	Search       *search.Index
+	Mailer       mailer.Mailer
+	Replies      *reply.Templates
+	Fragments    interfaces.Renderer
+	Drafts       *draft.Drafts
+	Wizard       *draft.Drafts
+	Variants     *variant.Assigner
+
+	reloadable atomic.Pointer[Reloadable]
+}
+
+// Dependencies are everything the feedback handlers use, any of which can be left nil when the feature using it is off
+type Dependencies struct {
 	Render       interfaces.Renderer
 	CacheService *cacheHelper.Helper
 	Config       *config.Config
+	FeedbackAPI  FeedbackAPIClient
+	DeadLetters  *deadletter.Store
+	Idempotency  idempotency.Store
+	Duplicates   *duplicate.Detector
+	Tagger       *tagging.Tagger
+	Taxonomy     *taxonomy.Classifier
+	Router       *routing.Router
+	Sentiment    sentiment.Scorer
+	Store        *store.Store
+	Search       *search.Index
+	Mailer       mailer.Mailer
+	Replies      *reply.Templates
+	Fragments    interfaces.Renderer
+	Drafts       *draft.Drafts
+	Wizard       *draft.Drafts
+	Flags        *flags.Flags
+	Variants     *variant.Assigner
 }
 
-// NewFeedback creates a new instance of Feedback
-func NewFeedback(rc interfaces.Renderer, c *cacheHelper.Helper, cfg *config.Config) *Feedback {
-	return &Feedback{
-		Render:       rc,
-		CacheService: c,
-		Config:       cfg,
+// NewFeedback creates a new instance of Feedback from its dependencies
+func NewFeedback(d Dependencies) *Feedback {
+	f := &Feedback{
+		Render:       d.Render,
+		CacheService: d.CacheService,
+		FeedbackAPI:  d.FeedbackAPI,
+		DeadLetters:  d.DeadLetters,
+		Idempotency:  d.Idempotency,
+		Duplicates:   d.Duplicates,
+		Taxonomy:     d.Taxonomy,
+		Sentiment:    d.Sentiment,
+		Store:        d.Store,
+		Search:       d.Search,
+		Mailer:       d.Mailer,
+		Replies:      d.Replies,
+		Fragments:    d.Fragments,
+		Drafts:       d.Drafts,
+		Wizard:       d.Wizard,
+		Variants:     d.Variants,
 	}
+	f.Reload(Reloadable{Config: d.Config, Tagger: d.Tagger, Router: d.Router, Flags: d.Flags})
+	return f
+}
+
+// Reload replaces the settings and rules used by the handlers, taking effect from the next request
+func (f *Feedback) Reload(r Reloadable) {
+	f.reloadable.Store(&r)
+}
+
+// current returns the settings and rules in use
+func (f *Feedback) current() *Reloadable {
+	return f.reloadable.Load()
+}
+
+// features returns the feature flags turned on for req from a page in lang
+func (f *Feedback) features(w http.ResponseWriter, req *http.Request, lang string) model.Features {
+	return f.current().Flags.Evaluate(w, req, lang)
 }
 
 // ClientError is an interface that can be used to retrieve the status code if a client has errored
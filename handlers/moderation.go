@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mapper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	dphandlers "github.com/ONSdigital/dp-net/v3/handlers"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+// moderationPageSize is the number of items shown in the moderation inbox
+const moderationPageSize = 50
+
+// ModerationInbox lists the most recent stored feedback, optionally filtered by the status query parameter
+func (f *Feedback) ModerationInbox() http.HandlerFunc {
+	return dphandlers.ControllerHandler(func(w http.ResponseWriter, req *http.Request, lang, _, _ string) {
+		ctx := req.Context()
+
+		status := req.URL.Query().Get("status")
+		if status != "" && !store.IsValidStatus(status) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		items, err := f.Store.Recent(store.Filter{Status: status}, moderationPageSize)
+		if err != nil {
+			log.Error(ctx, "failed to list stored feedback", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		p := mapper.CreateModerationInbox(req, f.Render.NewBasePageModel(), lang, items, status)
+		f.Render.BuildPage(w, p, "moderation")
+	})
+}
+
+// ModerationItem shows a single item of stored feedback with the form used to moderate it
+func (f *Feedback) ModerationItem() http.HandlerFunc {
+	return dphandlers.ControllerHandler(func(w http.ResponseWriter, req *http.Request, lang, _, _ string) {
+		item, err := f.Store.Get(mux.Vars(req)["id"])
+		if err != nil {
+			writeStoreError(w, req, err)
+			return
+		}
+
+		p := mapper.CreateModerationDetail(req, f.Render.NewBasePageModel(), lang, item)
+		// replies can only be sent when a mailer is configured
+		p.Item.CanReply = p.Item.CanReply && f.Mailer != nil && f.Replies != nil
+		f.Render.BuildPage(w, p, "moderation-item")
+	})
+}
+
+// UpdateModerationItem sets the status of stored feedback and adds any note given, attributed to the signed in user
+func (f *Feedback) UpdateModerationItem() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		if err := req.ParseForm(); err != nil {
+			log.Error(ctx, "unable to parse request form", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		status := req.PostForm.Get("status")
+		if !store.IsValidStatus(status) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		note := strings.TrimSpace(req.PostForm.Get("note"))
+
+		author := moderator(ctx)
+
+		id := mux.Vars(req)["id"]
+		now := time.Now().UTC()
+		_, err := f.Store.Update(id, func(item *store.Item) error {
+			item.SetStatus(status, author, now)
+			if note != "" {
+				item.Notes = append(item.Notes, store.Note{Author: author, Text: note, CreatedAt: now})
+			}
+			return nil
+		})
+		if err != nil {
+			writeStoreError(w, req, err)
+			return
+		}
+
+		log.Info(ctx, "feedback moderated", log.Data{"id": id, "status": status, "author": author})
+		http.Redirect(w, req, mapper.ModerationPath+"/"+id, http.StatusSeeOther)
+	}
+}
+
+// moderator identifies the signed in user, or the calling service when there is no user
+func moderator(ctx context.Context) string {
+	if user := dprequest.User(ctx); user != "" {
+		return user
+	}
+	return dprequest.Caller(ctx)
+}
+
+func writeStoreError(w http.ResponseWriter, req *http.Request, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	log.Error(req.Context(), "failed to access stored feedback", err)
+	w.WriteHeader(http.StatusInternalServerError)
+}
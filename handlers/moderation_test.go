@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	coreModel "github.com/ONSdigital/dis-design-system-go/model"
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
+	sdkError "github.com/ONSdigital/dp-feedback-api/sdk/errors"
+	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/interfaces/interfacestest"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mocks"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestModeration(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+	ctx := context.Background()
+
+	Convey("Given a store of feedback and the moderation handlers", t, func() {
+		st, err := store.New(filepath.Join(t.TempDir(), "feedback"))
+		So(err, ShouldBeNil)
+		first, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "first"}}, "en")
+		second, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "second"}}, "en")
+		_, err = st.Update(second.ID, func(i *store.Item) error {
+			i.Status = store.StatusSpam
+			return nil
+		})
+		So(err, ShouldBeNil)
+
+		mockRenderer := &interfacestest.RendererMock{
+			BuildPageFunc:        func(w io.Writer, pageModel interface{}, templateName string) {},
+			NewBasePageModelFunc: func() coreModel.Page { return coreModel.Page{} },
+		}
+		f := NewFeedback(Dependencies{
+			Render:       mockRenderer,
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{},
+			FeedbackAPI:  &FeedbackAPIClientMock{},
+			Store:        st,
+		})
+
+		r := mux.NewRouter()
+		r.Path("/feedback/moderation").Methods("GET").HandlerFunc(f.ModerationInbox())
+		r.Path("/feedback/moderation/{id}").Methods("GET").HandlerFunc(f.ModerationItem())
+		r.Path("/feedback/moderation/{id}").Methods("POST").HandlerFunc(f.UpdateModerationItem())
+
+		Convey("When the inbox is filtered by status", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/feedback/moderation?status=new", http.NoBody))
+
+			Convey("Then only feedback with that status is listed", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockRenderer.BuildPageCalls(), ShouldHaveLength, 1)
+				So(mockRenderer.BuildPageCalls()[0].TemplateName, ShouldEqual, "moderation")
+				p := mockRenderer.BuildPageCalls()[0].PageModel.(model.Moderation)
+				So(p.Items, ShouldHaveLength, 1)
+				So(p.Items[0].ID, ShouldEqual, first.ID)
+			})
+		})
+
+		Convey("When the inbox is filtered by an unknown status", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/feedback/moderation?status=deleted", http.NoBody))
+
+			Convey("Then a 400 is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(mockRenderer.BuildPageCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a stored item is requested", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/feedback/moderation/"+first.ID, http.NoBody))
+
+			Convey("Then it is shown", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockRenderer.BuildPageCalls()[0].TemplateName, ShouldEqual, "moderation-item")
+				p := mockRenderer.BuildPageCalls()[0].PageModel.(model.ModerationDetail)
+				So(p.Item.Description, ShouldEqual, "first")
+			})
+		})
+
+		Convey("When an unknown item is requested", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/feedback/moderation/0000000000000000000000ff", http.NoBody))
+
+			Convey("Then a 404 is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("When an item is moderated by a signed in user", func() {
+			req := httptest.NewRequest("POST", "/feedback/moderation/"+first.ID, strings.NewReader("status=needs-reply&note=+passed+to+the+CPI+team+"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req = req.WithContext(dprequest.SetUser(req.Context(), "moderator@ons.gov.uk"))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			Convey("Then the status and note are saved and the item is shown again", func() {
+				So(w.Code, ShouldEqual, http.StatusSeeOther)
+				So(w.Header().Get("Location"), ShouldEqual, "/feedback/moderation/"+first.ID)
+
+				item, err := st.Get(first.ID)
+				So(err, ShouldBeNil)
+				So(item.Status, ShouldEqual, store.StatusNeedsReply)
+				So(item.Notes, ShouldHaveLength, 1)
+				So(item.Notes[0].Author, ShouldEqual, "moderator@ons.gov.uk")
+				So(item.Notes[0].Text, ShouldEqual, "passed to the CPI team")
+				So(item.StatusChanges, ShouldHaveLength, 1)
+				So(item.StatusChanges[0].From, ShouldEqual, store.StatusNew)
+				So(item.StatusChanges[0].To, ShouldEqual, store.StatusNeedsReply)
+				So(item.StatusChanges[0].Author, ShouldEqual, "moderator@ons.gov.uk")
+			})
+		})
+
+		Convey("When an item is given an unknown status", func() {
+			req := httptest.NewRequest("POST", "/feedback/moderation/"+first.ID, strings.NewReader("status=deleted"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			Convey("Then a 400 is returned and the item is unchanged", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				item, _ := st.Get(first.ID)
+				So(item.Status, ShouldEqual, store.StatusNew)
+			})
+		})
+	})
+
+	Convey("Given a store of feedback", t, func() {
+		st, err := store.New(filepath.Join(t.TempDir(), "feedback"))
+		So(err, ShouldBeNil)
+		mockFeedbackAPI := &FeedbackAPIClientMock{
+			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				return nil
+			},
+		}
+		f := NewFeedback(Dependencies{
+			Render:       &interfacestest.RendererMock{},
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{SiteDomain: siteDomain},
+			FeedbackAPI:  mockFeedbackAPI,
+			Store:        st,
+		})
+
+		Convey("When feedback is sent", func() {
+			req := httptest.NewRequest("POST", "http://localhost/feedback", strings.NewReader("description=testing1234&type=test"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			f.addFeedback(httptest.NewRecorder(), req, "cy")
+
+			Convey("Then it is kept for moderation", func() {
+				items, err := st.Recent(store.Filter{}, 10)
+				So(err, ShouldBeNil)
+				So(items, ShouldHaveLength, 1)
+				So(items[0].Lang, ShouldEqual, "cy")
+				So(items[0].Status, ShouldEqual, store.StatusNew)
+				So(items[0].Submission.Feedback.Feedback, ShouldEqual, "testing1234")
+			})
+		})
+	})
+}
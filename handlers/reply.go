@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	core "github.com/ONSdigital/dis-design-system-go/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mailer"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mapper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/reply"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	dphandlers "github.com/ONSdigital/dp-net/v3/handlers"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+const replyDateFormat = "2 January 2006"
+
+// ReplyForm shows the form for replying to the person who left feedback, starting from the template named in the
+// template query parameter or the first template
+func (f *Feedback) ReplyForm() http.HandlerFunc {
+	return dphandlers.ControllerHandler(func(w http.ResponseWriter, req *http.Request, lang, _, _ string) {
+		item, ok := f.replyableItem(w, req)
+		if !ok {
+			return
+		}
+
+		templates := f.Replies.List()
+		selected := req.URL.Query().Get("template")
+		if selected == "" && len(templates) > 0 {
+			selected = templates[0].Name
+		}
+
+		var subject, body string
+		if selected != "" {
+			var err error
+			if subject, body, err = f.Replies.Render(selected, replyData(item)); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		p := mapper.CreateModerationReply(req, f.Render.NewBasePageModel(), lang, item, templates, selected, subject, body, nil)
+		f.Render.BuildPage(w, p, "moderation-reply")
+	})
+}
+
+// SendReply emails a reply to the person who left feedback and records it against the feedback. Feedback that
+// was waiting for a reply is marked as actioned.
+func (f *Feedback) SendReply() http.HandlerFunc {
+	return dphandlers.ControllerHandler(func(w http.ResponseWriter, req *http.Request, lang, _, _ string) {
+		ctx := req.Context()
+
+		item, ok := f.replyableItem(w, req)
+		if !ok {
+			return
+		}
+
+		if err := req.ParseForm(); err != nil {
+			log.Error(ctx, "unable to parse request form", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		selected := req.PostForm.Get("template")
+		subject := strings.TrimSpace(req.PostForm.Get("subject"))
+		body := strings.TrimSpace(req.PostForm.Get("body"))
+
+		validationErrors := validateReply(subject, body)
+		if len(validationErrors) == 0 {
+			msg := mailer.Message{
+				From:    f.current().Config.FeedbackFrom,
+				To:      item.Submission.EmailAddress,
+				Subject: subject,
+				Body:    body,
+			}
+			if err := f.Mailer.Send(ctx, msg); err != nil {
+				log.Error(ctx, "failed to send reply to feedback", err, log.Data{"id": item.ID})
+				validationErrors = append(validationErrors, replyError("ReplySendFailed", "#reply-form"))
+			}
+		}
+		if len(validationErrors) > 0 {
+			p := mapper.CreateModerationReply(req, f.Render.NewBasePageModel(), lang, item, f.Replies.List(), selected, subject, body, validationErrors)
+			f.Render.BuildPage(w, p, "moderation-reply")
+			return
+		}
+
+		author := moderator(ctx)
+		now := time.Now().UTC()
+		_, err := f.Store.Update(item.ID, func(i *store.Item) error {
+			i.Replies = append(i.Replies, store.Reply{
+				Author:   author,
+				To:       item.Submission.EmailAddress,
+				Subject:  subject,
+				Body:     body,
+				Template: selected,
+				SentAt:   now,
+			})
+			if i.Status == store.StatusNew || i.Status == store.StatusNeedsReply {
+				i.SetStatus(store.StatusActioned, author, now)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Error(ctx, "reply was sent but could not be recorded against the feedback", err, log.Data{"id": item.ID})
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		log.Info(ctx, "reply sent to feedback", log.Data{"id": item.ID, "author": author, "template": selected})
+		http.Redirect(w, req, mapper.ModerationPath+"/"+item.ID+"/history", http.StatusSeeOther)
+	})
+}
+
+// ModerationHistory shows everything that has happened to an item of stored feedback
+func (f *Feedback) ModerationHistory() http.HandlerFunc {
+	return dphandlers.ControllerHandler(func(w http.ResponseWriter, req *http.Request, lang, _, _ string) {
+		item, err := f.Store.Get(mux.Vars(req)["id"])
+		if err != nil {
+			writeStoreError(w, req, err)
+			return
+		}
+
+		p := mapper.CreateModerationHistory(req, f.Render.NewBasePageModel(), lang, item)
+		f.Render.BuildPage(w, p, "moderation-history")
+	})
+}
+
+// replyableItem returns the requested feedback if it left an email address to reply to, otherwise writing an error
+func (f *Feedback) replyableItem(w http.ResponseWriter, req *http.Request) (*store.Item, bool) {
+	item, err := f.Store.Get(mux.Vars(req)["id"])
+	if err != nil {
+		writeStoreError(w, req, err)
+		return nil, false
+	}
+	if item.Submission == nil || item.Submission.EmailAddress == "" {
+		http.Error(w, "feedback has no email address to reply to", http.StatusConflict)
+		return nil, false
+	}
+	return item, true
+}
+
+func replyData(item *store.Item) reply.Data {
+	return reply.Data{
+		Name:       item.Submission.Name,
+		Email:      item.Submission.EmailAddress,
+		URL:        item.Submission.OnsURL,
+		Feedback:   item.Submission.Feedback.Feedback,
+		ReceivedAt: item.ReceivedAt.Format(replyDateFormat),
+	}
+}
+
+func validateReply(subject, body string) (validationErrors []core.ErrorItem) {
+	if subject == "" || strings.ContainsAny(subject, "\r\n") {
+		validationErrors = append(validationErrors, replyError("ReplyAlertSubject", "#subject-error"))
+	}
+	if body == "" {
+		validationErrors = append(validationErrors, replyError("ReplyAlertBody", "#body-error"))
+	}
+	return validationErrors
+}
+
+func replyError(localeKey, url string) core.ErrorItem {
+	return core.ErrorItem{
+		Description: core.Localisation{
+			LocaleKey: localeKey,
+			Plural:    1,
+		},
+		URL: url,
+	}
+}
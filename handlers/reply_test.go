@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	coreModel "github.com/ONSdigital/dis-design-system-go/model"
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/interfaces/interfacestest"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mailer"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mocks"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/reply"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReply(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+	ctx := context.Background()
+
+	Convey("Given stored feedback and the reply handlers", t, func() {
+		st, err := store.New(filepath.Join(t.TempDir(), "feedback"))
+		So(err, ShouldBeNil)
+		withEmail, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "the CPI table is wrong", Name: "Jo", EmailAddress: "jo@example.com"}}, "en")
+		anonymous, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "great"}}, "en")
+
+		replies, err := reply.New(reply.DefaultTemplates)
+		So(err, ShouldBeNil)
+		mockMailer := &mailer.MailerMock{
+			SendFunc: func(ctx context.Context, msg mailer.Message) error { return nil },
+		}
+		mockRenderer := &interfacestest.RendererMock{
+			BuildPageFunc:        func(w io.Writer, pageModel interface{}, templateName string) {},
+			NewBasePageModelFunc: func() coreModel.Page { return coreModel.Page{} },
+		}
+		cfg := &config.Config{FeedbackFrom: "feedback@ons.gov.uk"}
+		f := NewFeedback(Dependencies{
+			Render:       mockRenderer,
+			CacheService: &cacheHelper.Helper{},
+			Config:       cfg,
+			FeedbackAPI:  &FeedbackAPIClientMock{},
+			Store:        st,
+			Mailer:       mockMailer,
+			Replies:      replies,
+		})
+
+		r := mux.NewRouter()
+		r.Path("/feedback/moderation/{id}/reply").Methods("GET").HandlerFunc(f.ReplyForm())
+		r.Path("/feedback/moderation/{id}/reply").Methods("POST").HandlerFunc(f.SendReply())
+		r.Path("/feedback/moderation/{id}/history").Methods("GET").HandlerFunc(f.ModerationHistory())
+
+		post := func(id, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/feedback/moderation/"+id+"/reply", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req = req.WithContext(dprequest.SetUser(req.Context(), "moderator@ons.gov.uk"))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			return w
+		}
+
+		Convey("When the reply form is requested with a template", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/feedback/moderation/"+withEmail.ID+"/reply?template=more-information", http.NoBody))
+
+			Convey("Then the form is filled in from the template", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockRenderer.BuildPageCalls()[0].TemplateName, ShouldEqual, "moderation-reply")
+				p := mockRenderer.BuildPageCalls()[0].PageModel.(model.ModerationReply)
+				So(p.Template, ShouldEqual, "more-information")
+				So(p.BodyField.Input.Value, ShouldStartWith, "Dear Jo,")
+				So(p.BodyField.Input.Value, ShouldContainSubstring, "the CPI table is wrong")
+			})
+		})
+
+		Convey("When the reply form is requested with an unknown template", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/feedback/moderation/"+withEmail.ID+"/reply?template=unknown", http.NoBody))
+
+			Convey("Then a 400 is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+
+		Convey("When the reply form is requested for feedback without an email address", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/feedback/moderation/"+anonymous.ID+"/reply", http.NoBody))
+
+			Convey("Then a 409 is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusConflict)
+			})
+		})
+
+		Convey("When a reply is sent", func() {
+			w := post(withEmail.ID, "template=thanks&subject=Your+feedback&body=Thank+you.")
+
+			Convey("Then it is emailed to the person who left the feedback", func() {
+				So(mockMailer.SendCalls(), ShouldHaveLength, 1)
+				So(mockMailer.SendCalls()[0].Msg, ShouldResemble, mailer.Message{
+					From:    "feedback@ons.gov.uk",
+					To:      "jo@example.com",
+					Subject: "Your feedback",
+					Body:    "Thank you.",
+				})
+			})
+
+			Convey("Then it is recorded against the feedback, which is marked as actioned", func() {
+				So(w.Code, ShouldEqual, http.StatusSeeOther)
+				So(w.Header().Get("Location"), ShouldEqual, "/feedback/moderation/"+withEmail.ID+"/history")
+
+				item, err := st.Get(withEmail.ID)
+				So(err, ShouldBeNil)
+				So(item.Replies, ShouldHaveLength, 1)
+				So(item.Replies[0].Author, ShouldEqual, "moderator@ons.gov.uk")
+				So(item.Replies[0].Template, ShouldEqual, "thanks")
+				So(item.Status, ShouldEqual, store.StatusActioned)
+				So(item.StatusChanges, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When a reply is sent without a subject or body", func() {
+			w := post(withEmail.ID, "template=thanks&subject=+&body=")
+
+			Convey("Then nothing is sent and the form is shown with errors", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockMailer.SendCalls(), ShouldBeEmpty)
+				p := mockRenderer.BuildPageCalls()[0].PageModel.(model.ModerationReply)
+				So(p.Error.ErrorItems, ShouldHaveLength, 2)
+				So(p.SubjectField.ValidationErr.HasValidationErr, ShouldBeTrue)
+				So(p.BodyField.ValidationErr.HasValidationErr, ShouldBeTrue)
+			})
+		})
+
+		Convey("When the mail server fails to send a reply", func() {
+			mockMailer.SendFunc = func(ctx context.Context, msg mailer.Message) error {
+				return errors.New("connection refused")
+			}
+			w := post(withEmail.ID, "template=thanks&subject=Your+feedback&body=Thank+you.")
+
+			Convey("Then the form is shown with an error and nothing is recorded", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				p := mockRenderer.BuildPageCalls()[0].PageModel.(model.ModerationReply)
+				So(p.Error.ErrorItems, ShouldHaveLength, 1)
+				So(p.Error.ErrorItems[0].Description.LocaleKey, ShouldEqual, "ReplySendFailed")
+				So(p.BodyField.Input.Value, ShouldEqual, "Thank you.")
+
+				item, _ := st.Get(withEmail.ID)
+				So(item.Replies, ShouldBeEmpty)
+				So(item.Status, ShouldEqual, store.StatusNew)
+			})
+		})
+
+		Convey("When the history of feedback is requested", func() {
+			post(withEmail.ID, "template=thanks&subject=Your+feedback&body=Thank+you.")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/feedback/moderation/"+withEmail.ID+"/history", http.NoBody))
+
+			Convey("Then everything that happened to it is shown", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockRenderer.BuildPageCalls()[0].TemplateName, ShouldEqual, "moderation-history")
+				p := mockRenderer.BuildPageCalls()[0].PageModel.(model.ModerationHistory)
+				So(p.Events, ShouldHaveLength, 3)
+				So(p.Events[0].LocaleKey, ShouldEqual, "HistoryReceived")
+			})
+		})
+	})
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/routing"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// routingDryRun is the team that feedback would be routed to, along with the tags used to route it
+type routingDryRun struct {
+	routing.Result
+	Tags []string `json:"tags,omitempty"`
+}
+
+// RoutingDryRun reports which team feedback would be routed to without submitting anything. The url, service,
+// tag and description query parameters describe the feedback, any tags matching the description are added to
+// those given.
+func (f *Feedback) RoutingDryRun() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		query := req.URL.Query()
+
+		// the rules may have been removed since the service started
+		r := f.current()
+		if r.Router == nil {
+			http.NotFound(w, req)
+			return
+		}
+
+		tags := query["tag"]
+		if r.Tagger != nil && query.Get("description") != "" {
+			tags = append(tags, r.Tagger.Tag(query.Get("lang"), query.Get("description"), query.Get("url"))...)
+		}
+
+		result := routingDryRun{
+			Result: r.Router.Route(routing.Request{URL: query.Get("url"), Service: query.Get("service"), Tags: tags}),
+			Tags:   tags,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			log.Error(ctx, "failed to write routing dry run response", err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
+	sdkError "github.com/ONSdigital/dp-feedback-api/sdk/errors"
+	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/interfaces/interfacestest"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/routing"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/tagging"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRouting(t *testing.T) {
+	router, err := routing.New([]routing.Rule{
+		{Team: "developer-hub", Services: []string{"dev"}},
+		{Team: "census", PathPrefixes: []string{"/census"}},
+		{Team: "search", Tags: []string{"search"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagger, err := tagging.New(tagging.DefaultRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("Given feedback submitted from the developer service form", t, func() {
+		req := httptest.NewRequest("POST", "http://localhost/feedback?service=dev", strings.NewReader("description=testing1234&type=test"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		mockFeedbackAPI := &FeedbackAPIClientMock{
+			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				return nil
+			},
+		}
+		f := NewFeedback(Dependencies{
+			Render:       &interfacestest.RendererMock{},
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{SiteDomain: siteDomain},
+			FeedbackAPI:  mockFeedbackAPI,
+			Tagger:       tagger,
+			Router:       router,
+		})
+
+		Convey("When addFeedback is called", func() {
+			f.addFeedback(w, req, lang)
+
+			Convey("Then the owning team is sent with the feedback", func() {
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+				So(mockFeedbackAPI.PostSubmissionCalls()[0].S.Team, ShouldEqual, "developer-hub")
+			})
+		})
+	})
+
+	Convey("Given the routing dry run handler", t, func() {
+		f := NewFeedback(Dependencies{
+			Render:       &interfacestest.RendererMock{},
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{},
+			FeedbackAPI:  &FeedbackAPIClientMock{},
+			Tagger:       tagger,
+			Router:       router,
+		})
+
+		testCases := []struct {
+			description string
+			query       string
+			expected    routingDryRun
+		}{
+			{"a census URL", "url=https://www.ons.gov.uk/census/maps", routingDryRun{Result: routing.Result{Team: "census", Rule: 2}}},
+			{"a description matching a tag", "url=https://www.ons.gov.uk/economy&description=I+cannot+find+CPI", routingDryRun{Result: routing.Result{Team: "search", Rule: 3}, Tags: []string{"search"}}},
+			{"a given tag", "tag=search", routingDryRun{Result: routing.Result{Team: "search", Rule: 3}, Tags: []string{"search"}}},
+			{"feedback matching no rule", "url=https://www.ons.gov.uk/economy", routingDryRun{}},
+		}
+
+		for _, tc := range testCases {
+			Convey("When it is called for "+tc.description, func() {
+				w := httptest.NewRecorder()
+				f.RoutingDryRun()(w, httptest.NewRequest("GET", "http://localhost/feedback/routing?"+tc.query, http.NoBody))
+
+				Convey("Then the team the feedback would be routed to is returned", func() {
+					So(w.Code, ShouldEqual, http.StatusOK)
+					So(w.Header().Get("Content-Type"), ShouldEqual, "application/json")
+
+					var got routingDryRun
+					So(json.Unmarshal(w.Body.Bytes(), &got), ShouldBeNil)
+					So(got, ShouldResemble, tc.expected)
+				})
+			})
+		}
+	})
+}
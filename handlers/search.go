@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/export"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/search"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// Limits on the number of search results returned at once
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// searchResults is a page of feedback matching a search
+type searchResults struct {
+	Query string          `json:"q"`
+	Total int             `json:"total"`
+	Page  int             `json:"page"`
+	Limit int             `json:"limit"`
+	Items []export.Record `json:"items"`
+}
+
+// SearchFeedback finds stored feedback containing the words and "quoted phrases" in the q query parameter, newest
+// first. The results can be filtered with the same query parameters as an export, and by status, and are paged
+// with page and limit.
+func (f *Feedback) SearchFeedback() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		query := req.URL.Query()
+
+		q := search.ParseQuery(query.Get("q"))
+		if q.IsEmpty() {
+			http.Error(w, "a search query is required", http.StatusBadRequest)
+			return
+		}
+
+		page, err := parsePositiveInt(query.Get("page"), 1)
+		if err != nil {
+			http.Error(w, "invalid page: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		limit, err := parsePositiveInt(query.Get("limit"), defaultSearchLimit)
+		if err != nil || limit > maxSearchLimit {
+			http.Error(w, fmt.Sprintf("invalid limit, expected 1 to %d", maxSearchLimit), http.StatusBadRequest)
+			return
+		}
+
+		filter, err := export.Filter{
+			Since:     query.Get("since"),
+			Until:     query.Get("until"),
+			Type:      query.Get("type"),
+			URLPrefix: query.Get("url_prefix"),
+			Lang:      query.Get("lang"),
+			Tags:      query["tag"],
+		}.StoreFilter()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.Status = query.Get("status")
+		if filter.Status != "" && !store.IsValidStatus(filter.Status) {
+			http.Error(w, fmt.Sprintf("invalid status %q", filter.Status), http.StatusBadRequest)
+			return
+		}
+
+		results := searchResults{Query: query.Get("q"), Page: page, Limit: limit, Items: []export.Record{}}
+		// a page too far on to be numbered without overflowing is past the last result, so is empty
+		first := math.MaxInt
+		if page-1 <= math.MaxInt/limit {
+			first = (page - 1) * limit
+		}
+		for _, id := range f.Search.Search(q) {
+			item, err := f.Store.Get(id)
+			if errors.Is(err, store.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				log.Error(ctx, "failed to read stored feedback", err, log.Data{"id": id})
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if !filter.Matches(item) {
+				continue
+			}
+			if results.Total >= first && len(results.Items) < limit {
+				results.Items = append(results.Items, export.NewRecord(item))
+			}
+			results.Total++
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(results); err != nil {
+			log.Error(ctx, "failed to write search response", err)
+		}
+	}
+}
+
+// parsePositiveInt parses value as a number of at least 1, returning def when value is empty
+func parsePositiveInt(value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if n < 1 {
+		return 0, errors.New("must be at least 1")
+	}
+	return n, nil
+}
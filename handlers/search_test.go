@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
+	sdkError "github.com/ONSdigital/dp-feedback-api/sdk/errors"
+	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/interfaces/interfacestest"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/search"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSearchFeedback(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given an index of stored feedback and the search handler", t, func() {
+		st, err := store.New(filepath.Join(t.TempDir(), "feedback"))
+		So(err, ShouldBeNil)
+		var ids []string
+		for i := 0; i < 5; i++ {
+			item, err := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: fmt.Sprintf("CPI feedback %d", i)}}, "en")
+			So(err, ShouldBeNil)
+			ids = append(ids, item.ID)
+		}
+		_, err = st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "GDP feedback"}}, "en")
+		So(err, ShouldBeNil)
+		_, err = st.Update(ids[4], func(i *store.Item) error {
+			i.Status = store.StatusSpam
+			return nil
+		})
+		So(err, ShouldBeNil)
+
+		idx, err := search.Build(ctx, st)
+		So(err, ShouldBeNil)
+		f := NewFeedback(Dependencies{
+			Render:       &interfacestest.RendererMock{},
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{},
+			FeedbackAPI:  &FeedbackAPIClientMock{},
+			Store:        st,
+			Search:       idx,
+		})
+
+		get := func(query string) (*httptest.ResponseRecorder, searchResults) {
+			w := httptest.NewRecorder()
+			f.SearchFeedback()(w, httptest.NewRequest("GET", "/feedback/search?"+query, http.NoBody))
+			var results searchResults
+			if w.Code == http.StatusOK {
+				So(json.Unmarshal(w.Body.Bytes(), &results), ShouldBeNil)
+			}
+			return w, results
+		}
+
+		Convey("When a page of results is requested", func() {
+			w, results := get("q=cpi&limit=2&page=2")
+
+			Convey("Then the total and that page of matching feedback are returned, newest first", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "application/json")
+				So(results.Total, ShouldEqual, 5)
+				So(results.Items, ShouldHaveLength, 2)
+				So(results.Items[0].ID, ShouldEqual, ids[2])
+				So(results.Items[1].ID, ShouldEqual, ids[1])
+			})
+		})
+
+		Convey("When the results are filtered by status", func() {
+			_, results := get("q=cpi&status=new")
+
+			Convey("Then only feedback with that status is returned", func() {
+				So(results.Total, ShouldEqual, 4)
+				So(results.Items[0].ID, ShouldEqual, ids[3])
+			})
+		})
+
+		Convey("When a page beyond the results is requested", func() {
+			_, results := get("q=cpi&page=9")
+
+			Convey("Then no items are returned", func() {
+				So(results.Total, ShouldEqual, 5)
+				So(results.Items, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a page so far on that its first result cannot be counted to is requested", func() {
+			_, results := get("q=cpi&limit=10&page=1844674407370955162")
+
+			Convey("Then no items are returned", func() {
+				So(results.Total, ShouldEqual, 5)
+				So(results.Items, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When feedback is sent after the index was built", func() {
+			f.FeedbackAPI = &FeedbackAPIClientMock{
+				PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+					return nil
+				},
+			}
+			f.current().Config.SiteDomain = siteDomain
+			req := httptest.NewRequest("POST", "http://localhost/feedback", strings.NewReader("description=RPI+feedback&type=test"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			f.addFeedback(httptest.NewRecorder(), req, lang)
+
+			Convey("Then it can be found", func() {
+				_, results := get("q=rpi")
+				So(results.Total, ShouldEqual, 1)
+				So(results.Items[0].Feedback, ShouldEqual, "RPI feedback")
+			})
+		})
+
+		Convey("When the search is invalid", func() {
+			Convey("Then a 400 is returned", func() {
+				for _, query := range []string{"", "q=%22%22", "q=cpi&page=0", "q=cpi&limit=1000", "q=cpi&status=deleted", "q=cpi&type=survey"} {
+					w, _ := get(query)
+					So(w.Code, ShouldEqual, http.StatusBadRequest)
+				}
+			})
+		})
+	})
+}
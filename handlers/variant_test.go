@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	coreModel "github.com/ONSdigital/dis-design-system-go/model"
+	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
+	sdkError "github.com/ONSdigital/dp-feedback-api/sdk/errors"
+	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/interfaces/interfacestest"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mocks"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/variant"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFormVariants(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+
+	Convey("Given the feedback handlers testing only the short form", t, func() {
+		mockRenderer := &interfacestest.RendererMock{
+			BuildPageFunc: func(w io.Writer, pageModel interface{}, templateName string) {},
+			NewBasePageModelFunc: func() coreModel.Page {
+				return coreModel.Page{}
+			},
+		}
+		mockFeedbackAPI := &FeedbackAPIClientMock{
+			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				return nil
+			},
+		}
+		f := NewFeedback(Dependencies{
+			Render:       mockRenderer,
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{SiteDomain: siteDomain},
+			FeedbackAPI:  mockFeedbackAPI,
+			Variants:     variant.New([]variant.Variant{{Name: variant.Short, Weight: 1}}, false),
+		})
+		post := func(form url.Values) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/feedback", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			f.AddFeedback()(w, req)
+			return w
+		}
+
+		Convey("When a new user opens the feedback page", func() {
+			w := httptest.NewRecorder()
+			f.GetFeedback()(w, httptest.NewRequest("GET", "/feedback", http.NoBody))
+
+			Convey("Then they are shown the short form", func() {
+				p := mockRenderer.BuildPageCalls()[0].PageModel.(model.Feedback)
+				So(p.Variant, ShouldEqual, variant.Short)
+				So(p.Contact, ShouldBeEmpty)
+			})
+
+			Convey("And they are given the variant in a cookie", func() {
+				cookies := w.Result().Cookies()
+				So(cookies, ShouldHaveLength, 1)
+				So(cookies[0].Name, ShouldEqual, variant.CookieName)
+				So(cookies[0].Value, ShouldEqual, variant.Short)
+			})
+		})
+
+		Convey("When the short form is sent", func() {
+			w := post(url.Values{"type": {"The whole website"}, "description": {"too long"}, "variant": {variant.Short}})
+
+			Convey("Then the variant is recorded on the submission", func() {
+				So(w.Code, ShouldEqual, http.StatusMovedPermanently)
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+				So(mockFeedbackAPI.PostSubmissionCalls()[0].S.Variant, ShouldEqual, variant.Short)
+			})
+		})
+
+		Convey("When a form is sent naming a variant that is not being tested", func() {
+			post(url.Values{"type": {"The whole website"}, "description": {"too long"}, "variant": {variant.Control}})
+
+			Convey("Then no variant is recorded", func() {
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+				So(mockFeedbackAPI.PostSubmissionCalls()[0].S.Variant, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the short form is sent with an error", func() {
+			post(url.Values{"type": {"The whole website"}, "variant": {variant.Short}})
+
+			Convey("Then the short form is shown again", func() {
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldBeEmpty)
+				So(mockRenderer.BuildPageCalls()[0].PageModel.(model.Feedback).Variant, ShouldEqual, variant.Short)
+			})
+		})
+	})
+
+	Convey("Given the feedback handlers without a test", t, func() {
+		mockRenderer := &interfacestest.RendererMock{
+			BuildPageFunc: func(w io.Writer, pageModel interface{}, templateName string) {},
+			NewBasePageModelFunc: func() coreModel.Page {
+				return coreModel.Page{}
+			},
+		}
+		f := NewFeedback(Dependencies{
+			Render:       mockRenderer,
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{SiteDomain: siteDomain},
+			FeedbackAPI:  &FeedbackAPIClientMock{},
+		})
+
+		Convey("When the feedback page is opened", func() {
+			w := httptest.NewRecorder()
+			f.GetFeedback()(w, httptest.NewRequest("GET", "/feedback", http.NoBody))
+
+			Convey("Then the form is shown as it is, without a variant cookie", func() {
+				p := mockRenderer.BuildPageCalls()[0].PageModel.(model.Feedback)
+				So(p.Variant, ShouldBeEmpty)
+				So(p.Contact, ShouldHaveLength, 2)
+				So(w.Result().Cookies(), ShouldBeEmpty)
+			})
+		})
+	})
+}
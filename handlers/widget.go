@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/idempotency"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mapper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/widget"
+	dphandlers "github.com/ONSdigital/dp-net/v3/handlers"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// maxWidgetBody is the largest feedback that can be sent as JSON
+const maxWidgetBody = 64 * 1024
+
+// widgetResponse is the result of sending feedback as JSON
+type widgetResponse struct {
+	Status string        `json:"status,omitempty"`
+	Errors []widgetError `json:"errors,omitempty"`
+}
+
+// widgetError is a problem with feedback sent as JSON. ID is the id of the field's error in the widget form.
+type widgetError struct {
+	ID      string `json:"id,omitempty"`
+	Message string `json:"message"`
+}
+
+// Widget renders the footer feedback form on its own, for embedding in the page given by the url query parameter
+func (f *Feedback) Widget() http.HandlerFunc {
+	return f.cors(dphandlers.ControllerHandler(func(w http.ResponseWriter, req *http.Request, lang, _, _ string) {
+		var ff model.FeedbackForm
+		if pageURL := req.URL.Query().Get("url"); mapper.IsSiteDomainURL(pageURL, f.current().Config.SiteDomain) {
+			ff.URL = pageURL
+		}
+
+		p := mapper.CreateWidget(req, f.Fragments.NewBasePageModel(), ff, widgetLang(req, lang))
+		f.Fragments.BuildPage(w, p, "widget")
+	}))
+}
+
+// WidgetScript serves the script that embeds the widget in other pages
+func (f *Feedback) WidgetScript() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if _, err := w.Write(widget.Loader); err != nil {
+			log.Error(req.Context(), "failed to write widget script", err)
+		}
+	}
+}
+
+// SubmitFeedback accepts feedback from the widget as JSON, responding with any validation errors as JSON
+func (f *Feedback) SubmitFeedback() http.HandlerFunc {
+	return f.cors(dphandlers.ControllerHandler(func(w http.ResponseWriter, req *http.Request, lang, _, _ string) {
+		f.submitFeedback(w, req, widgetLang(req, lang))
+	}))
+}
+
+func (f *Feedback) submitFeedback(w http.ResponseWriter, req *http.Request, lang string) {
+	ctx := req.Context()
+
+	// requiring JSON means browsers ask before sending feedback from other sites, which only the allowed origins pass
+	if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType != "application/json" {
+		writeWidgetResponse(ctx, w, http.StatusUnsupportedMediaType, widgetResponse{Errors: []widgetError{{Message: "feedback must be sent as application/json"}}})
+		return
+	}
+
+	var ff model.FeedbackForm
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxWidgetBody)).Decode(&ff); err != nil {
+		log.Error(ctx, "unable to decode widget feedback", err)
+		writeWidgetResponse(ctx, w, http.StatusBadRequest, widgetResponse{Errors: []widgetError{{Message: "invalid feedback"}}})
+		return
+	}
+	ff.FormLocation = "footer"
+
+	if validationErrors := validateForm(&ff, f.current().Config.SiteDomain); len(validationErrors) > 0 {
+		var resp widgetResponse
+		for _, e := range validationErrors {
+			resp.Errors = append(resp.Errors, widgetError{
+				ID:      strings.TrimPrefix(e.URL, "#"),
+				Message: helper.Localise(e.Description.LocaleKey, lang, e.Description.Plural),
+			})
+		}
+		writeWidgetResponse(ctx, w, http.StatusBadRequest, resp)
+		return
+	}
+
+	received := widgetResponse{Status: "received"}
+
+	trackKey := f.Idempotency != nil && idempotency.IsValidKey(ff.IdempotencyKey)
+	if trackKey {
+		if _, isNew := f.Idempotency.Add(ff.IdempotencyKey, thanksURL(ff.URL)); !isNew {
+			log.Info(ctx, "suppressing repeated feedback submission", log.Data{"idempotency_key": ff.IdempotencyKey})
+			writeWidgetResponse(ctx, w, http.StatusAccepted, received)
+			return
+		}
+	}
+
+	// feedback kept for replay counts as received, as it is on the feedback page
+	if kept, err := f.send(ctx, &ff, lang, req.URL.Query().Get("service")); err != nil && !kept {
+		if trackKey {
+			f.Idempotency.Remove(ff.IdempotencyKey)
+		}
+		writeWidgetResponse(ctx, w, http.StatusBadGateway, widgetResponse{Errors: []widgetError{{Message: helper.Localise("FeedbackWidgetSendFailed", lang, 1)}}})
+		return
+	}
+
+	writeWidgetResponse(ctx, w, http.StatusAccepted, received)
+}
+
+func writeWidgetResponse(ctx context.Context, w http.ResponseWriter, status int, resp widgetResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Error(ctx, "failed to write widget response", err)
+	}
+}
+
+// cors lets pages on the allowed origins call h from the browser, answering their preflight requests
+func (f *Feedback) cors(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Vary", "Origin")
+
+		origin := req.Header.Get("Origin")
+		cfg := f.current().Config
+		isAllowed := origin != "" && widget.IsAllowedOrigin(origin, cfg.WidgetAllowedOrigins, cfg.SiteDomain)
+		if isAllowed {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if req.Method == http.MethodOptions {
+			if isAllowed {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+				w.Header().Set("Access-Control-Max-Age", "600")
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		h(w, req)
+	}
+}
+
+// widgetLang is the language asked for in the lang query parameter, as the lang cookie is not sent by other sites
+func widgetLang(req *http.Request, lang string) string {
+	if l := req.URL.Query().Get("lang"); l == dprequest.LangEN || l == dprequest.LangCY {
+		return l
+	}
+	return lang
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	coreModel "github.com/ONSdigital/dis-design-system-go/model"
+	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
+	sdkError "github.com/ONSdigital/dp-feedback-api/sdk/errors"
+	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/idempotency"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/interfaces/interfacestest"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mocks"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/widget"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWidget(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+
+	Convey("Given the widget handlers", t, func() {
+		mockFragments := &interfacestest.RendererMock{
+			BuildPageFunc: func(w io.Writer, pageModel interface{}, templateName string) {},
+			NewBasePageModelFunc: func() coreModel.Page {
+				return coreModel.Page{}
+			},
+		}
+		var postErr *sdkError.StatusError
+		mockFeedbackAPI := &FeedbackAPIClientMock{
+			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				return postErr
+			},
+		}
+		cfg := &config.Config{SiteDomain: siteDomain, WidgetAllowedOrigins: []string{"http://localhost:20000"}}
+		f := NewFeedback(Dependencies{
+			Render:       &interfacestest.RendererMock{},
+			CacheService: &cacheHelper.Helper{},
+			Config:       cfg,
+			FeedbackAPI:  mockFeedbackAPI,
+			Idempotency:  idempotency.NewMemoryStore(10, time.Minute),
+			Fragments:    mockFragments,
+		})
+
+		submit := func(body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/feedback/api", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Origin", "https://www.ons.gov.uk")
+			w := httptest.NewRecorder()
+			f.SubmitFeedback()(w, req)
+			return w
+		}
+
+		Convey("When the widget is requested from a page on the site", func() {
+			req := httptest.NewRequest("GET", "/feedback/widget?lang=cy&url=https://www.ons.gov.uk/economy", http.NoBody)
+			req.Header.Set("Origin", "https://www.ons.gov.uk")
+			w := httptest.NewRecorder()
+			f.Widget()(w, req)
+
+			Convey("Then the form is rendered for that page without the layout", func() {
+				So(mockFragments.BuildPageCalls(), ShouldHaveLength, 1)
+				So(mockFragments.BuildPageCalls()[0].TemplateName, ShouldEqual, "widget")
+				p := mockFragments.BuildPageCalls()[0].PageModel.(model.Feedback)
+				So(p.PreviousURL, ShouldEqual, "https://www.ons.gov.uk/economy")
+				So(p.Language, ShouldEqual, "cy")
+			})
+
+			Convey("Then the page is allowed to read it", func() {
+				So(w.Header().Get("Access-Control-Allow-Origin"), ShouldEqual, "https://www.ons.gov.uk")
+				So(w.Header().Get("Vary"), ShouldEqual, "Origin")
+			})
+		})
+
+		Convey("When the widget is requested for a page that is not on the site", func() {
+			req := httptest.NewRequest("GET", "/feedback/widget?url=https://example.com", http.NoBody)
+			req.Header.Set("Origin", "https://example.com")
+			w := httptest.NewRecorder()
+			f.Widget()(w, req)
+
+			Convey("Then the form is for the whole website and other sites cannot read it", func() {
+				p := mockFragments.BuildPageCalls()[0].PageModel.(model.Feedback)
+				So(p.PreviousURL, ShouldBeEmpty)
+				So(w.Header().Get("Access-Control-Allow-Origin"), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When an allowed origin checks it can send feedback", func() {
+			req := httptest.NewRequest("OPTIONS", "/feedback/api", http.NoBody)
+			req.Header.Set("Origin", "http://localhost:20000")
+			req.Header.Set("Access-Control-Request-Method", "POST")
+			w := httptest.NewRecorder()
+			f.SubmitFeedback()(w, req)
+
+			Convey("Then it is allowed to send JSON", func() {
+				So(w.Code, ShouldEqual, http.StatusNoContent)
+				So(w.Header().Get("Access-Control-Allow-Origin"), ShouldEqual, "http://localhost:20000")
+				So(w.Header().Get("Access-Control-Allow-Methods"), ShouldEqual, "GET, POST")
+				So(w.Header().Get("Access-Control-Allow-Headers"), ShouldEqual, "Content-Type")
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When an origin is allowed after the config is reloaded", func() {
+			f.Reload(Reloadable{Config: &config.Config{SiteDomain: siteDomain, WidgetAllowedOrigins: []string{"https://example.com"}}})
+			req := httptest.NewRequest("OPTIONS", "/feedback/api", http.NoBody)
+			req.Header.Set("Origin", "https://example.com")
+			w := httptest.NewRecorder()
+			f.SubmitFeedback()(w, req)
+
+			Convey("Then it is allowed from the next request", func() {
+				So(w.Header().Get("Access-Control-Allow-Origin"), ShouldEqual, "https://example.com")
+			})
+		})
+
+		Convey("When another origin checks it can send feedback", func() {
+			req := httptest.NewRequest("OPTIONS", "/feedback/api", http.NoBody)
+			req.Header.Set("Origin", "https://example.com")
+			w := httptest.NewRecorder()
+			f.SubmitFeedback()(w, req)
+
+			Convey("Then it is not allowed", func() {
+				So(w.Code, ShouldEqual, http.StatusNoContent)
+				So(w.Header().Get("Access-Control-Allow-Origin"), ShouldBeEmpty)
+				So(w.Header().Get("Access-Control-Allow-Methods"), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When valid feedback is sent as JSON", func() {
+			w := submit(`{"type":"A specific page","url":"https://www.ons.gov.uk/economy","description":"useful","email":"jo@example.com","consent":true}`)
+
+			Convey("Then it is sent to the feedback API", func() {
+				So(w.Code, ShouldEqual, http.StatusAccepted)
+				So(w.Body.String(), ShouldEqual, `{"status":"received"}`+"\n")
+				So(w.Header().Get("Access-Control-Allow-Origin"), ShouldEqual, "https://www.ons.gov.uk")
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+				s := mockFeedbackAPI.PostSubmissionCalls()[0].S
+				So(s.OnsURL, ShouldEqual, "https://www.ons.gov.uk/economy")
+				So(s.Feedback.Feedback, ShouldEqual, "useful")
+				So(s.Consent, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When the same feedback is sent twice", func() {
+			body := `{"description":"useful","idempotency_key":"abc123"}`
+			first, second := submit(body), submit(body)
+
+			Convey("Then it is only sent once", func() {
+				So(first.Code, ShouldEqual, http.StatusAccepted)
+				So(second.Code, ShouldEqual, http.StatusAccepted)
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When invalid feedback is sent as JSON", func() {
+			w := submit(`{"description":" ","email":"jo@example.com"}`)
+
+			Convey("Then the localised errors are returned with the fields they are for", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				var resp widgetResponse
+				So(json.Unmarshal(w.Body.Bytes(), &resp), ShouldBeNil)
+				So(resp.Errors, ShouldResemble, []widgetError{
+					{ID: "feedback-error", Message: "Write some feedback"},
+					{ID: "consent-error", Message: "Agree to us using your contact details, or delete your email address"},
+				})
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When feedback is not sent as JSON", func() {
+			req := httptest.NewRequest("POST", "/feedback/api", strings.NewReader("description=useful"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			f.SubmitFeedback()(w, req)
+
+			Convey("Then it is rejected", func() {
+				So(w.Code, ShouldEqual, http.StatusUnsupportedMediaType)
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the JSON cannot be decoded", func() {
+			w := submit(`{"description":`)
+
+			Convey("Then a 400 is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+
+		Convey("When the feedback API cannot be reached", func() {
+			postErr = &sdkError.StatusError{Err: errors.New("feedback API unavailable"), Code: http.StatusBadGateway}
+			w := submit(`{"description":"useful","idempotency_key":"abc123"}`)
+
+			Convey("Then the failure is reported so the feedback can be sent again", func() {
+				So(w.Code, ShouldEqual, http.StatusBadGateway)
+				So(w.Body.String(), ShouldContainSubstring, "Sorry, there was a problem sending your feedback")
+
+				postErr = nil
+				So(submit(`{"description":"useful","idempotency_key":"abc123"}`).Code, ShouldEqual, http.StatusAccepted)
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When the widget script is requested", func() {
+			w := httptest.NewRecorder()
+			f.WidgetScript()(w, httptest.NewRequest("GET", "/feedback/widget.js", http.NoBody))
+
+			Convey("Then the loader is served", func() {
+				So(w.Header().Get("Content-Type"), ShouldEqual, "text/javascript; charset=utf-8")
+				So(w.Body.Bytes(), ShouldResemble, widget.Loader)
+			})
+		})
+	})
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	core "github.com/ONSdigital/dis-design-system-go/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/draft"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mapper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// wizardStep is a screen of the feedback wizard, asking for some of the fields of the feedback form
+type wizardStep struct {
+	name string
+	// validate checks only the fields asked for by the step
+	validate func(ff *model.FeedbackForm, siteDomain string) []core.ErrorItem
+	// merge copies the fields asked for by the step from the posted form into the user's answers so far
+	merge func(ff *model.FeedbackForm, posted *model.FeedbackForm)
+}
+
+// wizardSteps are the screens of the feedback wizard, in the order they are shown
+var wizardSteps = []wizardStep{
+	{
+		name:     "type",
+		validate: validateType,
+		merge: func(ff, posted *model.FeedbackForm) {
+			ff.Type, ff.URL = posted.Type, posted.URL
+		},
+	},
+	{
+		name: "description",
+		validate: func(ff *model.FeedbackForm, _ string) []core.ErrorItem {
+			return validateDescription(ff)
+		},
+		merge: func(ff, posted *model.FeedbackForm) {
+			ff.Description = posted.Description
+		},
+	},
+	{
+		name: "rating",
+		validate: func(ff *model.FeedbackForm, _ string) []core.ErrorItem {
+			return validateRating(ff)
+		},
+		merge: func(ff, posted *model.FeedbackForm) {
+			ff.Rating = posted.Rating
+		},
+	},
+	{
+		name: "contact",
+		validate: func(ff *model.FeedbackForm, _ string) []core.ErrorItem {
+			return validateContact(ff)
+		},
+		merge: func(ff, posted *model.FeedbackForm) {
+			ff.Name, ff.Email, ff.Consent = posted.Name, posted.Email, posted.Consent
+		},
+	},
+}
+
+// wizardStepIndex returns the position of the named step, or the first step if there is no step with that name
+func wizardStepIndex(name string) int {
+	for i, step := range wizardSteps {
+		if step.name == name {
+			return i
+		}
+	}
+	return 0
+}
+
+// firstUnansweredStep returns the position of the first step whose answers are not valid, or the last step if they
+// all are
+func firstUnansweredStep(ff model.FeedbackForm, siteDomain string) int {
+	for i, step := range wizardSteps {
+		answers := ff
+		if len(step.validate(&answers, siteDomain)) > 0 {
+			return i
+		}
+	}
+	return len(wizardSteps) - 1
+}
+
+// wizardURL is the page showing the numbered step of the wizard
+func wizardURL(n int) string {
+	return "/feedback?step=" + url.QueryEscape(wizardSteps[n].name)
+}
+
+// getWizard shows the step of the feedback wizard asked for, unless an earlier step still needs answering
+func (f *Feedback) getWizard(w http.ResponseWriter, req *http.Request, lang string) {
+	ff, ok := f.Wizard.Load(req)
+	if !ok {
+		ff = model.FeedbackForm{URL: req.Referer()}
+	}
+
+	n := wizardStepIndex(req.URL.Query().Get("step"))
+	if first := firstUnansweredStep(ff, f.current().Config.SiteDomain); first < n {
+		n = first
+	}
+	f.renderWizard(w, req, []core.ErrorItem{}, ff, lang, n)
+}
+
+// addWizardStep keeps the answers posted from a step of the feedback wizard, moving on to the next step if they are
+// valid or back to the previous one if asked. The answers are sent as feedback from the last step.
+func (f *Feedback) addWizardStep(w http.ResponseWriter, req *http.Request, posted model.FeedbackForm, lang string) {
+	n := wizardStepIndex(req.Form.Get("wizard-step"))
+	step := wizardSteps[n]
+
+	ff, _ := f.Wizard.Load(req)
+	step.merge(&ff, &posted)
+	if ff.IdempotencyKey == "" {
+		ff.IdempotencyKey = posted.IdempotencyKey
+	}
+
+	if req.Form.Has("wizard-back") {
+		// answers are kept without being checked, so they are still there when the user comes back to the step
+		if validationErrors := f.saveWizard(w, req, ff); len(validationErrors) > 0 {
+			f.renderWizard(w, req, validationErrors, ff, lang, n)
+			return
+		}
+		http.Redirect(w, req, wizardURL(max(n-1, 0)), http.StatusSeeOther)
+		return
+	}
+
+	validationErrors := step.validate(&ff, f.current().Config.SiteDomain)
+	if len(validationErrors) > 0 {
+		validationErrors = append(validationErrors, f.saveWizard(w, req, withoutErrors(ff))...)
+		f.renderWizard(w, req, validationErrors, ff, lang, n)
+		return
+	}
+
+	// the earlier steps are checked again before sending, as the session may have expired or been changed in another
+	// tab
+	next := n + 1
+	if next == len(wizardSteps) {
+		next = firstUnansweredStep(ff, f.current().Config.SiteDomain)
+	}
+	if next != n {
+		if validationErrors := f.saveWizard(w, req, ff); len(validationErrors) > 0 {
+			f.renderWizard(w, req, validationErrors, ff, lang, n)
+			return
+		}
+		http.Redirect(w, req, wizardURL(next), http.StatusSeeOther)
+		return
+	}
+
+	f.submit(w, req, &ff, lang, f.Wizard.Discard)
+}
+
+// saveWizard keeps the user's answers in their wizard cookie, returning an error to show them if the answers could
+// not be kept, which is only expected when they are too long to fit in the cookie
+func (f *Feedback) saveWizard(w http.ResponseWriter, req *http.Request, ff model.FeedbackForm) []core.ErrorItem {
+	err := f.Wizard.Save(w, req, ff)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, draft.ErrTooLarge) {
+		log.Warn(req.Context(), "feedback wizard answers are too large to keep", log.Data{"description_length": len(ff.Description)})
+	} else {
+		log.Error(req.Context(), "unable to keep feedback wizard answers", err)
+	}
+	return []core.ErrorItem{
+		{
+			Description: core.Localisation{
+				LocaleKey: "FeedbackTooLong",
+				Plural:    1,
+			},
+			URL: "#description-field",
+		},
+	}
+}
+
+// renderWizard renders the numbered step of the feedback wizard
+func (f *Feedback) renderWizard(w http.ResponseWriter, req *http.Request, validationErrors []core.ErrorItem, ff model.FeedbackForm, lang string, n int) {
+	p := mapper.CreateFeedbackWizard(req, f.Render.NewBasePageModel(), validationErrors, ff, lang, wizardSteps[n].name, n+1, len(wizardSteps))
+	p.Features = f.features(w, req, lang)
+
+	if f.current().Config.EnableNewNavBar {
+		mappedNavContent, err := f.CacheService.GetMappedNavigationContent(req.Context(), lang)
+		if err == nil {
+			p.NavigationContent = mappedNavContent
+		}
+	}
+
+	f.Render.BuildPage(w, p, "feedback-wizard")
+}
+
+// withoutErrors returns ff without the flags marking which of its fields are not valid
+func withoutErrors(ff model.FeedbackForm) model.FeedbackForm {
+	ff.IsTypeErr, ff.IsURLErr, ff.IsDescriptionErr, ff.IsRatingErr = false, false, false, false
+	ff.IsEmailErr, ff.IsConsentErr = false, false
+	return ff
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	coreModel "github.com/ONSdigital/dis-design-system-go/model"
+	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
+	sdkError "github.com/ONSdigital/dp-feedback-api/sdk/errors"
+	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/draft"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/idempotency"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/interfaces/interfacestest"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mapper"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mocks"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWizard(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+
+	Convey("Given the feedback handlers in wizard mode", t, func() {
+		mockRenderer := &interfacestest.RendererMock{
+			BuildPageFunc: func(w io.Writer, pageModel interface{}, templateName string) {},
+			NewBasePageModelFunc: func() coreModel.Page {
+				return coreModel.Page{}
+			},
+		}
+		mockFeedbackAPI := &FeedbackAPIClientMock{
+			PostSubmissionFunc: func(ctx context.Context, s *submission.Submission, options feedbackAPI.Options) *sdkError.StatusError {
+				return nil
+			},
+		}
+		wizard, err := draft.New(draft.WizardCookieName, cookieSecret, time.Minute, false)
+		So(err, ShouldBeNil)
+		f := NewFeedback(Dependencies{
+			Render:       mockRenderer,
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{SiteDomain: siteDomain},
+			FeedbackAPI:  mockFeedbackAPI,
+			Idempotency:  idempotency.NewMemoryStore(10, time.Minute),
+			Wizard:       wizard,
+		})
+		// another instance of the service, given the same cookie secret
+		otherWizard, err := draft.New(draft.WizardCookieName, cookieSecret, time.Minute, false)
+		So(err, ShouldBeNil)
+		other := NewFeedback(Dependencies{
+			Render:       mockRenderer,
+			CacheService: &cacheHelper.Helper{},
+			Config:       &config.Config{SiteDomain: siteDomain},
+			FeedbackAPI:  mockFeedbackAPI,
+			Idempotency:  idempotency.NewMemoryStore(10, time.Minute),
+			Wizard:       otherWizard,
+		})
+
+		var cookies []*http.Cookie
+		postTo := func(instance *Feedback, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/feedback", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			for _, c := range cookies {
+				req.AddCookie(c)
+			}
+			w := httptest.NewRecorder()
+			instance.AddFeedback()(w, req)
+			if set := w.Result().Cookies(); len(set) > 0 {
+				cookies = set
+			}
+			return w
+		}
+		post := func(body string) *httptest.ResponseRecorder {
+			return postTo(f, body)
+		}
+		get := func(path string) model.FeedbackWizard {
+			req := httptest.NewRequest("GET", path, http.NoBody)
+			req.Header.Set("Referer", "https://www.ons.gov.uk/economy")
+			for _, c := range cookies {
+				req.AddCookie(c)
+			}
+			f.GetFeedback()(httptest.NewRecorder(), req)
+			calls := mockRenderer.BuildPageCalls()
+			So(calls[len(calls)-1].TemplateName, ShouldEqual, "feedback-wizard")
+			return calls[len(calls)-1].PageModel.(model.FeedbackWizard)
+		}
+		lastPage := func() model.FeedbackWizard {
+			calls := mockRenderer.BuildPageCalls()
+			return calls[len(calls)-1].PageModel.(model.FeedbackWizard)
+		}
+
+		Convey("When the feedback page is opened", func() {
+			p := get("/feedback")
+
+			Convey("Then the first step asks what the feedback is about", func() {
+				So(p.Step, ShouldEqual, "type")
+				So(p.Progress, ShouldEqual, "Step 1 of 4")
+				So(p.IsFirstStep, ShouldBeTrue)
+				So(p.PreviousURL, ShouldEqual, "https://www.ons.gov.uk/economy")
+			})
+		})
+
+		Convey("When a later step is asked for before the earlier ones are answered", func() {
+			p := get("/feedback?step=contact")
+
+			Convey("Then the first unanswered step is shown", func() {
+				So(p.Step, ShouldEqual, "type")
+			})
+		})
+
+		Convey("When a step is answered", func() {
+			w := post("wizard-step=type&type=" + url.QueryEscape(mapper.WholeSite))
+
+			Convey("Then the user is sent to the next step", func() {
+				So(w.Code, ShouldEqual, http.StatusSeeOther)
+				So(w.Header().Get("Location"), ShouldEqual, "/feedback?step=description")
+				So(cookies[0].Name, ShouldEqual, draft.WizardCookieName)
+				So(get("/feedback?step=description").Step, ShouldEqual, "description")
+			})
+
+			Convey("And the next step is answered with an error", func() {
+				w := post("wizard-step=description&description=+")
+
+				Convey("Then the step is shown again with only its own error", func() {
+					So(w.Code, ShouldEqual, http.StatusOK)
+					p := lastPage()
+					So(p.Step, ShouldEqual, "description")
+					So(p.Page.Error.ErrorItems, ShouldHaveLength, 1)
+					So(p.DescriptionField.ValidationErr.HasValidationErr, ShouldBeTrue)
+				})
+
+				Convey("Then the error is not kept with the answers", func() {
+					So(get("/feedback?step=description").DescriptionField.ValidationErr.HasValidationErr, ShouldBeFalse)
+				})
+			})
+
+			Convey("And the user goes back from the next step", func() {
+				w := post("wizard-step=description&description=half+written&wizard-back=true")
+
+				Convey("Then the previous step is shown with the answers kept", func() {
+					So(w.Code, ShouldEqual, http.StatusSeeOther)
+					So(w.Header().Get("Location"), ShouldEqual, "/feedback?step=type")
+					p := get("/feedback?step=type")
+					So(p.TypeRadios.Radios[0].Input.IsChecked, ShouldBeTrue)
+					So(p.DescriptionField.Input.Value, ShouldEqual, "half written")
+					So(mockFeedbackAPI.PostSubmissionCalls(), ShouldBeEmpty)
+				})
+			})
+		})
+
+		Convey("When every step is answered", func() {
+			post("wizard-step=type&type=" + url.QueryEscape(mapper.ASpecificPage) + "&url=" + url.QueryEscape("https://www.ons.gov.uk/economy"))
+			post("wizard-step=description&description=useful")
+			So(post("wizard-step=rating").Code, ShouldEqual, http.StatusOK)
+			So(lastPage().RatingRadios.ValidationErr.HasValidationErr, ShouldBeTrue)
+			post("wizard-step=rating&rating=4")
+			p := get("/feedback?step=contact")
+			So(p.IsLastStep, ShouldBeTrue)
+			w := post("wizard-step=contact&name=Jo&idempotency-key=" + p.IdempotencyKey)
+
+			Convey("Then the answers are sent as feedback", func() {
+				So(w.Code, ShouldEqual, http.StatusMovedPermanently)
+				So(w.Header().Get("Location"), ShouldEqual, "/feedback/thanks?returnTo=https://www.ons.gov.uk/economy")
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+				s := mockFeedbackAPI.PostSubmissionCalls()[0].S
+				So(s.OnsURL, ShouldEqual, "https://www.ons.gov.uk/economy")
+				So(s.Feedback.Feedback, ShouldEqual, "useful")
+				So(s.Name, ShouldEqual, "Jo")
+				So(*s.Rating, ShouldEqual, 4)
+			})
+
+			Convey("Then the answers are discarded", func() {
+				So(cookies[0].MaxAge, ShouldBeLessThan, 0)
+			})
+		})
+
+		Convey("When each step is handled by a different instance", func() {
+			postTo(f, "wizard-step=type&type="+url.QueryEscape(mapper.WholeSite))
+			postTo(other, "wizard-step=description&description=useful")
+			postTo(f, "wizard-step=rating&rating=5")
+			w := postTo(other, "wizard-step=contact&name=Jo")
+
+			Convey("Then the answers from every step are sent as feedback", func() {
+				So(w.Code, ShouldEqual, http.StatusMovedPermanently)
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+				s := mockFeedbackAPI.PostSubmissionCalls()[0].S
+				So(s.Feedback.Feedback, ShouldEqual, "useful")
+				So(*s.Rating, ShouldEqual, 5)
+				So(s.Name, ShouldEqual, "Jo")
+			})
+		})
+
+		Convey("When the feedback is too long to keep in the cookie", func() {
+			post("wizard-step=type&type=" + url.QueryEscape(mapper.WholeSite))
+			kept := cookies
+			// random letters, which do not compress well
+			r := rand.New(rand.NewSource(1))
+			description := make([]byte, 8000)
+			for i := range description {
+				description[i] = byte('a' + r.Intn(26))
+			}
+			w := post("wizard-step=description&description=" + string(description))
+
+			Convey("Then the step is shown again asking for it to be shortened", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				p := lastPage()
+				So(p.Step, ShouldEqual, "description")
+				So(p.Page.Error.ErrorItems, ShouldHaveLength, 1)
+				So(p.Page.Error.ErrorItems[0].Description.LocaleKey, ShouldEqual, "FeedbackTooLong")
+				So(p.DescriptionField.Input.Value, ShouldEqual, string(description))
+			})
+
+			Convey("Then the earlier answers are still kept", func() {
+				So(cookies, ShouldResemble, kept)
+				So(get("/feedback?step=description").Step, ShouldEqual, "description")
+			})
+		})
+
+		Convey("When the last step is posted without the earlier answers", func() {
+			w := post("wizard-step=contact&name=Jo")
+
+			Convey("Then the user is sent back to the first unanswered step", func() {
+				So(w.Code, ShouldEqual, http.StatusSeeOther)
+				So(w.Header().Get("Location"), ShouldEqual, "/feedback?step=type")
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When feedback is posted from the footer form", func() {
+			w := post("feedback-form-type=footer&description=useful")
+
+			Convey("Then it is sent in one go", func() {
+				So(w.Code, ShouldEqual, http.StatusMovedPermanently)
+				So(mockFeedbackAPI.PostSubmissionCalls(), ShouldHaveLength, 1)
+			})
+		})
+	})
+}
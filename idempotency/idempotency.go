@@ -0,0 +1,108 @@
+package idempotency
+
+import (
+	"container/list"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// MaxKeyLength is the longest idempotency key that will be tracked, longer keys are ignored
+const MaxKeyLength = 64
+
+// Store records the idempotency keys of accepted submissions along with the response originally given
+type Store interface {
+	// Add records key with its response, returning the original response and false if key was already recorded
+	Add(key, response string) (original string, isNew bool)
+	// Remove forgets key so that the submission may be retried
+	Remove(key string)
+}
+
+// NewKey returns a random idempotency key to be embedded in a form
+func NewKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// IsValidKey is true when key is suitable for tracking
+func IsValidKey(key string) bool {
+	return key != "" && len(key) <= MaxKeyLength
+}
+
+type entry struct {
+	key      string
+	response string
+	expires  time.Time
+}
+
+// MemoryStore is an in-memory least recently used Store whose keys expire after a time window
+type MemoryStore struct {
+	capacity int
+	window   time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// NewMemoryStore creates a MemoryStore holding at most capacity keys for the duration of window. It always holds at
+// least the latest key, as with no room for it Add would evict until there was nothing left to evict.
+func NewMemoryStore(capacity int, window time.Duration) *MemoryStore {
+	return &MemoryStore{
+		capacity: max(capacity, 1),
+		window:   window,
+		now:      time.Now,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Add implements Store
+func (s *MemoryStore) Add(key, response string) (original string, isNew bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if el, ok := s.entries[key]; ok {
+		e := el.Value.(*entry)
+		if now.Before(e.expires) {
+			s.order.MoveToFront(el)
+			return e.response, false
+		}
+		s.remove(el)
+	}
+
+	s.entries[key] = s.order.PushFront(&entry{key: key, response: response, expires: now.Add(s.window)})
+	for s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+	return response, true
+}
+
+// Remove implements Store
+func (s *MemoryStore) Remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.entries[key]; ok {
+		s.remove(el)
+	}
+}
+
+// Len returns the number of keys currently held, including any that have expired but not been evicted
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.order.Len()
+}
+
+func (s *MemoryStore) remove(el *list.Element) {
+	s.order.Remove(el)
+	delete(s.entries, el.Value.(*entry).key)
+}
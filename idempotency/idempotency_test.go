@@ -0,0 +1,100 @@
+package idempotency
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewKey(t *testing.T) {
+	Convey("Given two generated keys", t, func() {
+		first, second := NewKey(), NewKey()
+
+		Convey("Then they are valid and different", func() {
+			So(IsValidKey(first), ShouldBeTrue)
+			So(IsValidKey(second), ShouldBeTrue)
+			So(first, ShouldNotEqual, second)
+		})
+	})
+
+	Convey("Given keys that cannot be tracked", t, func() {
+		So(IsValidKey(""), ShouldBeFalse)
+		So(IsValidKey(strings.Repeat("a", MaxKeyLength+1)), ShouldBeFalse)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	Convey("Given a memory store with a one minute window", t, func() {
+		now := time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)
+		store := NewMemoryStore(2, time.Minute)
+		store.now = func() time.Time { return now }
+
+		Convey("When a key is added for the first time", func() {
+			response, isNew := store.Add("key-1", "/feedback/thanks?returnTo=a")
+
+			Convey("Then it is recorded as new", func() {
+				So(isNew, ShouldBeTrue)
+				So(response, ShouldEqual, "/feedback/thanks?returnTo=a")
+			})
+
+			Convey("And the same key is added again within the window", func() {
+				now = now.Add(30 * time.Second)
+				response, isNew := store.Add("key-1", "/feedback/thanks?returnTo=b")
+
+				Convey("Then the original response is returned", func() {
+					So(isNew, ShouldBeFalse)
+					So(response, ShouldEqual, "/feedback/thanks?returnTo=a")
+				})
+			})
+
+			Convey("And the same key is added again after the window", func() {
+				now = now.Add(2 * time.Minute)
+				response, isNew := store.Add("key-1", "/feedback/thanks?returnTo=b")
+
+				Convey("Then it is recorded as new", func() {
+					So(isNew, ShouldBeTrue)
+					So(response, ShouldEqual, "/feedback/thanks?returnTo=b")
+				})
+			})
+
+			Convey("And the key is removed", func() {
+				store.Remove("key-1")
+				_, isNew := store.Add("key-1", "/feedback/thanks?returnTo=b")
+
+				Convey("Then it can be added again", func() {
+					So(isNew, ShouldBeTrue)
+				})
+			})
+		})
+
+		Convey("When more keys are added than the store can hold", func() {
+			store.Add("key-1", "one")
+			store.Add("key-2", "two")
+			store.Add("key-1", "one")
+			store.Add("key-3", "three")
+
+			Convey("Then the least recently used key is evicted", func() {
+				So(store.Len(), ShouldEqual, 2)
+				_, isNew := store.Add("key-2", "two")
+				So(isNew, ShouldBeTrue)
+			})
+		})
+	})
+
+	Convey("Given a memory store created with no capacity", t, func() {
+		store := NewMemoryStore(-1, time.Minute)
+
+		Convey("When keys are added", func() {
+			store.Add("key-1", "one")
+			_, isNew := store.Add("key-1", "one")
+			store.Add("key-2", "two")
+
+			Convey("Then the latest key is still held", func() {
+				So(isNew, ShouldBeFalse)
+				So(store.Len(), ShouldEqual, 1)
+			})
+		})
+	})
+}
@@ -0,0 +1,111 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+//go:generate moq -out mailer_mock.go -pkg mailer . Mailer
+
+// Mailer sends email
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Message is a plain text email
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+}
+
+// Validate returns an error if the message cannot be sent safely
+func (m Message) Validate() error {
+	if _, err := mail.ParseAddress(m.From); err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+	if _, err := mail.ParseAddress(m.To); err != nil {
+		return fmt.Errorf("invalid to address: %w", err)
+	}
+	if strings.ContainsAny(m.Subject, "\r\n") {
+		return errors.New("subject must be a single line")
+	}
+	if strings.TrimSpace(m.Subject) == "" || strings.TrimSpace(m.Body) == "" {
+		return errors.New("subject and body are required")
+	}
+	return nil
+}
+
+// sendMailFunc matches smtp.SendMail
+type sendMailFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// SMTP sends email through a mail server
+type SMTP struct {
+	addr     string
+	auth     smtp.Auth
+	now      func() time.Time
+	sendMail sendMailFunc
+}
+
+// NewSMTP creates a Mailer for the mail server at host and port, authenticating when user is set
+func NewSMTP(host, port, user, password string) *SMTP {
+	m := &SMTP{
+		addr:     net.JoinHostPort(host, port),
+		now:      time.Now,
+		sendMail: smtp.SendMail,
+	}
+	if user != "" {
+		m.auth = smtp.PlainAuth("", user, password, host)
+	}
+	return m
+}
+
+// Send implements Mailer
+func (m *SMTP) Send(_ context.Context, msg Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	from, _ := mail.ParseAddress(msg.From)
+	to, _ := mail.ParseAddress(msg.To)
+
+	b, err := encode(msg, m.now())
+	if err != nil {
+		return err
+	}
+	if err := m.sendMail(m.addr, m.auth, from.Address, []string{to.Address}, b); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// encode formats the message as a MIME email with a quoted-printable UTF-8 body
+func encode(msg Message, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", msg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	body := strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n")
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return nil, fmt.Errorf("failed to encode email: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode email: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,80 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSMTP(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given an SMTP mailer", t, func() {
+		var sentAddr, sentFrom string
+		var sentTo []string
+		var sent []byte
+		m := NewSMTP("smtp.example.com", "587", "user", "secret")
+		m.now = func() time.Time { return time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC) }
+		m.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			sentAddr, sentFrom, sentTo, sent = addr, from, to, msg
+			return nil
+		}
+		msg := Message{
+			From:    "ONS Feedback <feedback@ons.gov.uk>",
+			To:      "jo@example.com",
+			Subject: "Eich adborth",
+			Body:    "Dear Jo,\nThank you.",
+		}
+
+		Convey("When a message is sent", func() {
+			err := m.Send(ctx, msg)
+
+			Convey("Then it is sent to the mail server as a plain text email", func() {
+				So(err, ShouldBeNil)
+				So(sentAddr, ShouldEqual, "smtp.example.com:587")
+				So(sentFrom, ShouldEqual, "feedback@ons.gov.uk")
+				So(sentTo, ShouldResemble, []string{"jo@example.com"})
+				So(string(sent), ShouldContainSubstring, "From: ONS Feedback <feedback@ons.gov.uk>\r\n")
+				So(string(sent), ShouldContainSubstring, "Subject: Eich adborth\r\n")
+				So(string(sent), ShouldContainSubstring, "Date: Thu, 14 Mar 2024 12:00:00 +0000\r\n")
+				So(string(sent), ShouldEndWith, "\r\n\r\nDear Jo,\r\nThank you.")
+			})
+		})
+
+		Convey("When a message has a subject that would add headers", func() {
+			msg.Subject = "Hello\r\nBcc: everyone@example.com"
+			err := m.Send(ctx, msg)
+
+			Convey("Then it is not sent", func() {
+				So(err, ShouldNotBeNil)
+				So(sent, ShouldBeNil)
+			})
+		})
+
+		Convey("When a message has an invalid address", func() {
+			msg.To = "not an address"
+			err := m.Send(ctx, msg)
+
+			Convey("Then it is not sent", func() {
+				So(err, ShouldNotBeNil)
+				So(sent, ShouldBeNil)
+			})
+		})
+
+		Convey("When the mail server rejects a message", func() {
+			m.sendMail = func(string, smtp.Auth, string, []string, []byte) error {
+				return errors.New("550 mailbox unavailable")
+			}
+			err := m.Send(ctx, msg)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "550 mailbox unavailable")
+			})
+		})
+	})
+}
@@ -3,10 +3,14 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/ONSdigital/dp-frontend-feedback-controller/assets"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/cli"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/service"
 	dpotelgo "github.com/ONSdigital/dp-otel-go"
@@ -17,14 +21,25 @@ func main() {
 	log.Namespace = "dp-frontend-feedback-controller"
 	ctx := context.Background()
 
-	if err := run(ctx); err != nil {
+	configPath := flag.String("config", os.Getenv(config.FileEnvVar), "TOML file of settings, overridden by environment variables")
+	flag.Parse()
+
+	if flag.NArg() > 0 {
+		if err := runCommand(ctx, *configPath, flag.Args()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if err := run(ctx, *configPath); err != nil {
 		log.Fatal(ctx, "application unexpectedly failed", err)
 	}
 
 	os.Exit(0)
 }
 
-func run(ctx context.Context) error {
+func run(ctx context.Context, configPath string) error {
 	// Create error channel for os signals
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
@@ -34,12 +49,17 @@ func run(ctx context.Context) error {
 	svcErrors := make(chan error, 1)
 
 	// Read config
-	cfg, err := config.Get()
+	cfg, err := config.Read(configPath)
 	if err != nil {
 		log.Error(ctx, "unable to retrieve service configuration", err)
 		return err
 	}
 
+	if err := cfg.Validate(assets.Asset); err != nil {
+		log.Error(ctx, "invalid service configuration", err)
+		return err
+	}
+
 	log.Info(ctx, "got service configuration", log.Data{"config": cfg})
 
 	if cfg.OtelEnabled {
@@ -80,3 +100,38 @@ func run(ctx context.Context) error {
 
 	return svc.Close(ctx)
 }
+
+// runCommand runs one of the maintenance subcommands instead of starting the service
+func runCommand(ctx context.Context, configPath string, args []string) error {
+	if args[0] == "config" {
+		return cli.NewConfigPrinter(configPath, os.Stdout).Run(args[1:])
+	}
+
+	cfg, err := config.Read(configPath)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve service configuration: %w", err)
+	}
+
+	switch args[0] {
+	case "replay":
+		replayer, err := cli.NewReplayer(cfg, os.Stdout)
+		if err != nil {
+			return err
+		}
+		return replayer.Run(ctx, args[1:])
+	case "export":
+		exporter, err := cli.NewExporter(cfg, os.Stdout)
+		if err != nil {
+			return err
+		}
+		return exporter.Run(ctx, args[1:])
+	case "gdpr":
+		cmd, err := cli.NewGDPR(cfg, os.Stdout)
+		if err != nil {
+			return err
+		}
+		return cmd.Run(ctx, args[1:])
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
@@ -0,0 +1,89 @@
+package mapper
+
+import (
+	"net/http"
+
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	core "github.com/ONSdigital/dis-design-system-go/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/dashboard"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+)
+
+const dashboardDateFormat = "2 Jan 2006"
+
+var typeLocaleKeys = map[string]string{
+	store.TypePage:       "DashboardTypePage",
+	store.TypeWholeSite:  "DashboardTypeWholeSite",
+	store.TypeNewService: "DashboardTypeNewService",
+}
+
+var languageLocaleKeys = map[string]string{
+	"en": "DashboardLanguageEnglish",
+	"cy": "DashboardLanguageWelsh",
+}
+
+// CreateDashboard maps a summary of stored feedback to the dashboard page
+func CreateDashboard(req *http.Request, basePage core.Page, lang string, summary *dashboard.Summary) model.Dashboard {
+	p := model.Dashboard{
+		Page:  basePage,
+		Weeks: len(summary.Weeks),
+		Total: summary.Total,
+		// Until is the start of the following week
+		Period: summary.Since.Format(dashboardDateFormat) + " – " + summary.Until.AddDate(0, 0, -1).Format(dashboardDateFormat),
+	}
+	p.Language = lang
+	p.Type = "feedback-dashboard"
+	p.URI = req.URL.Path
+	p.Metadata.Title = helper.Localise("DashboardTitle", lang, 1)
+
+	weeks := make([]dashboard.Count, 0, len(summary.Weeks))
+	for _, w := range summary.Weeks {
+		weeks = append(weeks, dashboard.Count{Label: w.Start.Format(dashboardDateFormat), Count: w.Count})
+	}
+	p.WeeklyCounts = mapBars(weeks, summary.Total, nil)
+	p.Types = mapBars(summary.Types, summary.Total, typeLocaleKeys)
+	p.Languages = mapBars(summary.Languages, summary.Total, languageLocaleKeys)
+	p.TopURLs = mapBars(summary.TopURLs, summary.Total, nil)
+
+	p.Sentiment = mapRatio(summary.Positive, summary.Scored)
+	p.Satisfaction = mapRatio(summary.Satisfied, summary.Rated)
+	return p
+}
+
+// mapBars converts counts to bars, using the locale key for each label where there is one
+func mapBars(counts []dashboard.Count, total int, localeKeys map[string]string) []model.DashboardBar {
+	largest := 0
+	for _, c := range counts {
+		largest = max(largest, c.Count)
+	}
+
+	bars := make([]model.DashboardBar, 0, len(counts))
+	for _, c := range counts {
+		bars = append(bars, model.DashboardBar{
+			Label:     c.Label,
+			LocaleKey: localeKeys[c.Label],
+			Count:     c.Count,
+			Percent:   percent(c.Count, total),
+			Width:     percent(c.Count, largest),
+		})
+	}
+	return bars
+}
+
+func mapRatio(count, total int) model.DashboardRatio {
+	return model.DashboardRatio{
+		Count:   count,
+		Total:   total,
+		Percent: percent(count, total),
+		HasData: total > 0,
+	}
+}
+
+// percent returns n as a whole percentage of total, or 0 if total is 0
+func percent(n, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return (200*n + total) / (2 * total)
+}
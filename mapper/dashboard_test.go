@@ -0,0 +1,91 @@
+package mapper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	core "github.com/ONSdigital/dis-design-system-go/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/dashboard"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mocks"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCreateDashboard(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+	Convey("Given a summary of stored feedback", t, func() {
+		req := httptest.NewRequest(http.MethodGet, "/feedback/dashboard", http.NoBody)
+		summary := &dashboard.Summary{
+			Since: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+			Until: time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC),
+			Total: 3,
+			Weeks: []dashboard.Week{
+				{Start: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), Count: 1},
+				{Start: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), Count: 2},
+			},
+			Types:     []dashboard.Count{{Label: store.TypePage, Count: 2}, {Label: store.TypeWholeSite, Count: 1}, {Label: store.TypeNewService, Count: 0}},
+			Languages: []dashboard.Count{{Label: "en", Count: 2}, {Label: "cy", Count: 1}},
+			Positive:  1,
+			Scored:    3,
+			Satisfied: 3,
+			Rated:     4,
+		}
+
+		Convey("When the dashboard is mapped", func() {
+			sut := CreateDashboard(req, core.Page{}, "en", summary)
+
+			Convey("Then it sets the page metadata and period", func() {
+				So(sut.Type, ShouldEqual, "feedback-dashboard")
+				So(sut.Metadata.Title, ShouldEqual, "Feedback dashboard")
+				So(sut.Period, ShouldEqual, "4 Mar 2024 – 17 Mar 2024")
+				So(sut.Weeks, ShouldEqual, 2)
+			})
+
+			Convey("Then each week is a bar sized against the busiest week", func() {
+				So(sut.WeeklyCounts, ShouldHaveLength, 2)
+				So(sut.WeeklyCounts[0].Label, ShouldEqual, "4 Mar 2024")
+				So(sut.WeeklyCounts[0].Width, ShouldEqual, 50)
+				So(sut.WeeklyCounts[1].Width, ShouldEqual, 100)
+			})
+
+			Convey("Then types and languages are shown as shares of the total with their labels", func() {
+				So(sut.Types[0].LocaleKey, ShouldEqual, "DashboardTypePage")
+				So(sut.Types[0].Percent, ShouldEqual, 67)
+				So(sut.Types[2].Percent, ShouldEqual, 0)
+				So(sut.Languages[1].LocaleKey, ShouldEqual, "DashboardLanguageWelsh")
+				So(sut.Languages[1].Percent, ShouldEqual, 33)
+			})
+
+			Convey("Then the share of feedback with a positive sentiment is shown", func() {
+				So(sut.Sentiment.HasData, ShouldBeTrue)
+				So(sut.Sentiment.Percent, ShouldEqual, 33)
+			})
+
+			Convey("Then the share of rated feedback that was satisfied is shown", func() {
+				So(sut.Satisfaction, ShouldResemble, model.DashboardRatio{Count: 3, Total: 4, Percent: 75, HasData: true})
+			})
+		})
+
+		Convey("When the dashboard is mapped for feedback without sentiment scores", func() {
+			summary.Positive, summary.Scored = 0, 0
+			sut := CreateDashboard(req, core.Page{}, "en", summary)
+
+			Convey("Then no sentiment figure is shown", func() {
+				So(sut.Sentiment.HasData, ShouldBeFalse)
+			})
+		})
+
+		Convey("When the dashboard is mapped for feedback without ratings", func() {
+			summary.Satisfied, summary.Rated = 0, 0
+			sut := CreateDashboard(req, core.Page{}, "en", summary)
+
+			Convey("Then no satisfaction figure is shown", func() {
+				So(sut.Satisfaction.HasData, ShouldBeFalse)
+			})
+		})
+	})
+}
@@ -4,23 +4,35 @@ import (
 	"html"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/ONSdigital/dis-design-system-go/helper"
 	core "github.com/ONSdigital/dis-design-system-go/model"
-	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/idempotency"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/variant"
 )
 
 const (
 	WholeSite     = "The whole website"
 	ASpecificPage = "A specific page"
+	NewService    = "The new service"
 )
 
-var cfg *config.Config
+// The ratings that can be given in the feedback wizard, from very poor to very good
+const (
+	MinRating = 1
+	MaxRating = 5
+)
 
-// CreateGetFeedback returns a mapped feedback page to the feedback model
-func CreateGetFeedback(req *http.Request, basePage core.Page, validationErrors []core.ErrorItem, ff model.FeedbackForm, lang string) model.Feedback {
+// ConsentVersion identifies the wording of the FeedbackConsent locale key that people agree to. Change it whenever
+// that wording changes.
+const ConsentVersion = "1"
+
+// CreateGetFeedback returns a mapped feedback page to the feedback model, with the fields and wording of formVariant
+// when a variant of the form is being tested
+func CreateGetFeedback(req *http.Request, basePage core.Page, validationErrors []core.ErrorItem, ff model.FeedbackForm, lang, formVariant string) model.Feedback {
 	p := model.Feedback{
 		Page: basePage,
 	}
@@ -128,7 +140,7 @@ func CreateGetFeedback(req *http.Request, basePage core.Page, validationErrors [
 						Text: helper.Localise("FeedbackWhatOptNewService", lang, 1, serviceDescription),
 					},
 					Name:  "type",
-					Value: "The new service",
+					Value: NewService,
 				},
 			},
 			p.TypeRadios.Radios[1])
@@ -172,6 +184,30 @@ func CreateGetFeedback(req *http.Request, basePage core.Page, validationErrors [
 		},
 	}
 
+	p.ConsentField = model.CheckboxField{
+		Input: core.Input{
+			ID:        "consent-field",
+			IsChecked: ff.Consent,
+			Label: core.Localisation{
+				LocaleKey: "FeedbackConsent",
+				Plural:    1,
+			},
+			Language: lang,
+			Name:     "consent",
+			Value:    "true",
+		},
+		ValidationErr: core.ValidationErr{
+			HasValidationErr: ff.IsConsentErr,
+			ErrorItem: core.ErrorItem{
+				Description: core.Localisation{
+					LocaleKey: "FeedbackAlertConsent",
+					Plural:    1,
+				},
+				ID: "consent-error",
+			},
+		},
+	}
+
 	p.DescriptionField = core.TextareaField{
 		Input: core.Input{
 			Autocomplete: "off",
@@ -202,10 +238,84 @@ func CreateGetFeedback(req *http.Request, basePage core.Page, validationErrors [
 
 	p.PreviousURL = ff.URL
 
+	// a re-rendered form keeps its key so a corrected submission is still recognised as the same one
+	p.IdempotencyKey = ff.IdempotencyKey
+	if !idempotency.IsValidKey(p.IdempotencyKey) {
+		p.IdempotencyKey = idempotency.NewKey()
+	}
+
+	// the short form only asks what the feedback is about and what could be better
+	p.Variant = formVariant
+	if formVariant == variant.Short {
+		p.Contact = nil
+		p.DescriptionField.Input.Label.LocaleKey = "FeedbackTitleEntryShort"
+		p.DescriptionField.Input.Description.LocaleKey = "FeedbackHintEntryShort"
+	}
+
 	return p
 }
 
-func CreateGetFeedbackThanks(req *http.Request, basePage core.Page, lang, referrer, wholeSiteURL string) model.Feedback {
+// CreateFeedbackWizard returns the page for the step of the feedback wizard numbered stepNumber out of stepCount
+func CreateFeedbackWizard(req *http.Request, basePage core.Page, validationErrors []core.ErrorItem, ff model.FeedbackForm, lang, step string, stepNumber, stepCount int) model.FeedbackWizard {
+	p := model.FeedbackWizard{
+		Feedback:    CreateGetFeedback(req, basePage, validationErrors, ff, lang, ""),
+		Step:        step,
+		Progress:    helper.Localise("FeedbackWizardProgress", lang, 1, strconv.Itoa(stepNumber), strconv.Itoa(stepCount)),
+		IsFirstStep: stepNumber == 1,
+		IsLastStep:  stepNumber == stepCount,
+	}
+
+	p.RatingRadios = core.RadioFieldset{
+		Legend: core.Localisation{
+			LocaleKey: "FeedbackTitleRating",
+			Plural:    1,
+		},
+		ValidationErr: core.ValidationErr{
+			HasValidationErr: ff.IsRatingErr,
+			ErrorItem: core.ErrorItem{
+				Description: core.Localisation{
+					LocaleKey: "FeedbackChooseRating",
+					Plural:    1,
+				},
+				ID: "rating-error",
+			},
+		},
+	}
+	for rating := MinRating; rating <= MaxRating; rating++ {
+		value := strconv.Itoa(rating)
+		p.RatingRadios.Radios = append(p.RatingRadios.Radios, core.Radio{
+			Input: core.Input{
+				ID:        "rating-" + value,
+				IsChecked: ff.Rating == rating,
+				Label: core.Localisation{
+					LocaleKey: "FeedbackRating" + value,
+					Plural:    1,
+				},
+				Name:  "rating",
+				Value: value,
+			},
+		})
+	}
+
+	return p
+}
+
+// CreateWidget returns the footer variant of the feedback form, which other pages embed. Rather than asking for
+// the type of feedback, it is about the page in ff.URL, or the whole website if that is empty.
+func CreateWidget(req *http.Request, basePage core.Page, ff model.FeedbackForm, lang string) model.Feedback {
+	ff.FormLocation = "footer"
+	ff.Type = WholeSite
+	if ff.URL != "" {
+		ff.Type = ASpecificPage
+	}
+	p := CreateGetFeedback(req, basePage, nil, ff, lang, "")
+	p.Type = "feedback-widget"
+	return p
+}
+
+// CreateGetFeedbackThanks returns the thank you page, linking back to the page given by the returnTo query if it is on
+// siteDomain
+func CreateGetFeedbackThanks(req *http.Request, basePage core.Page, lang, referrer, wholeSiteURL, siteDomain string) model.Feedback {
 	if wholeSiteURL == "" {
 		wholeSiteURL = "https://www.ons.gov.uk"
 	}
@@ -228,7 +338,7 @@ func CreateGetFeedbackThanks(req *http.Request, basePage core.Page, lang, referr
 		returnTo = wholeSiteURL
 	} else if returnTo == "" {
 		returnTo = referrer
-	} else if IsSiteDomainURL(returnTo, "") {
+	} else if IsSiteDomainURL(returnTo, siteDomain) {
 		returnTo = NormaliseURL(returnTo)
 	} else {
 		returnTo = referrer
@@ -239,9 +349,9 @@ func CreateGetFeedbackThanks(req *http.Request, basePage core.Page, lang, referr
 	return p
 }
 
-// IsSiteDomainURL is true when urlString is a URL and its host ends with `.`+siteDomain (when siteDomain is blank, or uses config.SiteDomain)
+// IsSiteDomainURL is true when urlString is a URL and its host is siteDomain or ends with `.`+siteDomain
 func IsSiteDomainURL(urlString, siteDomain string) bool {
-	if urlString == "" {
+	if urlString == "" || siteDomain == "" {
 		return false
 	}
 	urlString = NormaliseURL(urlString)
@@ -249,14 +359,6 @@ func IsSiteDomainURL(urlString, siteDomain string) bool {
 	if err != nil {
 		return false
 	}
-	if siteDomain == "" {
-		if cfg == nil {
-			if cfg, err = config.Get(); err != nil {
-				return false
-			}
-		}
-		siteDomain = cfg.SiteDomain
-	}
 	hostName := urlObject.Hostname()
 	if hostName != siteDomain && !strings.HasSuffix(hostName, "."+siteDomain) {
 		return false
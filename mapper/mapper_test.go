@@ -11,6 +11,7 @@ import (
 	core "github.com/ONSdigital/dis-design-system-go/model"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/mocks"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/variant"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -23,7 +24,7 @@ func TestCreateGetFeedback(t *testing.T) {
 			validationErr := []core.ErrorItem{}
 			ff := model.FeedbackForm{}
 			lang := "en"
-			sut := CreateGetFeedback(req, bp, validationErr, ff, lang)
+			sut := CreateGetFeedback(req, bp, validationErr, ff, lang, "")
 
 			Convey("Then it sets the page metadata", func() {
 				So(sut.Type, ShouldEqual, "feedback")
@@ -49,6 +50,56 @@ func TestCreateGetFeedback(t *testing.T) {
 			Convey("Then it maps the previous url field", func() {
 				So(sut.PreviousURL, ShouldEqual, ff.URL)
 			})
+
+			Convey("Then it generates an idempotency key for the form", func() {
+				So(sut.IdempotencyKey, ShouldNotBeEmpty)
+			})
+		})
+
+		Convey("When the form was submitted with an email address but without consent", func() {
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			ff := model.FeedbackForm{Email: "hello@world.com", IsConsentErr: true}
+			sut := CreateGetFeedback(req, core.Page{}, []core.ErrorItem{}, ff, "en", "")
+
+			Convey("Then the consent checkbox is unticked and shows an error", func() {
+				So(sut.ConsentField.Input.Name, ShouldEqual, "consent")
+				So(sut.ConsentField.Input.IsChecked, ShouldBeFalse)
+				So(sut.ConsentField.ValidationErr.HasValidationErr, ShouldBeTrue)
+				So(sut.ConsentField.ValidationErr.ErrorItem.ID, ShouldEqual, "consent-error")
+			})
+		})
+
+		Convey("When the short form is being tested", func() {
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			sut := CreateGetFeedback(req, core.Page{}, []core.ErrorItem{}, model.FeedbackForm{}, "en", variant.Short)
+
+			Convey("Then it asks for the feedback in fewer words without contact details", func() {
+				So(sut.Variant, ShouldEqual, variant.Short)
+				So(sut.Contact, ShouldBeEmpty)
+				So(sut.DescriptionField.Input.Label.LocaleKey, ShouldEqual, "FeedbackTitleEntryShort")
+				So(sut.DescriptionField.Input.Description.LocaleKey, ShouldEqual, "FeedbackHintEntryShort")
+			})
+		})
+
+		Convey("When the control form is being tested", func() {
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			sut := CreateGetFeedback(req, core.Page{}, []core.ErrorItem{}, model.FeedbackForm{}, "en", variant.Control)
+
+			Convey("Then it is the form as it is without a test", func() {
+				So(sut.Variant, ShouldEqual, variant.Control)
+				So(sut.Contact, ShouldHaveLength, 2)
+				So(sut.DescriptionField.Input.Label.LocaleKey, ShouldEqual, "FeedbackTitleEntry")
+			})
+		})
+
+		Convey("When the form already has an idempotency key", func() {
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			ff := model.FeedbackForm{IdempotencyKey: "abc123"}
+			sut := CreateGetFeedback(req, core.Page{}, []core.ErrorItem{}, ff, "en", "")
+
+			Convey("Then the existing key is kept", func() {
+				So(sut.IdempotencyKey, ShouldEqual, "abc123")
+			})
 		})
 
 		Convey("When a valid service parameter is passed", func() {
@@ -57,7 +108,7 @@ func TestCreateGetFeedback(t *testing.T) {
 			validationErr := []core.ErrorItem{}
 			ff := model.FeedbackForm{}
 			lang := "en"
-			sut := CreateGetFeedback(req, bp, validationErr, ff, lang)
+			sut := CreateGetFeedback(req, bp, validationErr, ff, lang, "")
 
 			Convey("Then it maps the additional radio input", func() {
 				So(sut.TypeRadios, ShouldNotBeEmpty)
@@ -80,7 +131,7 @@ func TestCreateGetFeedback(t *testing.T) {
 				},
 			}
 			ff := model.FeedbackForm{}
-			sut := CreateGetFeedback(req, bp, validationErr, ff, lang)
+			sut := CreateGetFeedback(req, bp, validationErr, ff, lang, "")
 
 			Convey("Then it maps the error panel", func() {
 				So(sut.Error.Title, ShouldNotBeEmpty)
@@ -91,7 +142,7 @@ func TestCreateGetFeedback(t *testing.T) {
 			})
 
 			ff.IsURLErr = true
-			sut = CreateGetFeedback(req, bp, validationErr, ff, lang)
+			sut = CreateGetFeedback(req, bp, validationErr, ff, lang, "")
 			Convey("Then it changes the radio validation field description", func() {
 				So(sut.TypeRadios.ValidationErr.ErrorItem.Description.Text, ShouldEqual, "Enter URL or name of the page")
 			})
@@ -99,6 +150,85 @@ func TestCreateGetFeedback(t *testing.T) {
 	})
 }
 
+func TestCreateWidget(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+	Convey("Given a request for the widget embedded in a page", t, func() {
+		req := httptest.NewRequest(http.MethodGet, "/feedback/widget?url=https://www.ons.gov.uk/economy", http.NoBody)
+		ff := model.FeedbackForm{URL: "https://www.ons.gov.uk/economy"}
+
+		Convey("When the widget is mapped", func() {
+			sut := CreateWidget(req, core.Page{}, ff, "cy")
+
+			Convey("Then it asks for feedback on that page", func() {
+				So(sut.Type, ShouldEqual, "feedback-widget")
+				So(sut.Language, ShouldEqual, "cy")
+				So(sut.PreviousURL, ShouldEqual, "https://www.ons.gov.uk/economy")
+				So(sut.TypeRadios.Radios[1].Input.IsChecked, ShouldBeTrue)
+			})
+
+			Convey("Then it has the same fields as the feedback page", func() {
+				So(sut.DescriptionField.Input.Name, ShouldEqual, "description")
+				So(sut.Contact, ShouldHaveLength, 2)
+				So(sut.ConsentField.Input.Name, ShouldEqual, "consent")
+				So(sut.IdempotencyKey, ShouldNotBeEmpty)
+				So(sut.Page.Error.ErrorItems, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the widget is mapped without a page", func() {
+			sut := CreateWidget(req, core.Page{}, model.FeedbackForm{}, "en")
+
+			Convey("Then it asks for feedback on the whole website", func() {
+				So(sut.TypeRadios.Radios[0].Input.IsChecked, ShouldBeTrue)
+				So(sut.TypeRadios.Radios[1].Input.IsChecked, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestCreateFeedbackWizard(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+	Convey("Given answers to the feedback wizard with a rating", t, func() {
+		req := httptest.NewRequest(http.MethodGet, "/feedback?step=rating", http.NoBody)
+		ff := model.FeedbackForm{Type: WholeSite, Description: "useful", Rating: 4}
+
+		Convey("When the rating step is mapped", func() {
+			sut := CreateFeedbackWizard(req, core.Page{}, nil, ff, "en", "rating", 3, 4)
+
+			Convey("Then it shows where the step is in the wizard", func() {
+				So(sut.Step, ShouldEqual, "rating")
+				So(sut.Progress, ShouldEqual, "Step 3 of 4")
+				So(sut.IsFirstStep, ShouldBeFalse)
+				So(sut.IsLastStep, ShouldBeFalse)
+			})
+
+			Convey("Then the ratings are offered with the answer checked", func() {
+				So(sut.RatingRadios.Radios, ShouldHaveLength, MaxRating-MinRating+1)
+				So(sut.RatingRadios.Radios[0].Input.Value, ShouldEqual, "1")
+				So(sut.RatingRadios.Radios[3].Input.IsChecked, ShouldBeTrue)
+				So(sut.RatingRadios.Radios[0].Input.IsChecked, ShouldBeFalse)
+				So(sut.RatingRadios.ValidationErr.HasValidationErr, ShouldBeFalse)
+			})
+
+			Convey("Then the other answers are kept in the form", func() {
+				So(sut.DescriptionField.Input.Value, ShouldEqual, "useful")
+				So(sut.TypeRadios.Radios[0].Input.IsChecked, ShouldBeTrue)
+			})
+		})
+
+		Convey("When the last step is mapped without a rating", func() {
+			ff.Rating, ff.IsRatingErr = 0, true
+			sut := CreateFeedbackWizard(req, core.Page{}, nil, ff, "en", "contact", 4, 4)
+
+			Convey("Then it is the last step and the rating error is shown", func() {
+				So(sut.IsLastStep, ShouldBeTrue)
+				So(sut.RatingRadios.ValidationErr.HasValidationErr, ShouldBeTrue)
+				So(sut.RatingRadios.ValidationErr.ErrorItem.ID, ShouldEqual, "rating-error")
+			})
+		})
+	})
+}
+
 func TestCreateGetFeedbackThanks(t *testing.T) {
 	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
 	Convey("Given a valid page request", t, func() {
@@ -108,7 +238,7 @@ func TestCreateGetFeedbackThanks(t *testing.T) {
 			pageURL := "https://localhost/a/page/somewhere"
 			lang := "en"
 			wholeSiteURL := "https://ons.gov.uk"
-			sut := CreateGetFeedbackThanks(req, bp, lang, pageURL, wholeSiteURL)
+			sut := CreateGetFeedbackThanks(req, bp, lang, pageURL, wholeSiteURL, "localhost")
 
 			Convey("Then it sets the page metadata", func() {
 				So(sut.Metadata.Title, ShouldEqual, "Thank you")
@@ -135,7 +265,7 @@ func TestCreateGetFeedbackThanks(t *testing.T) {
 
 		Convey("When the returnTo parameter is set to whole-site and whole-site is explicit", func() {
 			req := httptest.NewRequest(http.MethodGet, "/?returnTo="+encWholeSite, http.NoBody)
-			sut := CreateGetFeedbackThanks(req, bp, lang, referrer, wholeSiteURL)
+			sut := CreateGetFeedbackThanks(req, bp, lang, referrer, wholeSiteURL, "localhost")
 
 			Convey("Then it sets the returnTo property to the whole-site", func() {
 				So(sut.ReturnTo, ShouldEqual, wholeSiteURL)
@@ -143,7 +273,7 @@ func TestCreateGetFeedbackThanks(t *testing.T) {
 		})
 		Convey("When the returnTo parameter is set to whole-site but whole-site is not explicit", func() {
 			req := httptest.NewRequest(http.MethodGet, "/?returnTo="+encWholeSite, http.NoBody)
-			sut := CreateGetFeedbackThanks(req, bp, lang, referrer, "")
+			sut := CreateGetFeedbackThanks(req, bp, lang, referrer, "", "localhost")
 
 			Convey("Then it sets the returnTo property to the default whole-site", func() {
 				So(sut.ReturnTo, ShouldEqual, "https://www.ons.gov.uk")
@@ -180,26 +310,20 @@ func TestURLFunctions(t *testing.T) {
 				false,
 			},
 			{
-				"URL of the config's site domain is recognised",
+				"URL of the site domain is recognised",
 				"https://localhost",
-				"",
+				"localhost",
 				true,
 			},
 			{
-				"sub-domain/host of the config's site domain is recognised",
+				"sub-domain/host of the site domain is recognised",
 				"anything.localhost",
-				"",
+				"localhost",
 				true,
 			},
 			{
-				"non-site domain URL is not recognised for config's site domain",
-				"https://not-site-domain.example.com",
-				"",
-				false,
-			},
-			{
-				"non-URL is not recognised for config's site domain",
-				"blah",
+				"URL is not recognised without a site domain",
+				"https://localhost",
 				"",
 				false,
 			},
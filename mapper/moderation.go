@@ -0,0 +1,154 @@
+package mapper
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	core "github.com/ONSdigital/dis-design-system-go/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/csrf"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+)
+
+// ModerationPath is the path of the moderation inbox
+const ModerationPath = "/feedback/moderation"
+
+// summaryLength is the number of characters of each description shown in the inbox
+const summaryLength = 140
+
+const moderationTimeFormat = "2 Jan 2006 15:04"
+
+var statusLocaleKeys = map[string]string{
+	store.StatusNew:        "ModerationStatusNew",
+	store.StatusNeedsReply: "ModerationStatusNeedsReply",
+	store.StatusActioned:   "ModerationStatusActioned",
+	store.StatusSpam:       "ModerationStatusSpam",
+}
+
+// CreateModerationInbox maps stored feedback to the moderation inbox page, status is the status the items were filtered by
+func CreateModerationInbox(req *http.Request, basePage core.Page, lang string, items []*store.Item, status string) model.Moderation {
+	p := model.Moderation{
+		Page:   basePage,
+		Status: status,
+		Items:  make([]model.ModerationItem, 0, len(items)),
+	}
+	p.Language = lang
+	p.Type = "feedback-moderation"
+	p.URI = req.URL.Path
+	p.Metadata.Title = helper.Localise("ModerationTitle", lang, 1)
+
+	p.Filters = append(p.Filters, model.ModerationFilter{LocaleKey: "ModerationAll", URL: ModerationPath, IsCurrent: status == ""})
+	for _, s := range store.Statuses {
+		p.Filters = append(p.Filters, model.ModerationFilter{
+			LocaleKey: statusLocaleKeys[s],
+			URL:       ModerationPath + "?status=" + url.QueryEscape(s),
+			IsCurrent: status == s,
+		})
+	}
+
+	for _, item := range items {
+		p.Items = append(p.Items, mapModerationItem(item))
+	}
+	return p
+}
+
+// CreateModerationDetail maps a stored feedback item to the page used to moderate it
+func CreateModerationDetail(req *http.Request, basePage core.Page, lang string, item *store.Item) model.ModerationDetail {
+	p := model.ModerationDetail{
+		Page:      basePage,
+		Item:      mapModerationItem(item),
+		CSRFToken: csrf.Token(req),
+	}
+	p.Language = lang
+	p.Type = "feedback-moderation"
+	p.URI = req.URL.Path
+	p.Metadata.Title = helper.Localise("ModerationTitle", lang, 1)
+	p.Breadcrumb = []core.TaxonomyNode{
+		{
+			Title: helper.Localise("ModerationTitle", lang, 1),
+			URI:   ModerationPath,
+		},
+	}
+
+	p.StatusRadios = core.RadioFieldset{
+		Legend: core.Localisation{
+			LocaleKey: "ModerationStatus",
+			Plural:    1,
+		},
+	}
+	for _, s := range store.Statuses {
+		p.StatusRadios.Radios = append(p.StatusRadios.Radios, core.Radio{
+			Input: core.Input{
+				ID:        "status-" + s,
+				IsChecked: item.Status == s,
+				Label: core.Localisation{
+					LocaleKey: statusLocaleKeys[s],
+					Plural:    1,
+				},
+				Name:  "status",
+				Value: s,
+			},
+		})
+	}
+
+	p.NoteField = core.TextareaField{
+		Input: core.Input{
+			Autocomplete: "off",
+			ID:           "note-field",
+			Label: core.Localisation{
+				LocaleKey: "ModerationAddNote",
+				Plural:    1,
+			},
+			Language: lang,
+			Name:     "note",
+		},
+	}
+
+	return p
+}
+
+func mapModerationItem(item *store.Item) model.ModerationItem {
+	m := model.ModerationItem{
+		ID:         item.ID,
+		URL:        ModerationPath + "/" + item.ID,
+		ReceivedAt: item.ReceivedAt.Format(moderationTimeFormat),
+		Status:     item.Status,
+		StatusKey:  statusLocaleKeys[item.Status],
+	}
+
+	if s := item.Submission; s != nil {
+		m.PageURL = s.OnsURL
+		m.Description = s.Feedback.Feedback
+		m.Summary = summarise(s.Feedback.Feedback, summaryLength)
+		m.Name = s.Name
+		m.Email = s.EmailAddress
+		m.CanReply = s.EmailAddress != ""
+		m.Tags = s.Tags
+		m.Topic = s.Topic
+		m.Subtopic = s.Subtopic
+		m.Team = s.Team
+		if s.Sentiment != nil {
+			m.Sentiment = strconv.FormatFloat(*s.Sentiment, 'f', 2, 64)
+		}
+	}
+
+	for _, n := range item.Notes {
+		m.Notes = append(m.Notes, model.ModerationNote{
+			Author:    n.Author,
+			Text:      n.Text,
+			CreatedAt: n.CreatedAt.Format(moderationTimeFormat),
+		})
+	}
+	return m
+}
+
+// summarise shortens text to at most length characters, ending with an ellipsis if it was shortened
+func summarise(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length-1]) + "…"
+}
@@ -0,0 +1,112 @@
+package mapper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	core "github.com/ONSdigital/dis-design-system-go/model"
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/csrf"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mocks"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newModerationItem() *store.Item {
+	sentiment := -0.4567
+	return &store.Item{
+		ID: "17bc5a1e0a3b4c00deadbeef",
+		Submission: &submission.Submission{
+			Feedback: feedbackAPIModel.Feedback{
+				OnsURL:       "https://www.ons.gov.uk/economy",
+				Feedback:     strings.Repeat("a", 200),
+				Name:         "Jo",
+				EmailAddress: "jo@example.com",
+			},
+			Tags:      []string{"search"},
+			Topic:     "Economy",
+			Sentiment: &sentiment,
+		},
+		ReceivedAt: time.Date(2024, 3, 14, 9, 30, 0, 0, time.UTC),
+		Status:     store.StatusNeedsReply,
+		Notes:      []store.Note{{Author: "moderator@ons.gov.uk", Text: "asked the team", CreatedAt: time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)}},
+	}
+}
+
+func TestCreateModerationInbox(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+	Convey("Given stored feedback filtered by status", t, func() {
+		req := httptest.NewRequest(http.MethodGet, "/feedback/moderation?status=needs-reply", http.NoBody)
+		items := []*store.Item{newModerationItem()}
+
+		Convey("When the inbox is mapped", func() {
+			sut := CreateModerationInbox(req, core.Page{}, "en", items, store.StatusNeedsReply)
+
+			Convey("Then it sets the page metadata", func() {
+				So(sut.Type, ShouldEqual, "feedback-moderation")
+				So(sut.Metadata.Title, ShouldEqual, "Feedback moderation")
+				So(sut.Language, ShouldEqual, "en")
+			})
+
+			Convey("Then it offers a filter for every status, marking the current one", func() {
+				So(sut.Filters, ShouldHaveLength, len(store.Statuses)+1)
+				So(sut.Filters[0].URL, ShouldEqual, ModerationPath)
+				So(sut.Filters[0].IsCurrent, ShouldBeFalse)
+				So(sut.Filters[2].URL, ShouldEqual, ModerationPath+"?status=needs-reply")
+				So(sut.Filters[2].IsCurrent, ShouldBeTrue)
+			})
+
+			Convey("Then each item links to its own page with a summary of the feedback", func() {
+				So(sut.Items, ShouldHaveLength, 1)
+				item := sut.Items[0]
+				So(item.URL, ShouldEqual, ModerationPath+"/17bc5a1e0a3b4c00deadbeef")
+				So(item.ReceivedAt, ShouldEqual, "14 Mar 2024 09:30")
+				So(item.StatusKey, ShouldEqual, "ModerationStatusNeedsReply")
+				So([]rune(item.Summary), ShouldHaveLength, summaryLength)
+				So(item.Summary, ShouldEndWith, "…")
+				So(item.Sentiment, ShouldEqual, "-0.46")
+			})
+		})
+	})
+}
+
+func TestCreateModerationDetail(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+	Convey("Given an item of stored feedback", t, func() {
+		req := httptest.NewRequest(http.MethodGet, "/feedback/moderation/17bc5a1e0a3b4c00deadbeef", http.NoBody)
+		req.AddCookie(&http.Cookie{Name: dprequest.FlorenceCookieKey, Value: "access-token"})
+
+		Convey("When the moderation page is mapped", func() {
+			sut := CreateModerationDetail(req, core.Page{}, "en", newModerationItem())
+
+			Convey("Then the full feedback and its notes are shown", func() {
+				So(sut.Item.Description, ShouldHaveLength, 200)
+				So(sut.Item.Notes, ShouldHaveLength, 1)
+				So(sut.Item.Notes[0].Author, ShouldEqual, "moderator@ons.gov.uk")
+			})
+
+			Convey("Then the form is given the signed in user's csrf token", func() {
+				So(sut.CSRFToken, ShouldNotBeEmpty)
+				So(sut.CSRFToken, ShouldEqual, csrf.Token(req))
+			})
+
+			Convey("Then the current status is selected", func() {
+				So(sut.StatusRadios.Radios, ShouldHaveLength, len(store.Statuses))
+				for _, r := range sut.StatusRadios.Radios {
+					So(r.Input.IsChecked, ShouldEqual, r.Input.Value == store.StatusNeedsReply)
+				}
+			})
+
+			Convey("Then it links back to the inbox", func() {
+				So(sut.Breadcrumb, ShouldHaveLength, 1)
+				So(sut.Breadcrumb[0].URI, ShouldEqual, ModerationPath)
+			})
+		})
+	})
+}
@@ -0,0 +1,189 @@
+package mapper
+
+import (
+	"cmp"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	core "github.com/ONSdigital/dis-design-system-go/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/csrf"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/reply"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+)
+
+// CreateModerationReply maps stored feedback to the page for replying to it, with the subject and body of the reply
+// so far
+func CreateModerationReply(req *http.Request, basePage core.Page, lang string, item *store.Item, templates []reply.Template, selected, subject, body string, validationErrors []core.ErrorItem) model.ModerationReply {
+	p := model.ModerationReply{
+		Page:      basePage,
+		Item:      mapModerationItem(item),
+		Template:  selected,
+		CSRFToken: csrf.Token(req),
+	}
+	p.Language = lang
+	p.Type = "feedback-moderation"
+	p.URI = req.URL.Path
+	p.Metadata.Title = helper.Localise("ReplyTitle", lang, 1)
+	p.Breadcrumb = []core.TaxonomyNode{
+		{
+			Title: helper.Localise("ModerationTitle", lang, 1),
+			URI:   ModerationPath,
+		},
+		{
+			Title: helper.Localise("ModerationFeedback", lang, 1),
+			URI:   p.Item.URL,
+		},
+	}
+
+	if len(validationErrors) > 0 {
+		p.Page.Error = core.Error{
+			Title:      p.Metadata.Title,
+			ErrorItems: validationErrors,
+			Language:   lang,
+		}
+	}
+
+	for _, t := range templates {
+		p.Templates = append(p.Templates, model.ModerationLink{
+			Text:      t.Title,
+			URL:       p.Item.URL + "/reply?template=" + url.QueryEscape(t.Name),
+			IsCurrent: t.Name == selected,
+		})
+	}
+
+	p.SubjectField = core.TextField{
+		Input: core.Input{
+			Autocomplete: "off",
+			ID:           "subject-field",
+			Label: core.Localisation{
+				LocaleKey: "ReplySubject",
+				Plural:    1,
+			},
+			Name:  "subject",
+			Value: subject,
+		},
+		ValidationErr: core.ValidationErr{
+			HasValidationErr: hasError(validationErrors, "#subject-error"),
+			ErrorItem: core.ErrorItem{
+				Description: core.Localisation{
+					LocaleKey: "ReplyAlertSubject",
+					Plural:    1,
+				},
+				ID: "subject-error",
+			},
+		},
+	}
+
+	p.BodyField = core.TextareaField{
+		Input: core.Input{
+			Autocomplete: "off",
+			ID:           "body-field",
+			Label: core.Localisation{
+				LocaleKey: "ReplyBody",
+				Plural:    1,
+			},
+			Language: lang,
+			Name:     "body",
+			Value:    body,
+		},
+		ValidationErr: core.ValidationErr{
+			HasValidationErr: hasError(validationErrors, "#body-error"),
+			ErrorItem: core.ErrorItem{
+				Description: core.Localisation{
+					LocaleKey: "ReplyAlertBody",
+					Plural:    1,
+				},
+				ID: "body-error",
+			},
+		},
+	}
+
+	return p
+}
+
+// CreateModerationHistory maps stored feedback to a timeline of everything that has happened to it, oldest first
+func CreateModerationHistory(req *http.Request, basePage core.Page, lang string, item *store.Item) model.ModerationHistory {
+	p := model.ModerationHistory{
+		Page: basePage,
+		Item: mapModerationItem(item),
+	}
+	p.Language = lang
+	p.Type = "feedback-moderation"
+	p.URI = req.URL.Path
+	p.Metadata.Title = helper.Localise("HistoryTitle", lang, 1)
+	p.Breadcrumb = []core.TaxonomyNode{
+		{
+			Title: helper.Localise("ModerationTitle", lang, 1),
+			URI:   ModerationPath,
+		},
+		{
+			Title: helper.Localise("ModerationFeedback", lang, 1),
+			URI:   p.Item.URL,
+		},
+	}
+
+	type event struct {
+		model.ModerationEvent
+		at int64
+	}
+	events := []event{{
+		ModerationEvent: model.ModerationEvent{LocaleKey: "HistoryReceived", At: item.ReceivedAt.Format(moderationTimeFormat)},
+		at:              item.ReceivedAt.UnixNano(),
+	}}
+	for _, c := range item.StatusChanges {
+		events = append(events, event{
+			ModerationEvent: model.ModerationEvent{
+				LocaleKey: "HistoryStatusChanged",
+				At:        c.ChangedAt.Format(moderationTimeFormat),
+				Author:    c.Author,
+				FromKey:   statusLocaleKeys[c.From],
+				ToKey:     statusLocaleKeys[c.To],
+			},
+			at: c.ChangedAt.UnixNano(),
+		})
+	}
+	for _, n := range item.Notes {
+		events = append(events, event{
+			ModerationEvent: model.ModerationEvent{
+				LocaleKey: "HistoryNoteAdded",
+				At:        n.CreatedAt.Format(moderationTimeFormat),
+				Author:    n.Author,
+				Text:      n.Text,
+			},
+			at: n.CreatedAt.UnixNano(),
+		})
+	}
+	for _, r := range item.Replies {
+		events = append(events, event{
+			ModerationEvent: model.ModerationEvent{
+				LocaleKey: "HistoryReplySent",
+				At:        r.SentAt.Format(moderationTimeFormat),
+				Author:    r.Author,
+				To:        r.To,
+				Subject:   r.Subject,
+				Text:      r.Body,
+			},
+			at: r.SentAt.UnixNano(),
+		})
+	}
+
+	slices.SortStableFunc(events, func(a, b event) int {
+		return cmp.Compare(a.at, b.at)
+	})
+	for _, e := range events {
+		p.Events = append(p.Events, e.ModerationEvent)
+	}
+	return p
+}
+
+func hasError(validationErrors []core.ErrorItem, url string) bool {
+	for _, e := range validationErrors {
+		if e.URL == url {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package mapper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dis-design-system-go/helper"
+	core "github.com/ONSdigital/dis-design-system-go/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/csrf"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mocks"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/reply"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCreateModerationReply(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+	Convey("Given stored feedback with contact details", t, func() {
+		item := newModerationItem()
+		req := httptest.NewRequest(http.MethodGet, ModerationPath+"/"+item.ID+"/reply?template=fixed", http.NoBody)
+		req.AddCookie(&http.Cookie{Name: dprequest.FlorenceCookieKey, Value: "access-token"})
+
+		Convey("When the reply form is mapped", func() {
+			sut := CreateModerationReply(req, core.Page{}, "en", item, reply.DefaultTemplates, "fixed", "Subject", "Body", nil)
+
+			Convey("Then it sets the page metadata", func() {
+				So(sut.Metadata.Title, ShouldEqual, "Reply to feedback")
+				So(sut.Breadcrumb, ShouldHaveLength, 2)
+				So(sut.Breadcrumb[1].URI, ShouldEqual, ModerationPath+"/"+item.ID)
+				So(sut.Error.Title, ShouldBeEmpty)
+			})
+
+			Convey("Then the form is given the signed in user's csrf token", func() {
+				So(sut.CSRFToken, ShouldNotBeEmpty)
+				So(sut.CSRFToken, ShouldEqual, csrf.Token(req))
+			})
+
+			Convey("Then it links to every template, marking the selected one", func() {
+				So(sut.Templates, ShouldHaveLength, len(reply.DefaultTemplates))
+				So(sut.Templates[0].URL, ShouldEqual, ModerationPath+"/"+item.ID+"/reply?template=thanks")
+				So(sut.Templates[0].IsCurrent, ShouldBeFalse)
+				So(sut.Templates[1].IsCurrent, ShouldBeTrue)
+			})
+
+			Convey("Then the subject and body are filled in", func() {
+				So(sut.SubjectField.Input.Value, ShouldEqual, "Subject")
+				So(sut.BodyField.Input.Value, ShouldEqual, "Body")
+			})
+		})
+
+		Convey("When the reply form is mapped with a missing body", func() {
+			validationErrors := []core.ErrorItem{{URL: "#body-error"}}
+			sut := CreateModerationReply(req, core.Page{}, "en", item, reply.DefaultTemplates, "fixed", "Subject", "", validationErrors)
+
+			Convey("Then only the body is marked as having an error", func() {
+				So(sut.Error.ErrorItems, ShouldResemble, validationErrors)
+				So(sut.SubjectField.ValidationErr.HasValidationErr, ShouldBeFalse)
+				So(sut.BodyField.ValidationErr.HasValidationErr, ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestCreateModerationHistory(t *testing.T) {
+	helper.InitialiseLocalisationsHelper(mocks.MockAssetFunction)
+	Convey("Given stored feedback that has been moderated and replied to", t, func() {
+		item := newModerationItem()
+		item.StatusChanges = []store.StatusChange{{Author: "moderator@ons.gov.uk", From: store.StatusNew, To: store.StatusNeedsReply, ChangedAt: time.Date(2024, 3, 14, 11, 0, 0, 0, time.UTC)}}
+		item.Replies = []store.Reply{{Author: "moderator@ons.gov.uk", To: "jo@example.com", Subject: "Your feedback", Body: "Thank you.", SentAt: time.Date(2024, 3, 16, 9, 0, 0, 0, time.UTC)}}
+		req := httptest.NewRequest(http.MethodGet, ModerationPath+"/"+item.ID+"/history", http.NoBody)
+
+		Convey("When the history is mapped", func() {
+			sut := CreateModerationHistory(req, core.Page{}, "en", item)
+
+			Convey("Then it sets the page metadata", func() {
+				So(sut.Metadata.Title, ShouldEqual, "Feedback history")
+				So(sut.Type, ShouldEqual, "feedback-moderation")
+			})
+
+			Convey("Then every event is listed oldest first", func() {
+				So(sut.Events, ShouldHaveLength, 4)
+				So(sut.Events[0].LocaleKey, ShouldEqual, "HistoryReceived")
+				So(sut.Events[1].LocaleKey, ShouldEqual, "HistoryStatusChanged")
+				So(sut.Events[1].FromKey, ShouldEqual, "ModerationStatusNew")
+				So(sut.Events[1].ToKey, ShouldEqual, "ModerationStatusNeedsReply")
+				So(sut.Events[2].LocaleKey, ShouldEqual, "HistoryNoteAdded")
+				So(sut.Events[2].Text, ShouldEqual, "asked the team")
+				So(sut.Events[3].LocaleKey, ShouldEqual, "HistoryReplySent")
+				So(sut.Events[3].To, ShouldEqual, "jo@example.com")
+				So(sut.Events[3].At, ShouldEqual, "16 Mar 2024 09:00")
+			})
+		})
+	})
+}
@@ -14,6 +14,28 @@ var enLocale = []string{
 	"one = \"This service\"",
 	"[FeedbackWhatEnterURL]",
 	"one = \"Enter URL or name of the page\"",
+	"[FeedbackAlertEntry]",
+	"one = \"Write some feedback\"",
+	"[FeedbackAlertEmail]",
+	"one = \"This is not a valid email address, correct it or delete it\"",
+	"[FeedbackAlertConsent]",
+	"one = \"Agree to us using your contact details, or delete your email address\"",
+	"[FeedbackWidgetSendFailed]",
+	"one = \"Sorry, there was a problem sending your feedback. Try again later.\"",
+	"[FeedbackWizardProgress]",
+	"one = \"Step {{.arg0}} of {{.arg1}}\"",
+	"[FeedbackChooseRating]",
+	"one = \"Choose a rating\"",
+	"[ModerationTitle]",
+	"one = \"Feedback moderation\"",
+	"[DashboardTitle]",
+	"one = \"Feedback dashboard\"",
+	"[ModerationFeedback]",
+	"one = \"Feedback\"",
+	"[ReplyTitle]",
+	"one = \"Reply to feedback\"",
+	"[HistoryTitle]",
+	"one = \"Feedback history\"",
 }
 
 // MockAssetFunction returns mocked toml []bytes
@@ -8,21 +8,182 @@ type Feedback struct {
 	Contact          []model.TextField   `json:"contact"`
 	TypeRadios       model.RadioFieldset `json:"type_radios"`
 	DescriptionField model.TextareaField `json:"description_field"`
+	ConsentField     CheckboxField       `json:"consent_field"`
 	PreviousURL      string              `json:"previous_url"`
 	ReturnTo         string              `json:"return_to"`
+	IdempotencyKey   string              `json:"idempotency_key"`
+	Draft            Draft               `json:"draft"`
+	Features         Features            `json:"features"`
+	Variant          string              `json:"variant"`
 }
 
-// FeedbackForm represents the user feedback form
+// FeedbackWizard is the page model for a step of the feedback wizard, which asks for the feedback form a part at a time
+type FeedbackWizard struct {
+	Feedback
+	RatingRadios model.RadioFieldset `json:"rating_radios"`
+	Step         string              `json:"step"`
+	Progress     string              `json:"progress"`
+	IsFirstStep  bool                `json:"is_first_step"`
+	IsLastStep   bool                `json:"is_last_step"`
+}
+
+// Features are the feature flags turned on for a request, by name
+type Features map[string]bool
+
+// On is true when the feature flag called name is turned on, for templates to use as {{ if .Features.On "name" }}
+func (f Features) On(name string) bool {
+	return f[name]
+}
+
+// Draft says whether the feedback form is saved as the user writes and whether it was restored from a saved draft
+type Draft struct {
+	CanSave    bool `json:"can_save"`
+	IsRestored bool `json:"is_restored"`
+}
+
+// CheckboxField defines the fields for a single checkbox
+type CheckboxField struct {
+	Input         model.Input         `json:"input"`
+	ValidationErr model.ValidationErr `json:"validation_err"`
+}
+
+// FeedbackForm represents the user feedback form, posted from the page or sent as JSON by the widget
 type FeedbackForm struct {
-	FormLocation     string `schema:"feedback-form-type"`
-	Type             string `schema:"type"`
-	IsTypeErr        bool   `schema:"is_type_err"`
-	URI              string `schema:":uri"`
-	URL              string `schema:"url"`
-	IsURLErr         bool   `schema:"is_url_err"`
-	Description      string `schema:"description"`
-	IsDescriptionErr bool   `schema:"is_description_err"`
-	Name             string `schema:"name"`
-	Email            string `schema:"email"`
-	IsEmailErr       bool   `schema:"is_email_err"`
+	FormLocation     string `schema:"feedback-form-type" json:"-"`
+	Type             string `schema:"type"               json:"type"`
+	IsTypeErr        bool   `schema:"is_type_err"        json:"-"`
+	URI              string `schema:":uri"               json:"-"`
+	URL              string `schema:"url"                json:"url"`
+	IsURLErr         bool   `schema:"is_url_err"         json:"-"`
+	Description      string `schema:"description"        json:"description"`
+	IsDescriptionErr bool   `schema:"is_description_err" json:"-"`
+	Name             string `schema:"name"               json:"name"`
+	Email            string `schema:"email"              json:"email"`
+	IsEmailErr       bool   `schema:"is_email_err"       json:"-"`
+	Consent          bool   `schema:"consent"            json:"consent"`
+	IsConsentErr     bool   `schema:"is_consent_err"     json:"-"`
+	Rating           int    `schema:"rating"             json:"rating,omitempty"`
+	IsRatingErr      bool   `schema:"is_rating_err"      json:"-"`
+	IdempotencyKey   string `schema:"idempotency-key"    json:"idempotency_key"`
+	Variant          string `schema:"variant"            json:"-"`
+}
+
+// Moderation is the page model for the moderation inbox
+type Moderation struct {
+	model.Page
+	Status  string             `json:"status"`
+	Filters []ModerationFilter `json:"filters"`
+	Items   []ModerationItem   `json:"items"`
+}
+
+// ModerationFilter links to the inbox restricted to a status
+type ModerationFilter struct {
+	LocaleKey string `json:"locale_key"`
+	URL       string `json:"url"`
+	IsCurrent bool   `json:"is_current"`
+}
+
+// ModerationItem is stored feedback as shown to moderators
+type ModerationItem struct {
+	ID          string           `json:"id"`
+	URL         string           `json:"url"`
+	ReceivedAt  string           `json:"received_at"`
+	PageURL     string           `json:"page_url"`
+	Description string           `json:"description"`
+	Summary     string           `json:"summary"`
+	Name        string           `json:"name"`
+	Email       string           `json:"email"`
+	Status      string           `json:"status"`
+	StatusKey   string           `json:"status_key"`
+	Tags        []string         `json:"tags"`
+	Topic       string           `json:"topic"`
+	Subtopic    string           `json:"subtopic"`
+	Team        string           `json:"team"`
+	Sentiment   string           `json:"sentiment"`
+	Notes       []ModerationNote `json:"notes"`
+	CanReply    bool             `json:"can_reply"`
+}
+
+// ModerationNote is a note added to feedback by a moderator
+type ModerationNote struct {
+	Author    string `json:"author"`
+	Text      string `json:"text"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ModerationDetail is the page model for moderating a single item of feedback
+type ModerationDetail struct {
+	model.Page
+	Item         ModerationItem      `json:"item"`
+	StatusRadios model.RadioFieldset `json:"status_radios"`
+	NoteField    model.TextareaField `json:"note_field"`
+	CSRFToken    string              `json:"csrf_token"`
+}
+
+// ModerationReply is the page model for replying to the person who left feedback
+type ModerationReply struct {
+	model.Page
+	Item         ModerationItem      `json:"item"`
+	Templates    []ModerationLink    `json:"templates"`
+	Template     string              `json:"template"`
+	SubjectField model.TextField     `json:"subject_field"`
+	BodyField    model.TextareaField `json:"body_field"`
+	CSRFToken    string              `json:"csrf_token"`
+}
+
+// ModerationLink links to another view of a moderation page
+type ModerationLink struct {
+	Text      string `json:"text"`
+	URL       string `json:"url"`
+	IsCurrent bool   `json:"is_current"`
+}
+
+// ModerationHistory is the page model for everything that has happened to an item of feedback
+type ModerationHistory struct {
+	model.Page
+	Item   ModerationItem    `json:"item"`
+	Events []ModerationEvent `json:"events"`
+}
+
+// ModerationEvent is something that happened to an item of feedback, LocaleKey describes what
+type ModerationEvent struct {
+	LocaleKey string `json:"locale_key"`
+	At        string `json:"at"`
+	Author    string `json:"author"`
+	Text      string `json:"text"`
+	Subject   string `json:"subject"`
+	To        string `json:"to"`
+	FromKey   string `json:"from_key"`
+	ToKey     string `json:"to_key"`
+}
+
+// Dashboard is the page model for the feedback dashboard
+type Dashboard struct {
+	model.Page
+	Weeks        int            `json:"weeks"`
+	Period       string         `json:"period"`
+	Total        int            `json:"total"`
+	WeeklyCounts []DashboardBar `json:"weekly_counts"`
+	Types        []DashboardBar `json:"types"`
+	Languages    []DashboardBar `json:"languages"`
+	TopURLs      []DashboardBar `json:"top_urls"`
+	Sentiment    DashboardRatio `json:"sentiment"`
+	Satisfaction DashboardRatio `json:"satisfaction"`
+}
+
+// DashboardBar is a count shown as a bar, Width is the percentage of the largest count in its chart
+type DashboardBar struct {
+	Label     string `json:"label"`
+	LocaleKey string `json:"locale_key"`
+	Count     int    `json:"count"`
+	Percent   int    `json:"percent"`
+	Width     int    `json:"width"`
+}
+
+// DashboardRatio is the proportion of feedback answering yes to a question
+type DashboardRatio struct {
+	Count   int  `json:"count"`
+	Total   int  `json:"total"`
+	Percent int  `json:"percent"`
+	HasData bool `json:"has_data"`
 }
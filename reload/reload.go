@@ -0,0 +1,185 @@
+package reload
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// Func puts a reloaded config into use, returning an error if it cannot be used
+type Func func(ctx context.Context, cfg *config.Config) error
+
+// Status is the outcome of the most recent reload
+type Status struct {
+	LastAttempt time.Time
+	LastSuccess time.Time
+	Err         error
+}
+
+// Reloader rereads the config when the config file changes or the service is sent SIGHUP. Only the settings that can
+// change while the service runs are taken from it, and if they cannot be used the config already in use is kept.
+type Reloader struct {
+	path     string
+	apply    Func
+	interval time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	current *config.Config
+	status  Status
+	modTime time.Time
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// New creates a Reloader for the service started with cfg, which checks cfg.ConfigFile for changes every
+// cfg.ConfigReloadInterval and passes each reloaded config to apply
+func New(cfg *config.Config, apply Func) *Reloader {
+	return &Reloader{
+		path:     cfg.ConfigFile,
+		apply:    apply,
+		interval: cfg.ConfigReloadInterval,
+		now:      time.Now,
+		current:  cfg,
+	}
+}
+
+// Start watches for changes to the config file and SIGHUP in the background, until Stop is called
+func (r *Reloader) Start(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil {
+		return
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	r.modTime = r.fileModTime()
+
+	// signals are caught from here on, rather than once the goroutine is running
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+
+	log.Info(ctx, "watching for config changes", log.Data{"path": r.path, "interval": r.interval.String()})
+	go r.run(ctx, hangups, r.stop, r.done)
+}
+
+// Stop stops watching for changes, waiting for a reload that is in progress to finish or ctx to be done
+func (r *Reloader) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	stop, done := r.stop, r.done
+	r.stop, r.done = nil, nil
+	r.mu.Unlock()
+	if stop == nil {
+		return nil
+	}
+
+	close(stop)
+	select {
+	case <-done:
+		log.Info(ctx, "config reloader stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Reloader) run(ctx context.Context, hangups chan os.Signal, stop, done chan struct{}) {
+	defer close(done)
+	defer signal.Stop(hangups)
+
+	// the file is only polled when there is one, otherwise the ticker channel is left nil
+	var ticks <-chan time.Time
+	if r.path != "" && r.interval > 0 {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-hangups:
+			log.Info(ctx, "SIGHUP received, reloading config")
+			_ = r.Reload(ctx)
+		case <-ticks:
+			r.mu.Lock()
+			modTime, changed := r.fileModTime(), false
+			if !modTime.Equal(r.modTime) {
+				r.modTime, changed = modTime, true
+			}
+			r.mu.Unlock()
+			if changed {
+				log.Info(ctx, "config file changed, reloading config", log.Data{"path": r.path})
+				_ = r.Reload(ctx)
+			}
+		}
+	}
+}
+
+// Reload rereads the config and puts the settings that can change into use. If the config cannot be read or used,
+// the error is returned and the config already in use is kept.
+func (r *Reloader) Reload(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status.LastAttempt = r.now()
+	next, err := config.Read(r.path)
+	if err == nil {
+		reloaded, fixed := r.current.Reloaded(next)
+		if len(fixed) > 0 {
+			log.Warn(ctx, "ignoring config changes that need a restart", log.Data{"settings": fixed})
+		}
+		if err = r.apply(ctx, reloaded); err == nil {
+			r.current = reloaded
+			r.status.LastSuccess, r.status.Err = r.status.LastAttempt, nil
+			log.Info(ctx, "config reloaded")
+			return nil
+		}
+	}
+
+	r.status.Err = err
+	log.Error(ctx, "failed to reload config, keeping the config in use", err)
+	return err
+}
+
+// Status returns the outcome of the most recent reload
+func (r *Reloader) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.status
+}
+
+// Checker reports the outcome of the most recent reload on the health endpoint, with a warning if it failed
+func (r *Reloader) Checker(ctx context.Context, state *healthcheck.CheckState) error {
+	status := r.Status()
+	switch {
+	case status.Err != nil:
+		return state.Update(healthcheck.StatusWarning, fmt.Sprintf("config reload at %s failed: %s", status.LastAttempt.Format(time.RFC3339), status.Err), 0)
+	case status.LastSuccess.IsZero():
+		return state.Update(healthcheck.StatusOK, "config has not been reloaded", 0)
+	default:
+		return state.Update(healthcheck.StatusOK, fmt.Sprintf("config reloaded at %s", status.LastSuccess.Format(time.RFC3339)), 0)
+	}
+}
+
+// fileModTime returns when the config file was last changed, or the zero time if there is no file to check
+func (r *Reloader) fileModTime() time.Time {
+	if r.path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,161 @@
+package reload
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReloader(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a service started with a config file", t, func() {
+		path := filepath.Join(t.TempDir(), "config.toml")
+		writeFile := func(contents string) {
+			So(os.WriteFile(path, []byte("config_reload_interval = \"10ms\"\n"+contents), 0o600), ShouldBeNil)
+		}
+		writeFile("widget_allowed_origins = [\"https://one.example.com\"]\n")
+		started, err := config.Read(path)
+		So(err, ShouldBeNil)
+
+		applied := make(chan *config.Config, 10)
+		var applyErr error
+		r := New(started, func(ctx context.Context, cfg *config.Config) error {
+			if applyErr != nil {
+				return applyErr
+			}
+			applied <- cfg
+			return nil
+		})
+		now := time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)
+		r.now = func() time.Time { return now }
+
+		check := func() *healthcheck.CheckState {
+			state := healthcheck.NewCheckState("config reload")
+			So(r.Checker(ctx, state), ShouldBeNil)
+			return state
+		}
+
+		Convey("When it has not been reloaded", func() {
+			Convey("Then the health check is OK", func() {
+				state := check()
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldEqual, "config has not been reloaded")
+			})
+		})
+
+		Convey("When settings that can change are changed and the config is reloaded", func() {
+			writeFile("widget_allowed_origins = [\"https://two.example.com\"]\n")
+			err := r.Reload(ctx)
+
+			Convey("Then the new settings are put into use", func() {
+				So(err, ShouldBeNil)
+				cfg := <-applied
+				So(cfg.WidgetAllowedOrigins, ShouldResemble, []string{"https://two.example.com"})
+				So(r.Status().LastSuccess, ShouldEqual, now)
+			})
+
+			Convey("Then the health check reports the reload", func() {
+				state := check()
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldEqual, "config reloaded at 2024-03-14T12:00:00Z")
+			})
+		})
+
+		Convey("When settings that need a restart are changed and the config is reloaded", func() {
+			writeFile("bind_addr = \":9999\"\nwidget_allowed_origins = [\"https://two.example.com\"]\n")
+			err := r.Reload(ctx)
+
+			Convey("Then they are left as they were", func() {
+				So(err, ShouldBeNil)
+				cfg := <-applied
+				So(cfg.BindAddr, ShouldEqual, started.BindAddr)
+				So(cfg.WidgetAllowedOrigins, ShouldResemble, []string{"https://two.example.com"})
+			})
+		})
+
+		Convey("When the config file cannot be read", func() {
+			writeFile("widget_allowed_origins = ")
+			err := r.Reload(ctx)
+
+			Convey("Then the config in use is kept and the health check warns", func() {
+				So(err, ShouldNotBeNil)
+				So(applied, ShouldBeEmpty)
+				state := check()
+				So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+				So(state.Message(), ShouldStartWith, "config reload at 2024-03-14T12:00:00Z failed: failed to read config file")
+			})
+		})
+
+		Convey("When the reloaded config cannot be used", func() {
+			applyErr = errors.New("SITE_DOMAIN must be set")
+			err := r.Reload(ctx)
+
+			Convey("Then the failure is reported", func() {
+				So(err, ShouldEqual, applyErr)
+				So(r.Status().Err, ShouldEqual, applyErr)
+				So(r.Status().LastSuccess.IsZero(), ShouldBeTrue)
+			})
+
+			Convey("And it is fixed and reloaded again", func() {
+				applyErr = nil
+				So(r.Reload(ctx), ShouldBeNil)
+
+				Convey("Then the failure is cleared", func() {
+					So(r.Status().Err, ShouldBeNil)
+					So(check().Status(), ShouldEqual, healthcheck.StatusOK)
+				})
+			})
+		})
+
+		Convey("When the reloader is started", func() {
+			r.Start(ctx)
+
+			Convey("And the config file changes", func() {
+				writeFile("widget_allowed_origins = [\"https://three.example.com\"]\n")
+				// file systems that only record whole seconds would otherwise miss the change
+				future := time.Now().Add(time.Minute)
+				So(os.Chtimes(path, future, future), ShouldBeNil)
+
+				Convey("Then the config is reloaded", func() {
+					select {
+					case cfg := <-applied:
+						So(cfg.WidgetAllowedOrigins, ShouldResemble, []string{"https://three.example.com"})
+					case <-time.After(5 * time.Second):
+						So("config was not reloaded", ShouldBeEmpty)
+					}
+					So(r.Stop(ctx), ShouldBeNil)
+				})
+			})
+
+			Convey("And the service is sent SIGHUP", func() {
+				So(syscall.Kill(syscall.Getpid(), syscall.SIGHUP), ShouldBeNil)
+
+				Convey("Then the config is reloaded", func() {
+					select {
+					case <-applied:
+					case <-time.After(5 * time.Second):
+						So("config was not reloaded", ShouldBeEmpty)
+					}
+					So(r.Stop(ctx), ShouldBeNil)
+				})
+			})
+
+			Convey("And it is stopped", func() {
+				So(r.Stop(ctx), ShouldBeNil)
+
+				Convey("Then stopping it again does nothing", func() {
+					So(r.Stop(ctx), ShouldBeNil)
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,161 @@
+package reply
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Template is a reply that staff can start from. The subject and body are Go text templates given Data.
+type Template struct {
+	Name    string `toml:"name"`
+	Title   string `toml:"title"`
+	Subject string `toml:"subject"`
+	Body    string `toml:"body"`
+}
+
+// Data is the feedback a reply is written about
+type Data struct {
+	Name       string
+	Email      string
+	URL        string
+	Feedback   string
+	ReceivedAt string
+}
+
+// DefaultTemplates are used when no reply templates file is configured
+var DefaultTemplates = []Template{
+	{
+		Name:    "thanks",
+		Title:   "Thank you",
+		Subject: "Your feedback to the Office for National Statistics",
+		Body: `Dear {{if .Name}}{{.Name}}{{else}}Sir or Madam{{end}},
+
+Thank you for your feedback{{if .URL}} about {{.URL}}{{end}} on {{.ReceivedAt}}. We have passed it to the team responsible.
+
+Kind regards,
+Office for National Statistics`,
+	},
+	{
+		Name:    "fixed",
+		Title:   "Problem fixed",
+		Subject: "We have fixed the problem you reported",
+		Body: `Dear {{if .Name}}{{.Name}}{{else}}Sir or Madam{{end}},
+
+Thank you for letting us know about a problem{{if .URL}} with {{.URL}}{{end}}. It has now been fixed.
+
+Kind regards,
+Office for National Statistics`,
+	},
+	{
+		Name:    "more-information",
+		Title:   "Ask for more information",
+		Subject: "Your feedback to the Office for National Statistics",
+		Body: `Dear {{if .Name}}{{.Name}}{{else}}Sir or Madam{{end}},
+
+Thank you for your feedback{{if .URL}} about {{.URL}}{{end}}. So that we can look into it, please could you reply with more detail about what you were trying to do?
+
+You told us:
+{{.Feedback}}
+
+Kind regards,
+Office for National Statistics`,
+	},
+}
+
+type compiled struct {
+	Template
+	subject *template.Template
+	body    *template.Template
+}
+
+// Templates renders replies from a fixed set of templates
+type Templates struct {
+	templates []compiled
+}
+
+// New parses the templates, checking that each has a unique name and renders
+func New(templates []Template) (*Templates, error) {
+	t := &Templates{templates: make([]compiled, 0, len(templates))}
+	seen := make(map[string]bool)
+	for i, tmpl := range templates {
+		if tmpl.Name == "" {
+			return nil, fmt.Errorf("reply template %d has no name", i+1)
+		}
+		if seen[tmpl.Name] {
+			return nil, fmt.Errorf("reply template %q is defined more than once", tmpl.Name)
+		}
+		seen[tmpl.Name] = true
+
+		c := compiled{Template: tmpl}
+		var err error
+		if c.subject, err = template.New(tmpl.Name).Option("missingkey=error").Parse(tmpl.Subject); err != nil {
+			return nil, fmt.Errorf("invalid subject in reply template %q: %w", tmpl.Name, err)
+		}
+		if c.body, err = template.New(tmpl.Name).Option("missingkey=error").Parse(tmpl.Body); err != nil {
+			return nil, fmt.Errorf("invalid body in reply template %q: %w", tmpl.Name, err)
+		}
+		if _, _, err := c.render(Data{}); err != nil {
+			return nil, fmt.Errorf("reply template %q cannot be rendered: %w", tmpl.Name, err)
+		}
+		t.templates = append(t.templates, c)
+	}
+	return t, nil
+}
+
+// LoadTemplates reads reply templates from a TOML file of [[template]] tables
+func LoadTemplates(filename string) ([]Template, error) {
+	var file struct {
+		Templates []Template `toml:"template"`
+	}
+
+	md, err := toml.DecodeFile(filename, &file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reply templates: %w", err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown reply template field %q", undecoded[0].String())
+	}
+	if len(file.Templates) == 0 {
+		return nil, errors.New("reply templates file contains no templates")
+	}
+	return file.Templates, nil
+}
+
+// List returns the templates in the order they were given
+func (t *Templates) List() []Template {
+	list := make([]Template, 0, len(t.templates))
+	for _, c := range t.templates {
+		list = append(list, c.Template)
+	}
+	return list
+}
+
+// Render returns the subject and body of the named template for the feedback in data
+func (t *Templates) Render(name string, data Data) (subject, body string, err error) {
+	for _, c := range t.templates {
+		if c.Name == name {
+			return c.render(data)
+		}
+	}
+	return "", "", fmt.Errorf("unknown reply template %q", name)
+}
+
+func (c compiled) render(data Data) (subject, body string, err error) {
+	var buf bytes.Buffer
+	if err := c.subject.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	// a subject must be one line to be used as an email header
+	subject = strings.Join(strings.Fields(buf.String()), " ")
+
+	buf.Reset()
+	if err := c.body.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	return subject, buf.String(), nil
+}
@@ -0,0 +1,109 @@
+package reply
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTemplates(t *testing.T) {
+	Convey("Given the default templates", t, func() {
+		templates, err := New(DefaultTemplates)
+		So(err, ShouldBeNil)
+
+		Convey("When a template is rendered for feedback with contact details", func() {
+			subject, body, err := templates.Render("thanks", Data{Name: "Jo", URL: "https://www.ons.gov.uk/economy", ReceivedAt: "14 Mar 2024"})
+
+			Convey("Then the feedback is filled in", func() {
+				So(err, ShouldBeNil)
+				So(subject, ShouldEqual, "Your feedback to the Office for National Statistics")
+				So(body, ShouldStartWith, "Dear Jo,\n\nThank you for your feedback about https://www.ons.gov.uk/economy on 14 Mar 2024.")
+			})
+		})
+
+		Convey("When a template is rendered for feedback without a name", func() {
+			_, body, err := templates.Render("fixed", Data{})
+
+			Convey("Then a general greeting is used", func() {
+				So(err, ShouldBeNil)
+				So(body, ShouldStartWith, "Dear Sir or Madam,\n\nThank you for letting us know about a problem. It has")
+			})
+		})
+
+		Convey("When an unknown template is rendered", func() {
+			_, _, err := templates.Render("unknown", Data{})
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When the templates are listed", func() {
+			Convey("Then they are in the order given", func() {
+				list := templates.List()
+				So(list, ShouldHaveLength, 3)
+				So(list[0].Name, ShouldEqual, "thanks")
+			})
+		})
+	})
+
+	Convey("Given templates that are not valid", t, func() {
+		testCases := []struct {
+			description string
+			templates   []Template
+		}{
+			{"a template without a name", []Template{{Subject: "s", Body: "b"}}},
+			{"a name used twice", []Template{{Name: "a", Subject: "s", Body: "b"}, {Name: "a", Subject: "s", Body: "b"}}},
+			{"a body that does not parse", []Template{{Name: "a", Subject: "s", Body: "{{.Name"}}},
+			{"a field that does not exist", []Template{{Name: "a", Subject: "{{.Phone}}", Body: "b"}}},
+		}
+
+		for _, tc := range testCases {
+			Convey("When they are given "+tc.description, func() {
+				_, err := New(tc.templates)
+
+				Convey("Then an error is returned", func() {
+					So(err, ShouldNotBeNil)
+				})
+			})
+		}
+	})
+}
+
+func TestLoadTemplates(t *testing.T) {
+	Convey("Given a reply templates file", t, func() {
+		filename := filepath.Join(t.TempDir(), "replies.toml")
+		So(os.WriteFile(filename, []byte(`
+[[template]]
+name = "census"
+title = "Census"
+subject = "Your census feedback"
+body = """Dear {{.Name}},
+Thank you."""
+`), 0o600), ShouldBeNil)
+
+		Convey("When it is loaded", func() {
+			templates, err := LoadTemplates(filename)
+
+			Convey("Then every template is read", func() {
+				So(err, ShouldBeNil)
+				So(templates, ShouldResemble, []Template{{Name: "census", Title: "Census", Subject: "Your census feedback", Body: "Dear {{.Name}},\nThank you."}})
+			})
+		})
+	})
+
+	Convey("Given a reply templates file with an unknown field", t, func() {
+		filename := filepath.Join(t.TempDir(), "replies.toml")
+		So(os.WriteFile(filename, []byte("[[template]]\nname = \"a\"\ncc = \"x\"\n"), 0o600), ShouldBeNil)
+
+		Convey("When it is loaded", func() {
+			_, err := LoadTemplates(filename)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
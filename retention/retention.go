@@ -0,0 +1,104 @@
+package retention
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/deadletter"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/gdpr"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// Author is recorded against feedback whose contact details were erased by the retention job
+const Author = "retention"
+
+// Job periodically erases the names and email addresses of stored and dead-lettered feedback older than the
+// retention period, keeping the feedback itself
+type Job struct {
+	store       *store.Store
+	deadLetters *deadletter.Store
+	period      time.Duration
+	interval    time.Duration
+	now         func() time.Time
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New creates a job that keeps contact details for period, checking every interval. dl may be nil when there is no
+// dead-letter store.
+func New(st *store.Store, dl *deadletter.Store, period, interval time.Duration) *Job {
+	return &Job{
+		store:       st,
+		deadLetters: dl,
+		period:      period,
+		interval:    interval,
+		now:         time.Now,
+	}
+}
+
+// Start runs the job in the background, straight away and then every interval, until Stop is called
+func (j *Job) Start(ctx context.Context) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.stop != nil {
+		return
+	}
+	j.stop = make(chan struct{})
+	j.done = make(chan struct{})
+
+	log.Info(ctx, "starting retention job", log.Data{"period": j.period.String(), "interval": j.interval.String()})
+	go j.run(ctx, j.stop, j.done)
+}
+
+// Stop stops the job, waiting for a purge that is in progress to finish or ctx to be done
+func (j *Job) Stop(ctx context.Context) error {
+	j.mu.Lock()
+	stop, done := j.stop, j.done
+	j.stop, j.done = nil, nil
+	j.mu.Unlock()
+	if stop == nil {
+		return nil
+	}
+
+	close(stop)
+	select {
+	case <-done:
+		log.Info(ctx, "retention job stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (j *Job) run(ctx context.Context, stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.Purge(ctx); err != nil {
+			log.Error(ctx, "retention job failed to purge contact details", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge erases the contact details of all feedback received or dead-lettered more than the retention period ago,
+// returning the number of items and dead-lettered entries changed
+func (j *Job) Purge(ctx context.Context) (int, error) {
+	cutoff := j.now().Add(-j.period)
+	ids, err := gdpr.EraseReceivedBefore(j.store, j.deadLetters, cutoff, Author)
+	if len(ids) > 0 {
+		log.Info(ctx, "retention job purged contact details", log.Data{"received_before": cutoff, "purged": len(ids), "ids": ids})
+	}
+	return len(ids), err
+}
@@ -0,0 +1,111 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/deadletter"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestJob(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given old and recent feedback with contact details", t, func() {
+		st, err := store.New(filepath.Join(t.TempDir(), "feedback"))
+		So(err, ShouldBeNil)
+		old, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "old", Name: "Jo", EmailAddress: "jo@example.com"}}, "en")
+		recent, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "recent", Name: "Sam", EmailAddress: "sam@example.com"}}, "en")
+		_, err = st.Update(old.ID, func(i *store.Item) error {
+			i.ReceivedAt = time.Now().Add(-48 * time.Hour)
+			return nil
+		})
+		So(err, ShouldBeNil)
+
+		dl := deadletter.New(filepath.Join(t.TempDir(), "dead-letter.jsonl"))
+		oldFailed, _ := dl.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "old", Name: "Jo", EmailAddress: "jo@example.com"}}, errors.New("failed"), 500)
+		recentFailed, _ := dl.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "recent", Name: "Sam", EmailAddress: "sam@example.com"}}, errors.New("failed"), 500)
+		_, err = dl.Update(func(e *deadletter.Entry) bool {
+			if e.ID != oldFailed.ID {
+				return false
+			}
+			e.FailedAt = time.Now().Add(-48 * time.Hour)
+			return true
+		})
+		So(err, ShouldBeNil)
+
+		job := New(st, dl, 24*time.Hour, time.Hour)
+
+		Convey("When contact details are purged", func() {
+			n, err := job.Purge(ctx)
+
+			Convey("Then only those older than the retention period are erased", func() {
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 2)
+
+				item, _ := st.Get(old.ID)
+				So(item.Submission.Name, ShouldBeEmpty)
+				So(item.Submission.EmailAddress, ShouldBeEmpty)
+				So(item.Submission.Feedback.Feedback, ShouldEqual, "old")
+				So(item.Erasure.Author, ShouldEqual, Author)
+
+				item, _ = st.Get(recent.ID)
+				So(item.Submission.EmailAddress, ShouldEqual, "sam@example.com")
+			})
+
+			Convey("Then dead-lettered feedback older than the retention period is erased too, and kept to be resent", func() {
+				e, _ := dl.Get(oldFailed.ID)
+				So(e.Feedback.Name, ShouldBeEmpty)
+				So(e.Feedback.EmailAddress, ShouldBeEmpty)
+				So(e.Feedback.Feedback.Feedback, ShouldEqual, "old")
+
+				e, _ = dl.Get(recentFailed.ID)
+				So(e.Feedback.EmailAddress, ShouldEqual, "sam@example.com")
+			})
+
+			Convey("Then purging again changes nothing", func() {
+				n, err := job.Purge(ctx)
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 0)
+			})
+		})
+
+		Convey("When the job is started and stopped", func() {
+			job.Start(ctx)
+			err := job.Stop(ctx)
+
+			Convey("Then it purges before stopping cleanly", func() {
+				So(err, ShouldBeNil)
+				item, _ := st.Get(old.ID)
+				So(item.Submission.EmailAddress, ShouldBeEmpty)
+			})
+
+			Convey("Then stopping it again does nothing", func() {
+				So(job.Stop(ctx), ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given old dead-lettered feedback without a feedback store", t, func() {
+		dl := deadletter.New(filepath.Join(t.TempDir(), "dead-letter.jsonl"))
+		failed, _ := dl.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "old", EmailAddress: "jo@example.com"}}, errors.New("failed"), 500)
+		job := New(nil, dl, -time.Hour, time.Hour)
+
+		Convey("When contact details are purged", func() {
+			n, err := job.Purge(ctx)
+
+			Convey("Then they are erased from the dead-lettered feedback", func() {
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 1)
+				e, _ := dl.Get(failed.ID)
+				So(e.Feedback.EmailAddress, ShouldBeEmpty)
+			})
+		})
+	})
+}
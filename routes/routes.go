@@ -5,13 +5,30 @@ import (
 	"net/http"
 
 	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/csrf"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/deadletter"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/draft"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/duplicate"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/flags"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/handlers"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/idempotency"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mailer"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/reply"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/routing"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/search"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/sentiment"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/tagging"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/taxonomy"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/variant"
 
 	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
 
 	render "github.com/ONSdigital/dis-design-system-go"
 
 	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
+	dphandlers "github.com/ONSdigital/dp-net/v3/handlers"
 	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/gorilla/mux"
 )
@@ -20,12 +37,49 @@ import (
 type Clients struct {
 	HealthCheckHandler func(w http.ResponseWriter, req *http.Request)
 	Renderer           *render.Render
+	Fragments          *render.Render
+	Drafts             *draft.Drafts
+	Wizard             *draft.Drafts
 	FeedbackAPI        *feedbackAPI.Client
+	DeadLetters        *deadletter.Store
+	Idempotency        idempotency.Store
+	Duplicates         *duplicate.Detector
+	Tagger             *tagging.Tagger
+	Taxonomy           *taxonomy.Classifier
+	Router             *routing.Router
+	Sentiment          sentiment.Scorer
+	Store              *store.Store
+	Search             *search.Index
+	Mailer             mailer.Mailer
+	Replies            *reply.Templates
+	Flags              *flags.Flags
+	Variants           *variant.Assigner
 }
 
-// Setup registers routes for the service
-func Setup(ctx context.Context, r *mux.Router, cfg *config.Config, c Clients, cacheService *cacheHelper.Helper) {
-	f := handlers.NewFeedback(c.Renderer, cacheService, cfg)
+// Setup registers routes for the service, returning the feedback handlers so their settings can be reloaded
+func Setup(ctx context.Context, r *mux.Router, cfg *config.Config, c Clients, cacheService *cacheHelper.Helper) *handlers.Feedback {
+	f := handlers.NewFeedback(handlers.Dependencies{
+		Render:       c.Renderer,
+		CacheService: cacheService,
+		Config:       cfg,
+		FeedbackAPI:  submission.NewClient(c.FeedbackAPI),
+		DeadLetters:  c.DeadLetters,
+		Idempotency:  c.Idempotency,
+		Duplicates:   c.Duplicates,
+		Tagger:       c.Tagger,
+		Taxonomy:     c.Taxonomy,
+		Router:       c.Router,
+		Sentiment:    c.Sentiment,
+		Store:        c.Store,
+		Search:       c.Search,
+		Mailer:       c.Mailer,
+		Replies:      c.Replies,
+		Fragments:    c.Fragments,
+		Drafts:       c.Drafts,
+		Wizard:       c.Wizard,
+		Flags:        c.Flags,
+		Variants:     c.Variants,
+	})
 
 	log.Info(ctx, "adding routes")
 	r.StrictSlash(true).Path("/health").HandlerFunc(c.HealthCheckHandler)
@@ -33,4 +87,37 @@ func Setup(ctx context.Context, r *mux.Router, cfg *config.Config, c Clients, ca
 	r.StrictSlash(true).Path("/feedback").Methods("POST").HandlerFunc(f.AddFeedback())
 	r.StrictSlash(true).Path("/feedback/thanks").Methods("GET").HandlerFunc(f.FeedbackThanks())
 	r.StrictSlash(true).Path("/feedback/thanks").Methods("POST").HandlerFunc(f.AddFeedback())
+	r.StrictSlash(true).Path("/feedback/widget").Methods("GET", "OPTIONS").HandlerFunc(f.Widget())
+	r.StrictSlash(true).Path("/feedback/widget.js").Methods("GET").HandlerFunc(f.WidgetScript())
+	r.StrictSlash(true).Path("/feedback/api").Methods("POST", "OPTIONS").HandlerFunc(f.SubmitFeedback())
+
+	if c.Drafts != nil {
+		r.StrictSlash(true).Path("/feedback/draft").Methods("POST").HandlerFunc(f.SaveDraft())
+		r.StrictSlash(true).Path("/feedback/draft/discard").Methods("POST").HandlerFunc(f.DiscardDraft())
+	}
+
+	if cfg.IsPublishing {
+		auth := dphandlers.Identity(cfg.APIRouterURL)
+		// routing rules can be added by reloading the config, the dry run returns 404 while there are none
+		r.StrictSlash(true).Path("/feedback/routing").Methods("GET").Handler(auth(dphandlers.CheckIdentity(f.RoutingDryRun())))
+
+		if c.Store != nil {
+			r.StrictSlash(true).Path("/feedback/export").Methods("GET").Handler(auth(dphandlers.CheckIdentity(f.ExportFeedback())))
+			r.StrictSlash(true).Path("/feedback/search").Methods("GET").Handler(auth(dphandlers.CheckIdentity(f.SearchFeedback())))
+			r.StrictSlash(true).Path("/feedback/gdpr").Methods("GET").Handler(auth(dphandlers.CheckIdentity(f.SubjectAccess())))
+			r.StrictSlash(true).Path("/feedback/gdpr/erase").Methods("POST").Handler(auth(dphandlers.CheckIdentity(csrf.Protect(f.EraseContactDetails()))))
+			r.StrictSlash(true).Path("/feedback/dashboard").Methods("GET").Handler(auth(dphandlers.CheckIdentity(f.Dashboard())))
+			r.StrictSlash(true).Path("/feedback/moderation").Methods("GET").Handler(auth(dphandlers.CheckIdentity(f.ModerationInbox())))
+			r.StrictSlash(true).Path("/feedback/moderation/{id}").Methods("GET").Handler(auth(dphandlers.CheckIdentity(f.ModerationItem())))
+			r.StrictSlash(true).Path("/feedback/moderation/{id}").Methods("POST").Handler(auth(dphandlers.CheckIdentity(csrf.Protect(f.UpdateModerationItem()))))
+			r.StrictSlash(true).Path("/feedback/moderation/{id}/history").Methods("GET").Handler(auth(dphandlers.CheckIdentity(f.ModerationHistory())))
+
+			if c.Mailer != nil && c.Replies != nil {
+				r.StrictSlash(true).Path("/feedback/moderation/{id}/reply").Methods("GET").Handler(auth(dphandlers.CheckIdentity(f.ReplyForm())))
+				r.StrictSlash(true).Path("/feedback/moderation/{id}/reply").Methods("POST").Handler(auth(dphandlers.CheckIdentity(csrf.Protect(f.SendReply()))))
+			}
+		}
+	}
+
+	return f
 }
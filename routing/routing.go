@@ -0,0 +1,179 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mapper"
+)
+
+// Rule assigns Team to feedback matching every criterion it sets. Each list matches if any of its values
+// match and a rule that sets no criteria matches all feedback.
+type Rule struct {
+	// Team is the identifier of the team that owns matching feedback
+	Team string `toml:"team"`
+	// Hosts are host names of the feedback URL, a leading *. matches any subdomain
+	Hosts []string `toml:"hosts"`
+	// PathPrefixes match the path of the feedback URL and everything below it
+	PathPrefixes []string `toml:"path_prefixes"`
+	// Services are values of the service parameter the feedback form was opened with
+	Services []string `toml:"services"`
+	// Tags are topics assigned to the feedback by tagging
+	Tags []string `toml:"tags"`
+}
+
+func (r *Rule) isCatchAll() bool {
+	return len(r.Hosts) == 0 && len(r.PathPrefixes) == 0 && len(r.Services) == 0 && len(r.Tags) == 0
+}
+
+// Request holds the details of feedback that rules are evaluated against
+type Request struct {
+	URL     string
+	Service string
+	Tags    []string
+}
+
+// Result is the outcome of routing a request, Rule is the position of the matching rule counting from 1
+type Result struct {
+	Team string `json:"team,omitempty"`
+	Rule int    `json:"rule,omitempty"`
+}
+
+// Router assigns feedback to the team of the first rule that matches it
+type Router struct {
+	rules []Rule
+}
+
+// New validates rules and creates a Router that evaluates them in order
+func New(rules []Rule) (*Router, error) {
+	r := &Router{rules: make([]Rule, 0, len(rules))}
+
+	for i, rule := range rules {
+		rule.Team = strings.TrimSpace(rule.Team)
+		if rule.Team == "" {
+			return nil, fmt.Errorf("routing rule %d has no team", i+1)
+		}
+		if i > 0 && rules[i-1].isCatchAll() {
+			return nil, fmt.Errorf("routing rule %d can never match as rule %d matches all feedback", i+1, i)
+		}
+		for _, host := range rule.Hosts {
+			if strings.TrimPrefix(host, "*.") == "" || strings.ContainsAny(host, "/:") {
+				return nil, fmt.Errorf("routing rule %d has an invalid host %q", i+1, host)
+			}
+		}
+		for _, prefix := range rule.PathPrefixes {
+			if !strings.HasPrefix(prefix, "/") {
+				return nil, fmt.Errorf("routing rule %d has a path prefix %q that does not start with /", i+1, prefix)
+			}
+		}
+		r.rules = append(r.rules, rule)
+	}
+
+	return r, nil
+}
+
+// LoadRules reads rules, in order, from a TOML file containing a [[route]] table for each rule
+func LoadRules(filename string) ([]Rule, error) {
+	var file struct {
+		Rules []Rule `toml:"route"`
+	}
+
+	md, err := toml.DecodeFile(filename, &file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routing rules: %w", err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown routing rule field %q", undecoded[0].String())
+	}
+	if len(file.Rules) == 0 {
+		return nil, errors.New("routing rules file contains no rules")
+	}
+	return file.Rules, nil
+}
+
+// Route returns the team of the first rule matching req, or an empty Result if none match
+func (r *Router) Route(req Request) Result {
+	host, urlPath := splitURL(req.URL)
+
+	for i := range r.rules {
+		rule := &r.rules[i]
+		if matchHost(rule.Hosts, host) && matchPath(rule.PathPrefixes, urlPath) &&
+			matchAny(rule.Services, req.Service) && matchTags(rule.Tags, req.Tags) {
+			return Result{Team: rule.Team, Rule: i + 1}
+		}
+	}
+	return Result{}
+}
+
+func matchHost(hosts []string, host string) bool {
+	if len(hosts) == 0 {
+		return true
+	}
+	for _, h := range hosts {
+		h = strings.ToLower(h)
+		if suffix, ok := strings.CutPrefix(h, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPath(prefixes []string, urlPath string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	if urlPath == "" {
+		return false
+	}
+	for _, prefix := range prefixes {
+		prefix = strings.ToLower(strings.TrimSuffix(prefix, "/"))
+		if prefix == "" || urlPath == prefix || strings.HasPrefix(urlPath, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchTags(ruleTags, tags []string) bool {
+	if len(ruleTags) == 0 {
+		return true
+	}
+	for _, tag := range tags {
+		if matchAny(ruleTags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitURL returns the lower-cased host and cleaned path of rawURL, which, like the form's URL, may have no scheme
+func splitURL(rawURL string) (host, urlPath string) {
+	u, err := url.Parse(mapper.NormaliseURL(strings.TrimSpace(rawURL)))
+	if err != nil {
+		return "", ""
+	}
+	if u.Path != "" {
+		urlPath = strings.ToLower(path.Clean(u.Path))
+	}
+	return strings.ToLower(u.Hostname()), urlPath
+}
@@ -0,0 +1,124 @@
+package routing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRoute(t *testing.T) {
+	Convey("Given a router with ordered rules", t, func() {
+		router, err := New([]Rule{
+			{Team: "developer-hub", Hosts: []string{"developer.ons.gov.uk"}},
+			{Team: "developer-hub", Services: []string{"dev"}},
+			{Team: "census", PathPrefixes: []string{"/census/"}},
+			{Team: "datasets", PathPrefixes: []string{"/datasets"}, Tags: []string{"data error", "download"}},
+			{Team: "search", Tags: []string{"search"}},
+			{Team: "publishing", Hosts: []string{"*.ons.gov.uk"}, PathPrefixes: []string{"/releases"}},
+			{Team: "web"},
+		})
+		So(err, ShouldBeNil)
+
+		testCases := []struct {
+			description string
+			req         Request
+			expected    Result
+		}{
+			{"a URL on the developer hub", Request{URL: "https://developer.ons.gov.uk/dataset/cpih01"}, Result{Team: "developer-hub", Rule: 1}},
+			{"the developer service form", Request{Service: "DEV"}, Result{Team: "developer-hub", Rule: 2}},
+			{"a census page", Request{URL: "https://www.ons.gov.uk/census/maps"}, Result{Team: "census", Rule: 3}},
+			{"the census landing page", Request{URL: "https://www.ons.gov.uk/Census"}, Result{Team: "census", Rule: 3}},
+			{"a dataset download problem", Request{URL: "https://www.ons.gov.uk/datasets/cpih01", Tags: []string{"download"}}, Result{Team: "datasets", Rule: 4}},
+			{"a dataset page without a matching tag", Request{URL: "https://www.ons.gov.uk/datasets/cpih01", Tags: []string{"accessibility"}}, Result{Team: "web", Rule: 7}},
+			{"a search problem on a dataset page", Request{URL: "https://www.ons.gov.uk/datasets/cpih01", Tags: []string{"search"}}, Result{Team: "search", Rule: 5}},
+			{"a subdomain release page", Request{URL: "https://beta.ons.gov.uk/releases/gdp"}, Result{Team: "publishing", Rule: 6}},
+			{"a census page without a scheme", Request{URL: "www.ons.gov.uk/census/maps"}, Result{Team: "census", Rule: 3}},
+			{"a developer hub URL without a scheme", Request{URL: "developer.ons.gov.uk/dataset/cpih01"}, Result{Team: "developer-hub", Rule: 1}},
+			{"a page sharing a prefix with a rule", Request{URL: "https://www.ons.gov.uk/censusarchive"}, Result{Team: "web", Rule: 7}},
+			{"whole site feedback", Request{}, Result{Team: "web", Rule: 7}},
+		}
+
+		for _, tc := range testCases {
+			Convey("When routing "+tc.description, func() {
+				So(router.Route(tc.req), ShouldResemble, tc.expected)
+			})
+		}
+	})
+
+	Convey("Given a router without a catch-all rule", t, func() {
+		router, err := New([]Rule{{Team: "census", PathPrefixes: []string{"/census"}}})
+		So(err, ShouldBeNil)
+
+		Convey("When feedback matches no rule", func() {
+			result := router.Route(Request{URL: "https://www.ons.gov.uk/economy"})
+
+			Convey("Then no team is assigned", func() {
+				So(result, ShouldResemble, Result{})
+			})
+		})
+	})
+}
+
+func TestNew(t *testing.T) {
+	Convey("Given rules that are not valid", t, func() {
+		testCases := []struct {
+			description string
+			rules       []Rule
+		}{
+			{"a rule without a team", []Rule{{PathPrefixes: []string{"/census"}}}},
+			{"a relative path prefix", []Rule{{Team: "census", PathPrefixes: []string{"census"}}}},
+			{"a host containing a scheme", []Rule{{Team: "dev", Hosts: []string{"https://developer.ons.gov.uk"}}}},
+			{"a rule after a catch-all", []Rule{{Team: "web"}, {Team: "census", PathPrefixes: []string{"/census"}}}},
+		}
+
+		for _, tc := range testCases {
+			Convey("When creating a router with "+tc.description, func() {
+				_, err := New(tc.rules)
+
+				Convey("Then an error is returned", func() {
+					So(err, ShouldNotBeNil)
+				})
+			})
+		}
+	})
+}
+
+func TestLoadRules(t *testing.T) {
+	Convey("Given a routing rules file", t, func() {
+		filename := filepath.Join(t.TempDir(), "routing.toml")
+		err := os.WriteFile(filename, []byte(`
+[[route]]
+team = "census"
+path_prefixes = ["/census"]
+
+[[route]]
+team = "web"
+`), 0o600)
+		So(err, ShouldBeNil)
+
+		Convey("When the rules are loaded", func() {
+			rules, err := LoadRules(filename)
+
+			Convey("Then they are read in order", func() {
+				So(err, ShouldBeNil)
+				So(rules, ShouldResemble, []Rule{{Team: "census", PathPrefixes: []string{"/census"}}, {Team: "web"}})
+			})
+		})
+	})
+
+	Convey("Given a routing rules file with a misspelt field", t, func() {
+		filename := filepath.Join(t.TempDir(), "routing.toml")
+		err := os.WriteFile(filename, []byte("[[route]]\nteam = \"census\"\nprefixes = [\"/census\"]\n"), 0o600)
+		So(err, ShouldBeNil)
+
+		Convey("When the rules are loaded", func() {
+			_, err := LoadRules(filename)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
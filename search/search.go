@@ -0,0 +1,176 @@
+package search
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// fieldGap separates the positions of words in different fields so that a phrase never spans two fields
+const fieldGap = 1000
+
+// Query is a parsed search. Every term and phrase must be found for feedback to match.
+type Query struct {
+	Terms   []string
+	Phrases [][]string
+}
+
+// IsEmpty is true when the query has nothing to search for
+func (q Query) IsEmpty() bool {
+	return len(q.Terms) == 0 && len(q.Phrases) == 0
+}
+
+// ParseQuery splits a search into words and "quoted phrases". An unterminated quote runs to the end of the search.
+func ParseQuery(text string) Query {
+	var q Query
+	for i, part := range strings.Split(text, `"`) {
+		words := Tokens(part)
+		if i%2 == 0 {
+			q.Terms = append(q.Terms, words...)
+			continue
+		}
+		switch len(words) {
+		case 0:
+		case 1:
+			q.Terms = append(q.Terms, words[0])
+		default:
+			q.Phrases = append(q.Phrases, words)
+		}
+	}
+	return q
+}
+
+// Tokens splits text into lower case words of letters and digits
+func Tokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Index is an in-memory inverted index of the description, URL and tags of stored feedback
+type Index struct {
+	mu sync.RWMutex
+	// postings holds the positions of each word in each item, by word then item ID
+	postings map[string]map[string][]int
+	size     int
+}
+
+// New creates an empty index
+func New() *Index {
+	return &Index{postings: make(map[string]map[string][]int)}
+}
+
+// Build creates an index of all the feedback in st
+func Build(ctx context.Context, st *store.Store) (*Index, error) {
+	idx := New()
+	if err := st.Walk(store.Filter{}, func(item *store.Item) error {
+		idx.Add(item)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	log.Info(ctx, "feedback search index built", log.Data{"items": idx.Len()})
+	return idx, nil
+}
+
+// Add indexes an item of feedback
+func (idx *Index) Add(item *store.Item) {
+	if item.Submission == nil {
+		return
+	}
+
+	fields := []string{item.Submission.Feedback.Feedback, item.Submission.OnsURL}
+	fields = append(fields, item.Submission.Tags...)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for f, field := range fields {
+		for i, word := range Tokens(field) {
+			docs, ok := idx.postings[word]
+			if !ok {
+				docs = make(map[string][]int)
+				idx.postings[word] = docs
+			}
+			docs[item.ID] = append(docs[item.ID], f*fieldGap+i)
+		}
+	}
+	idx.size++
+}
+
+// Len returns the number of items indexed
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.size
+}
+
+// Search returns the IDs of the items matching the query, newest first
+func (idx *Index) Search(q Query) []string {
+	if q.IsEmpty() {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var words []string
+	words = append(words, q.Terms...)
+	for _, phrase := range q.Phrases {
+		words = append(words, phrase...)
+	}
+
+	// start from the rarest word so the fewest items are checked
+	slices.SortFunc(words, func(a, b string) int {
+		return len(idx.postings[a]) - len(idx.postings[b])
+	})
+
+	var ids []string
+	for id := range idx.postings[words[0]] {
+		if idx.matches(id, words[1:], q.Phrases) {
+			ids = append(ids, id)
+		}
+	}
+
+	// ids sort in the order feedback was received
+	slices.Sort(ids)
+	slices.Reverse(ids)
+	return ids
+}
+
+func (idx *Index) matches(id string, words []string, phrases [][]string) bool {
+	for _, word := range words {
+		if _, ok := idx.postings[word][id]; !ok {
+			return false
+		}
+	}
+	for _, phrase := range phrases {
+		if !idx.hasPhrase(id, phrase) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasPhrase is true when the words of the phrase appear one after another in the item
+func (idx *Index) hasPhrase(id string, phrase []string) bool {
+	for _, start := range idx.postings[phrase[0]][id] {
+		found := true
+		for i, word := range phrase[1:] {
+			if !slices.Contains(idx.postings[word][id], start+i+1) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
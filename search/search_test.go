@@ -0,0 +1,84 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseQuery(t *testing.T) {
+	Convey("When searches are parsed", t, func() {
+		Convey("Then words and quoted phrases are separated", func() {
+			So(ParseQuery(`CPI "consumer price index" mm23`), ShouldResemble, Query{
+				Terms:   []string{"cpi", "mm23"},
+				Phrases: [][]string{{"consumer", "price", "index"}},
+			})
+		})
+
+		Convey("Then a quoted word is a term and an unterminated quote runs to the end", func() {
+			So(ParseQuery(`"CPI" "price index`), ShouldResemble, Query{
+				Terms:   []string{"cpi"},
+				Phrases: [][]string{{"price", "index"}},
+			})
+		})
+
+		Convey("Then punctuation alone is an empty search", func() {
+			So(ParseQuery(` "" - `).IsEmpty(), ShouldBeTrue)
+		})
+	})
+}
+
+func TestIndex(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a store of feedback", t, func() {
+		st, err := store.New(filepath.Join(t.TempDir(), "feedback"))
+		So(err, ShouldBeNil)
+		add := func(text, url string, tags ...string) string {
+			item, err := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: text, OnsURL: url}, Tags: tags}, "en")
+			So(err, ShouldBeNil)
+			return item.ID
+		}
+		cpi := add("The CPI figure for March looks wrong", "https://www.ons.gov.uk/economy/inflationandpriceindices")
+		index := add("Where is the consumer price index?", "", "search")
+		dataset := add("Can't download cpih01", "https://www.ons.gov.uk/datasets/cpih01")
+		other := add("Price changes to the index page", "https://www.ons.gov.uk/consumer")
+
+		Convey("When the index is built from the store", func() {
+			idx, err := Build(ctx, st)
+			So(err, ShouldBeNil)
+
+			Convey("Then every item is indexed", func() {
+				So(idx.Len(), ShouldEqual, 4)
+			})
+
+			testCases := []struct {
+				description string
+				query       string
+				expected    []string
+			}{
+				{"a word, regardless of case", "cpi", []string{cpi}},
+				{"a dataset ID in the description or URL", "CPIH01", []string{dataset}},
+				{"a word in the URL", "inflationandpriceindices", []string{cpi}},
+				{"a tag", "search", []string{index}},
+				{"several words", "price index", []string{other, index}},
+				{"a phrase", `"consumer price index"`, []string{index}},
+				{"a phrase across fields", `"page consumer"`, nil},
+				{"a word that is not indexed", "gdp", nil},
+			}
+
+			for _, tc := range testCases {
+				Convey("When searching for "+tc.description, func() {
+					Convey("Then the matching items are returned, newest first", func() {
+						So(idx.Search(ParseQuery(tc.query)), ShouldResemble, tc.expected)
+					})
+				})
+			}
+		})
+	})
+}
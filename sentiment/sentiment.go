@@ -0,0 +1,263 @@
+package sentiment
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// fallbackLang is the language whose lexicon is used after the lexicon for the page language
+const fallbackLang = "en"
+
+// MaxWeight is the largest weight, positive or negative, that a word can be given
+const MaxWeight = 4
+
+const (
+	// negateWindow is how many words after a negator have their sentiment reversed
+	negateWindow = 3
+	// negateFactor dampens reversed sentiment, as "not good" is less negative than "bad"
+	negateFactor = -0.5
+	// alpha controls how quickly the total weight of the text approaches a score of 1 or -1
+	alpha = 15
+)
+
+//go:embed lexicons/*.txt
+var lexicons embed.FS
+
+// Scorer rates the sentiment of feedback from -1 (negative) through 0 (neutral) to 1 (positive)
+type Scorer interface {
+	Score(lang, text string) float64
+}
+
+// Lexicon holds the weights of sentiment bearing words and the words that modify them
+type Lexicon struct {
+	Weights  map[string]float64
+	Negators map[string]bool
+	Boosters map[string]float64
+}
+
+// ParseLexicon reads a lexicon with a "<word> <weight>" line for each word. Lines of the form "@negate <word>"
+// and "@boost <word> <multiplier>" declare modifiers, blank lines and lines starting with # are ignored.
+func ParseLexicon(r io.Reader) (*Lexicon, error) {
+	l := &Lexicon{
+		Weights:  make(map[string]float64),
+		Negators: make(map[string]bool),
+		Boosters: make(map[string]float64),
+	}
+	seen := make(map[string]int)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		directive := ""
+		if strings.HasPrefix(fields[0], "@") {
+			directive, fields = fields[0], fields[1:]
+		}
+
+		var err error
+		switch directive {
+		case "":
+			err = l.addWeight(fields)
+		case "@negate":
+			err = l.addNegator(fields)
+		case "@boost":
+			err = l.addBooster(fields)
+		default:
+			err = fmt.Errorf("unknown directive %q", directive)
+		}
+		if err == nil {
+			if first, ok := seen[fields[0]]; ok {
+				err = fmt.Errorf("%q is already defined on line %d", fields[0], first)
+			}
+			seen[fields[0]] = n
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid lexicon line %d: %w", n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read lexicon: %w", err)
+	}
+	return l, nil
+}
+
+func (l *Lexicon) addWeight(fields []string) error {
+	if len(fields) != 2 {
+		return errors.New("expected a word and a weight")
+	}
+	if err := validateWord(fields[0]); err != nil {
+		return err
+	}
+	weight, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || math.Abs(weight) > MaxWeight {
+		return fmt.Errorf("weight of %q must be a number from -%d to %d", fields[0], MaxWeight, MaxWeight)
+	}
+	l.Weights[fields[0]] = weight
+	return nil
+}
+
+func (l *Lexicon) addNegator(fields []string) error {
+	if len(fields) != 1 {
+		return errors.New("expected a single word to negate with")
+	}
+	if err := validateWord(fields[0]); err != nil {
+		return err
+	}
+	l.Negators[fields[0]] = true
+	return nil
+}
+
+func (l *Lexicon) addBooster(fields []string) error {
+	if len(fields) != 2 {
+		return errors.New("expected a word and a multiplier")
+	}
+	if err := validateWord(fields[0]); err != nil {
+		return err
+	}
+	multiplier, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || multiplier <= 0 {
+		return fmt.Errorf("multiplier of %q must be a positive number", fields[0])
+	}
+	l.Boosters[fields[0]] = multiplier
+	return nil
+}
+
+// validateWord checks that word is written as it will appear once feedback text has been split into words
+func validateWord(word string) error {
+	if words := Words(word); len(words) != 1 || words[0] != word {
+		return fmt.Errorf("%q must be a single lower case word without punctuation", word)
+	}
+	return nil
+}
+
+// LexiconScorer scores text using the lexicon for its language, falling back to the English lexicon
+type LexiconScorer struct {
+	lexicons map[string]*Lexicon
+}
+
+// NewLexiconScorer creates a LexiconScorer from lexicons keyed by language
+func NewLexiconScorer(lexicons map[string]*Lexicon) *LexiconScorer {
+	return &LexiconScorer{lexicons: lexicons}
+}
+
+// NewDefaultScorer creates a LexiconScorer using the built-in English and Welsh lexicons
+func NewDefaultScorer() (*LexiconScorer, error) {
+	entries, err := lexicons.ReadDir("lexicons")
+	if err != nil {
+		return nil, err
+	}
+
+	byLang := make(map[string]*Lexicon, len(entries))
+	for _, entry := range entries {
+		file, err := lexicons.Open("lexicons/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		l, err := ParseLexicon(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		byLang[strings.TrimSuffix(entry.Name(), ".txt")] = l
+	}
+	return NewLexiconScorer(byLang), nil
+}
+
+// Score sums the weights of the words in text, adjusted by any negators and boosters before them, and
+// scales the total to between -1 and 1
+func (s *LexiconScorer) Score(lang, text string) float64 {
+	chain := make([]*Lexicon, 0, 2)
+	if l, ok := s.lexicons[lang]; ok {
+		chain = append(chain, l)
+	}
+	if l, ok := s.lexicons[fallbackLang]; ok && lang != fallbackLang {
+		chain = append(chain, l)
+	}
+
+	total := 0.0
+	boost := 1.0
+	negated := 0
+	for _, word := range Words(text) {
+		if isNegator(chain, word) {
+			negated = negateWindow
+			continue
+		}
+		if m := booster(chain, word); m != 0 {
+			boost = m
+			continue
+		}
+
+		weight := weightOf(chain, word) * boost
+		if negated > 0 {
+			weight *= negateFactor
+			negated--
+		}
+		total += weight
+		boost = 1
+	}
+
+	score := total / math.Sqrt(total*total+alpha)
+	return math.Round(score*1000) / 1000
+}
+
+func isNegator(chain []*Lexicon, word string) bool {
+	for _, l := range chain {
+		if l.Negators[word] {
+			return true
+		}
+	}
+	return false
+}
+
+func booster(chain []*Lexicon, word string) float64 {
+	for _, l := range chain {
+		if m, ok := l.Boosters[word]; ok {
+			return m
+		}
+	}
+	return 0
+}
+
+func weightOf(chain []*Lexicon, word string) float64 {
+	for _, l := range chain {
+		if w, ok := l.Weights[word]; ok {
+			return w
+		}
+	}
+	return 0
+}
+
+// Words splits text into lower case words, dropping apostrophes so that "don't" becomes "dont"
+func Words(text string) []string {
+	var words []string
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			words = append(words, b.String())
+			b.Reset()
+		}
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == '\'' || r == '’':
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			b.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
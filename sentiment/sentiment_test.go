@@ -0,0 +1,129 @@
+package sentiment
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseLexicon(t *testing.T) {
+	Convey("Given a lexicon with weights and modifiers", t, func() {
+		lexicon := `
+# a comment
+@negate not
+@boost very 1.5
+
+good 2
+bad -2.5
+`
+
+		Convey("When it is parsed", func() {
+			l, err := ParseLexicon(strings.NewReader(lexicon))
+
+			Convey("Then every entry is read", func() {
+				So(err, ShouldBeNil)
+				So(l.Weights, ShouldResemble, map[string]float64{"good": 2, "bad": -2.5})
+				So(l.Negators, ShouldResemble, map[string]bool{"not": true})
+				So(l.Boosters, ShouldResemble, map[string]float64{"very": 1.5})
+			})
+		})
+	})
+
+	Convey("Given lexicons that are not valid", t, func() {
+		testCases := []struct {
+			description string
+			lexicon     string
+			expected    string
+		}{
+			{"a word without a weight", "good", "line 1: expected a word and a weight"},
+			{"a weight that is not a number", "good great", "line 1"},
+			{"a weight that is too large", "good 5", "line 1"},
+			{"a word in upper case", "Good 2", "line 1"},
+			{"a phrase", "not good -1", "line 1"},
+			{"a word defined twice", "good 2\n\ngood 3", `line 3: "good" is already defined on line 1`},
+			{"a word that is both a weight and a modifier", "@negate not\nnot -1", "line 2"},
+			{"a booster without a multiplier", "@boost very", "line 1"},
+			{"a booster with a negative multiplier", "@boost very -1", "line 1"},
+			{"an unknown directive", "@ignore the", `line 1: unknown directive "@ignore"`},
+		}
+
+		for _, tc := range testCases {
+			Convey("When parsing "+tc.description, func() {
+				_, err := ParseLexicon(strings.NewReader(tc.lexicon))
+
+				Convey("Then an error identifying the line is returned", func() {
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldContainSubstring, tc.expected)
+				})
+			})
+		}
+	})
+}
+
+func TestScore(t *testing.T) {
+	Convey("Given the default scorer", t, func() {
+		scorer, err := NewDefaultScorer()
+		So(err, ShouldBeNil)
+
+		Convey("When neutral text is scored", func() {
+			Convey("Then the score is 0", func() {
+				So(scorer.Score("en", "The CPI bulletin for March"), ShouldEqual, 0)
+				So(scorer.Score("en", ""), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When positive and negative text is scored", func() {
+			positive := scorer.Score("en", "Really useful page, thanks")
+			negative := scorer.Score("en", "The chart is confusing and the figures are wrong")
+
+			Convey("Then the scores have the expected sign and are within range", func() {
+				So(positive, ShouldBeBetween, 0, 1)
+				So(negative, ShouldBeBetween, -1, 0)
+			})
+		})
+
+		Convey("When a word is negated", func() {
+			Convey("Then its sentiment is reversed and weakened", func() {
+				good := scorer.Score("en", "this is good")
+				notGood := scorer.Score("en", "this isn't good")
+				So(notGood, ShouldBeLessThan, 0)
+				So(-notGood, ShouldBeLessThan, good)
+			})
+		})
+
+		Convey("When a word is boosted", func() {
+			Convey("Then its sentiment is strengthened", func() {
+				So(scorer.Score("en", "very slow"), ShouldBeLessThan, scorer.Score("en", "slow"))
+				So(scorer.Score("en", "slightly slow"), ShouldBeGreaterThan, scorer.Score("en", "slow"))
+			})
+		})
+
+		Convey("When Welsh text is scored", func() {
+			Convey("Then the Welsh lexicon is used", func() {
+				So(scorer.Score("cy", "Tudalen ddefnyddiol iawn, diolch"), ShouldBeGreaterThan, 0)
+				So(scorer.Score("cy", "Mae'r siart yn ddryslyd ac yn anghywir"), ShouldBeLessThan, 0)
+				So(scorer.Score("cy", "Nid yw'n dda"), ShouldBeLessThan, 0)
+			})
+
+			Convey("Then English words are also scored", func() {
+				So(scorer.Score("cy", "terrible"), ShouldBeLessThan, 0)
+			})
+		})
+
+		Convey("When Welsh text is scored as English", func() {
+			Convey("Then the Welsh lexicon is not used", func() {
+				So(scorer.Score("en", "gwych"), ShouldEqual, 0)
+			})
+		})
+	})
+}
+
+func TestWords(t *testing.T) {
+	Convey("When text is split into words", t, func() {
+		Convey("Then it is lower cased, punctuation separates words and apostrophes are dropped", func() {
+			So(Words("Don't SHOUT, it’s rude!"), ShouldResemble, []string{"dont", "shout", "its", "rude"})
+			So(Words("Mae'r ŵyl yn 2024"), ShouldResemble, []string{"maer", "ŵyl", "yn", "2024"})
+		})
+	})
+}
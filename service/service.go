@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 
 	render "github.com/ONSdigital/dis-design-system-go"
 	"github.com/ONSdigital/dis-design-system-go/middleware/renderror"
@@ -10,7 +11,25 @@ import (
 	cacheHelper "github.com/ONSdigital/dp-frontend-cache-helper/pkg/navigation/helper"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/assets"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/deadletter"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/draft"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/duplicate"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/flags"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/fragment"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/handlers"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/idempotency"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mailer"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/reload"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/reply"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/retention"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/routes"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/routing"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/search"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/sentiment"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/tagging"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/taxonomy"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/variant"
 	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/gorilla/mux"
 	"github.com/justinas/alice"
@@ -32,6 +51,8 @@ type Service struct {
 	HealthCheck HealthChecker
 	Server      HTTPServer
 	ServiceList *ExternalServiceList
+	Retention   *retention.Job
+	Reloader    *reload.Reloader
 }
 
 // New creates a new service
@@ -52,9 +73,109 @@ func (svc *Service) Init(ctx context.Context, cfg *config.Config, serviceList *E
 	// Initialise clients
 	clients := routes.Clients{
 		Renderer:    render.NewWithDefaultClient(assets.Asset, assets.AssetNames, cfg.PatternLibraryAssetsPath, cfg.SiteDomain),
+		Fragments:   fragment.NewRenderer(assets.Asset, assets.AssetNames, cfg.PatternLibraryAssetsPath, cfg.SiteDomain),
 		FeedbackAPI: feedbackAPI.NewWithHealthClient(routerHealthClient),
+		Idempotency: idempotency.NewMemoryStore(cfg.IdempotencyCacheSize, cfg.IdempotencyWindow),
 	}
 
+	if cfg.DeadLetterPath != "" {
+		clients.DeadLetters = deadletter.New(cfg.DeadLetterPath)
+	}
+
+	// drafts are kept in cookies sealed with COOKIE_SECRET, so they are only kept once it has been set
+	if cfg.CookieSecret != "" && cfg.DraftExpiry > 0 {
+		// like the page renderer, cookies are only sent over https away from localhost
+		if clients.Drafts, err = draft.New(draft.CookieName, cfg.CookieSecret, cfg.DraftExpiry, !strings.Contains(cfg.SiteDomain, "localhost")); err != nil {
+			log.Error(ctx, "failed to create draft store", err)
+			return err
+		}
+	}
+
+	if cfg.EnableFeedbackWizard {
+		if clients.Wizard, err = draft.New(draft.WizardCookieName, cfg.CookieSecret, cfg.WizardSessionExpiry, !strings.Contains(cfg.SiteDomain, "localhost")); err != nil {
+			log.Error(ctx, "failed to create feedback wizard session store", err)
+			return err
+		}
+	}
+
+	if cfg.DuplicateAction != duplicate.ActionOff {
+		clients.Duplicates, err = duplicate.NewDetector(duplicate.Config{
+			Action:      cfg.DuplicateAction,
+			Threshold:   cfg.DuplicateThreshold,
+			Window:      cfg.DuplicateWindow,
+			HistorySize: cfg.DuplicateHistorySize,
+		})
+		if err != nil {
+			log.Error(ctx, "failed to create duplicate detector", err)
+			return err
+		}
+	}
+
+	if cfg.FeedbackStorePath != "" {
+		if clients.Store, err = store.New(cfg.FeedbackStorePath); err != nil {
+			log.Error(ctx, "failed to create feedback store", err, log.Data{"path": cfg.FeedbackStorePath})
+			return err
+		}
+		if clients.Search, err = search.Build(ctx, clients.Store); err != nil {
+			log.Error(ctx, "failed to build feedback search index", err)
+			return err
+		}
+	}
+
+	if cfg.ContactRetentionPeriod > 0 && (clients.Store != nil || clients.DeadLetters != nil) {
+		if cfg.RetentionInterval <= 0 {
+			err = errors.New("RETENTION_INTERVAL must be positive to erase old contact details")
+			log.Error(ctx, "failed to create retention job", err)
+			return err
+		}
+		svc.Retention = retention.New(clients.Store, clients.DeadLetters, cfg.ContactRetentionPeriod, cfg.RetentionInterval)
+	}
+
+	if clients.Store != nil && cfg.MailHost != "" {
+		if cfg.FeedbackFrom == "" {
+			err = errors.New("FEEDBACK_FROM must be set to reply to feedback")
+			log.Error(ctx, "failed to create mailer", err)
+			return err
+		}
+		clients.Mailer = mailer.NewSMTP(cfg.MailHost, cfg.MailPort, cfg.MailUser, cfg.MailPassword)
+
+		replyTemplates := reply.DefaultTemplates
+		if cfg.ReplyTemplatesPath != "" {
+			if replyTemplates, err = reply.LoadTemplates(cfg.ReplyTemplatesPath); err != nil {
+				log.Error(ctx, "failed to load reply templates", err, log.Data{"path": cfg.ReplyTemplatesPath})
+				return err
+			}
+		}
+		if clients.Replies, err = reply.New(replyTemplates); err != nil {
+			log.Error(ctx, "failed to create reply templates", err)
+			return err
+		}
+	}
+
+	if clients.Tagger, err = newTagger(ctx, cfg); err != nil {
+		return err
+	}
+
+	if clients.Sentiment, err = sentiment.NewDefaultScorer(); err != nil {
+		log.Error(ctx, "failed to create sentiment scorer", err)
+		return err
+	}
+
+	if clients.Router, err = newRouter(ctx, cfg); err != nil {
+		return err
+	}
+
+	if clients.Flags, err = newFlags(ctx, cfg); err != nil {
+		return err
+	}
+
+	formVariants, err := variant.Parse(cfg.FormVariants)
+	if err != nil {
+		log.Error(ctx, "failed to parse form variants", err)
+		return err
+	}
+	clients.Variants = variant.New(formVariants, !strings.Contains(cfg.SiteDomain, "localhost"))
+
 	// Get healthcheck with checkers
 	svc.HealthCheck, err = serviceList.GetHealthCheck(cfg, BuildTime, GitCommit, Version)
 	if err != nil {
@@ -83,6 +204,9 @@ func (svc *Service) Init(ctx context.Context, cfg *config.Config, serviceList *E
 
 	cacheService, _ := cacheHelper.Init(ctx, cacheConfig)
 	cacheService.RunUpdates(ctx, svcErrors)
+	if cacheService.Clienter != nil {
+		clients.Taxonomy = taxonomy.NewClassifier(cacheService)
+	}
 
 	// Initialise router
 	r := mux.NewRouter()
@@ -93,12 +217,82 @@ func (svc *Service) Init(ctx context.Context, cfg *config.Config, serviceList *E
 		renderror.Handler(clients.Renderer),
 	}
 	newAlice := alice.New(middleware...).Then(r)
-	routes.Setup(ctx, r, cfg, clients, cacheService)
+	f := routes.Setup(ctx, r, cfg, clients, cacheService)
 	svc.Server = serviceList.GetHTTPServer(cfg.BindAddr, newAlice)
 
+	svc.Reloader = reload.New(cfg, func(ctx context.Context, next *config.Config) error {
+		if err := next.Validate(assets.Asset); err != nil {
+			return err
+		}
+		tagger, err := newTagger(ctx, next)
+		if err != nil {
+			return err
+		}
+		router, err := newRouter(ctx, next)
+		if err != nil {
+			return err
+		}
+		featureFlags, err := newFlags(ctx, next)
+		if err != nil {
+			return err
+		}
+		f.Reload(handlers.Reloadable{Config: next, Tagger: tagger, Router: router, Flags: featureFlags})
+		return nil
+	})
+	if err = svc.HealthCheck.AddCheck("config reload", svc.Reloader.Checker); err != nil {
+		log.Error(ctx, "failed to add config reload checker", err)
+		return err
+	}
+
 	return nil
 }
 
+// newTagger creates the tagger using the rules in cfg, or the built-in rules if there are none
+func newTagger(ctx context.Context, cfg *config.Config) (*tagging.Tagger, error) {
+	taggingRules := tagging.DefaultRules
+	if cfg.TaggingRulesPath != "" {
+		var err error
+		if taggingRules, err = tagging.LoadRules(cfg.TaggingRulesPath); err != nil {
+			log.Error(ctx, "failed to load tagging rules", err, log.Data{"path": cfg.TaggingRulesPath})
+			return nil, err
+		}
+	}
+	tagger, err := tagging.New(taggingRules)
+	if err != nil {
+		log.Error(ctx, "failed to create tagger", err)
+		return nil, err
+	}
+	return tagger, nil
+}
+
+// newRouter creates the router using the rules in cfg, returning nil if there are none
+func newRouter(ctx context.Context, cfg *config.Config) (*routing.Router, error) {
+	if cfg.RoutingRulesPath == "" {
+		return nil, nil
+	}
+	routingRules, err := routing.LoadRules(cfg.RoutingRulesPath)
+	if err != nil {
+		log.Error(ctx, "failed to load routing rules", err, log.Data{"path": cfg.RoutingRulesPath})
+		return nil, err
+	}
+	router, err := routing.New(routingRules)
+	if err != nil {
+		log.Error(ctx, "failed to create router", err)
+		return nil, err
+	}
+	return router, nil
+}
+
+// newFlags creates the feature flags defined in cfg
+func newFlags(ctx context.Context, cfg *config.Config) (*flags.Flags, error) {
+	flagList, err := flags.Parse(cfg.FeatureFlags)
+	if err != nil {
+		log.Error(ctx, "failed to parse feature flags", err)
+		return nil, err
+	}
+	return flags.New(flagList, cfg.FeatureFlagOverrides, !strings.Contains(cfg.SiteDomain, "localhost")), nil
+}
+
 // Run starts an initialised service
 func (svc *Service) Run(ctx context.Context, svcErrors chan error) {
 	log.Info(ctx, "Starting service", log.Data{"config": svc.Config})
@@ -106,6 +300,16 @@ func (svc *Service) Run(ctx context.Context, svcErrors chan error) {
 	// Start healthcheck
 	svc.HealthCheck.Start(ctx)
 
+	// Start erasing old contact details
+	if svc.Retention != nil {
+		svc.Retention.Start(ctx)
+	}
+
+	// Start reloading the config when it changes
+	if svc.Reloader != nil {
+		svc.Reloader.Start(ctx)
+	}
+
 	// Start HTTP server
 	log.Info(ctx, "Starting server")
 	go func() {
@@ -134,6 +338,22 @@ func (svc *Service) Close(ctx context.Context) error {
 			log.Error(ctx, "failed to shutdown http server", err)
 			hasShutdownError = true
 		}
+
+		// stop reloading the config once no more requests will use it
+		if svc.Reloader != nil {
+			if err := svc.Reloader.Stop(ctx); err != nil {
+				log.Error(ctx, "failed to stop config reloader", err)
+				hasShutdownError = true
+			}
+		}
+
+		// stop the retention job once nothing else can change the store
+		if svc.Retention != nil {
+			if err := svc.Retention.Stop(ctx); err != nil {
+				log.Error(ctx, "failed to stop retention job", err)
+				hasShutdownError = true
+			}
+		}
 	}()
 
 	// wait for shutdown success (via cancel) or failure (timeout)
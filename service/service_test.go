@@ -4,14 +4,19 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/ONSdigital/dp-api-clients-go/v2/health"
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/config"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/retention"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/service"
 	"github.com/ONSdigital/dp-frontend-feedback-controller/service/mocks"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/store"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -119,7 +124,9 @@ func TestInitSuccess(t *testing.T) {
 
 						Convey("And the checkers are registered and the healthcheck", func() {
 							So(mockServiceList.HealthCheck, ShouldBeTrue)
-							So(len(hcMock.AddCheckCalls()), ShouldEqual, 1)
+							So(len(hcMock.AddCheckCalls()), ShouldEqual, 2)
+							So(hcMock.AddCheckCalls()[1].Name, ShouldEqual, "config reload")
+							So(svc.Reloader, ShouldNotBeNil)
 							So(len(initMock.DoGetHTTPServerCalls()), ShouldEqual, 1)
 							So(initMock.DoGetHTTPServerCalls()[0].BindAddr, ShouldEqual, ":25200")
 						})
@@ -332,6 +339,24 @@ func TestCloseSuccess(t *testing.T) {
 				So(len(serverCloseMock.ShutdownCalls()), ShouldEqual, 1)
 			})
 		})
+
+		Convey("When closing a service that has started its retention job", func() {
+			st, err := store.New(filepath.Join(t.TempDir(), "feedback"))
+			So(err, ShouldBeNil)
+			item, _ := st.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{EmailAddress: "jo@example.com"}}, "en")
+			svc.Retention = retention.New(st, nil, -time.Hour, time.Hour)
+			svc.Retention.Start(ctx)
+
+			err = svc.Close(ctx)
+
+			Convey("Then the job is stopped once it has purged old contact details", func() {
+				So(err, ShouldBeNil)
+				So(svc.Retention.Stop(ctx), ShouldBeNil)
+				stored, err := st.Get(item.ID)
+				So(err, ShouldBeNil)
+				So(stored.Submission.EmailAddress, ShouldBeEmpty)
+			})
+		})
 	})
 }
 
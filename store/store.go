@@ -0,0 +1,405 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// Moderation statuses of stored feedback
+const (
+	StatusNew        = "new"
+	StatusNeedsReply = "needs-reply"
+	StatusActioned   = "actioned"
+	StatusSpam       = "spam"
+)
+
+// Statuses lists every moderation status, in the order they are offered to moderators
+var Statuses = []string{StatusNew, StatusNeedsReply, StatusActioned, StatusSpam}
+
+// IsValidStatus is true when status is one of Statuses
+func IsValidStatus(status string) bool {
+	for _, s := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrNotFound is returned when no feedback is stored with the requested ID
+var ErrNotFound = errors.New("feedback not found")
+
+// ids are the hex encoded time of receipt followed by random bytes, so they sort in the order feedback was received
+var idPattern = regexp.MustCompile(`^[0-9a-f]{24}$`)
+
+// Note is a comment added to feedback by a moderator
+type Note struct {
+	Author    string    `json:"author"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Reply is an email sent to the person who left feedback
+type Reply struct {
+	Author   string    `json:"author"`
+	To       string    `json:"to"`
+	Subject  string    `json:"subject"`
+	Body     string    `json:"body"`
+	Template string    `json:"template,omitempty"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+// StatusChange records a moderator changing the status of feedback
+type StatusChange struct {
+	Author    string    `json:"author"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// Item is a feedback submission kept for moderation
+type Item struct {
+	ID            string                 `json:"id"`
+	Submission    *submission.Submission `json:"submission"`
+	Lang          string                 `json:"lang"`
+	ReceivedAt    time.Time              `json:"received_at"`
+	Status        string                 `json:"status"`
+	Notes         []Note                 `json:"notes,omitempty"`
+	Replies       []Reply                `json:"replies,omitempty"`
+	StatusChanges []StatusChange         `json:"status_changes,omitempty"`
+	Erasure       *Erasure               `json:"erasure,omitempty"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+// Erasure records the contact details of feedback being erased, and the pseudonym linking it to other feedback
+// from the same person if they were pseudonymised
+type Erasure struct {
+	Mode      string    `json:"mode"`
+	Pseudonym string    `json:"pseudonym,omitempty"`
+	Author    string    `json:"author"`
+	ErasedAt  time.Time `json:"erased_at"`
+}
+
+// SetStatus changes the status of the item, recording who changed it
+func (i *Item) SetStatus(status, author string, at time.Time) {
+	if status == i.Status {
+		return
+	}
+	i.StatusChanges = append(i.StatusChanges, StatusChange{Author: author, From: i.Status, To: status, ChangedAt: at})
+	i.Status = status
+}
+
+// Types of feedback, for filtering
+const (
+	TypePage       = "page"
+	TypeWholeSite  = "whole-site"
+	TypeNewService = "new-service"
+)
+
+// Types lists every type of feedback
+var Types = []string{TypePage, TypeWholeSite, TypeNewService}
+
+// Filter restricts the items returned from the store. Zero values are ignored.
+type Filter struct {
+	Since     time.Time
+	Until     time.Time
+	Status    string
+	Type      string
+	URLPrefix string
+	Lang      string
+	// Email address given with the feedback, in any case
+	Email string
+	// Tags that an item must all have
+	Tags []string
+}
+
+// Matches is true when the item was received within the bounds of the filter and has every property it sets
+func (f Filter) Matches(item *Item) bool {
+	if !f.Since.IsZero() && item.ReceivedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !item.ReceivedAt.Before(f.Until) {
+		return false
+	}
+	if f.Status != "" && item.Status != f.Status {
+		return false
+	}
+	if f.Type != "" && item.Type() != f.Type {
+		return false
+	}
+	if f.Lang != "" && item.Lang != f.Lang {
+		return false
+	}
+	if f.URLPrefix == "" && f.Email == "" && len(f.Tags) == 0 {
+		return true
+	}
+
+	if item.Submission == nil {
+		return false
+	}
+	if f.URLPrefix != "" && !strings.HasPrefix(item.Submission.OnsURL, f.URLPrefix) {
+		return false
+	}
+	if f.Email != "" && !strings.EqualFold(item.Submission.EmailAddress, f.Email) {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !slices.Contains(item.Submission.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// Type is whether the feedback is about a page, the whole website or a new service
+func (i *Item) Type() string {
+	switch {
+	case i.Submission == nil:
+		return TypePage
+	case i.Submission.Service != "":
+		return TypeNewService
+	case i.Submission.IsGeneralFeedback != nil && *i.Submission.IsGeneralFeedback:
+		return TypeWholeSite
+	default:
+		return TypePage
+	}
+}
+
+// Store keeps each feedback item as a JSON file in a directory, named so that a directory listing is in the
+// order feedback was received. The directory is shared with the gdpr and export commands, so every change is made
+// while holding a lock on the .lock file inside it.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// New creates a store in dir, creating the directory if it does not exist
+func New(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create feedback store: %w", err)
+	}
+	return &Store{dir: dir, now: time.Now}, nil
+}
+
+// Dir returns the directory the store is kept in
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Add stores a newly received submission
+func (s *Store) Add(ctx context.Context, sub *submission.Submission, lang string) (*Item, error) {
+	now := s.now().UTC()
+	id, err := newID(now)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &Item{
+		ID:         id,
+		Submission: sub,
+		Lang:       lang,
+		ReceivedAt: now,
+		Status:     StatusNew,
+		UpdatedAt:  now,
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := s.write(item); err != nil {
+		return nil, err
+	}
+
+	log.Info(ctx, "feedback stored", log.Data{"id": item.ID})
+	return item, nil
+}
+
+// Get returns the item with the given ID
+func (s *Store) Get(id string) (*Item, error) {
+	if !idPattern.MatchString(id) {
+		return nil, ErrNotFound
+	}
+	return s.read(id + ".json")
+}
+
+// Update applies fn to the item with the given ID and saves the result, unless fn returns an error
+func (s *Store) Update(id string, fn func(*Item) error) (*Item, error) {
+	if !idPattern.MatchString(id) {
+		return nil, ErrNotFound
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	item, err := s.read(id + ".json")
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(item); err != nil {
+		return nil, err
+	}
+	item.ID = id
+	item.UpdatedAt = s.now().UTC()
+
+	if err := s.write(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Walk calls fn for each item matching the filter, oldest first, reading one item at a time. Walking stops at
+// the first error returned by fn.
+func (s *Store) Walk(filter Filter, fn func(*Item) error) error {
+	names, err := s.names()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		item, err := s.read(name)
+		if errors.Is(err, ErrNotFound) {
+			continue // removed since the directory was listed
+		}
+		if err != nil {
+			return err
+		}
+		if !filter.Matches(item) {
+			continue
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Recent returns up to limit items matching the filter, newest first
+func (s *Store) Recent(filter Filter, limit int) ([]*Item, error) {
+	names, err := s.names()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*Item, 0, limit)
+	for i := len(names) - 1; i >= 0 && len(items) < limit; i-- {
+		item, err := s.read(names[i])
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if filter.Matches(item) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// lock stops other goroutines and processes changing the store until the returned function is called
+func (s *Store) lock() (func(), error) {
+	s.mu.Lock()
+	file, err := os.OpenFile(filepath.Join(s.dir, ".lock"), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("failed to open feedback store lock file: %w", err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		s.mu.Unlock()
+		return nil, fmt.Errorf("failed to lock feedback store: %w", err)
+	}
+	return func() {
+		_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+		s.mu.Unlock()
+	}, nil
+}
+
+// names lists the item files in the store, oldest first
+func (s *Store) names() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedback store: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if id, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() && idPattern.MatchString(id) {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+func (s *Store) read(name string) (*Item, error) {
+	b, err := os.ReadFile(filepath.Join(s.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stored feedback: %w", err)
+	}
+
+	var item Item
+	if err := json.Unmarshal(b, &item); err != nil {
+		return nil, fmt.Errorf("failed to decode stored feedback %s: %w", name, err)
+	}
+	return &item, nil
+}
+
+// write replaces the item's file atomically so readers never see a partly written item
+func (s *Store) write(item *Item) error {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode feedback: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, item.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary feedback file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write feedback: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write feedback: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, item.ID+".json")); err != nil {
+		return fmt.Errorf("failed to save feedback: %w", err)
+	}
+	return nil
+}
+
+func newID(receivedAt time.Time) (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate feedback id: %w", err)
+	}
+	return fmt.Sprintf("%016x%s", receivedAt.UnixNano(), hex.EncodeToString(b)), nil
+}
@@ -0,0 +1,268 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/submission"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newSubmission(text string) *submission.Submission {
+	return &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: text}}
+}
+
+// newTestStore creates a store whose clock advances a minute from midday on each call
+func newTestStore(t *testing.T) *Store {
+	s, err := New(filepath.Join(t.TempDir(), "feedback"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	return s
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given an empty store", t, func() {
+		s := newTestStore(t)
+
+		Convey("When a submission is added", func() {
+			item, err := s.Add(ctx, newSubmission("the chart is wrong"), "cy")
+
+			Convey("Then it is stored as new feedback", func() {
+				So(err, ShouldBeNil)
+				So(item.ID, ShouldHaveLength, 24)
+				So(item.Status, ShouldEqual, StatusNew)
+				So(item.Lang, ShouldEqual, "cy")
+			})
+
+			Convey("Then it can be retrieved by its ID", func() {
+				got, err := s.Get(item.ID)
+				So(err, ShouldBeNil)
+				So(got, ShouldResemble, item)
+			})
+
+			Convey("Then it is only readable by its owner", func() {
+				info, err := os.Stat(filepath.Join(s.Dir(), item.ID+".json"))
+				So(err, ShouldBeNil)
+				So(info.Mode().Perm(), ShouldEqual, os.FileMode(0o600))
+			})
+
+			Convey("And it is updated", func() {
+				updated, err := s.Update(item.ID, func(i *Item) error {
+					i.Status = StatusActioned
+					i.Notes = append(i.Notes, Note{Author: "moderator@ons.gov.uk", Text: "fixed"})
+					return nil
+				})
+
+				Convey("Then the change is saved", func() {
+					So(err, ShouldBeNil)
+					So(updated.UpdatedAt, ShouldHappenAfter, item.UpdatedAt)
+					got, _ := s.Get(item.ID)
+					So(got.Status, ShouldEqual, StatusActioned)
+					So(got.Notes, ShouldHaveLength, 1)
+				})
+			})
+
+			Convey("And an update fails", func() {
+				_, err := s.Update(item.ID, func(i *Item) error {
+					i.Status = StatusSpam
+					return errors.New("rejected")
+				})
+
+				Convey("Then the item is unchanged", func() {
+					So(err, ShouldNotBeNil)
+					got, _ := s.Get(item.ID)
+					So(got.Status, ShouldEqual, StatusNew)
+				})
+			})
+		})
+
+		Convey("When an unknown or malformed ID is requested", func() {
+			Convey("Then it is not found", func() {
+				_, err := s.Get("0000000000000000000000ff")
+				So(err, ShouldEqual, ErrNotFound)
+				_, err = s.Get("../../etc/passwd")
+				So(err, ShouldEqual, ErrNotFound)
+				_, err = s.Update("../secret", func(*Item) error { return nil })
+				So(err, ShouldEqual, ErrNotFound)
+			})
+		})
+	})
+
+	Convey("Given a store with several items", t, func() {
+		s := newTestStore(t)
+		first, _ := s.Add(ctx, newSubmission("first"), "en")
+		second, _ := s.Add(ctx, newSubmission("second"), "en")
+		third, _ := s.Add(ctx, newSubmission("third"), "en")
+		_, err := s.Update(second.ID, func(i *Item) error {
+			i.Status = StatusSpam
+			return nil
+		})
+		So(err, ShouldBeNil)
+
+		Convey("When the items are walked", func() {
+			var ids []string
+			err := s.Walk(Filter{}, func(i *Item) error {
+				ids = append(ids, i.ID)
+				return nil
+			})
+
+			Convey("Then they are visited oldest first", func() {
+				So(err, ShouldBeNil)
+				So(ids, ShouldResemble, []string{first.ID, second.ID, third.ID})
+			})
+		})
+
+		Convey("When the most recent items are listed", func() {
+			items, err := s.Recent(Filter{}, 2)
+
+			Convey("Then the newest are returned first, up to the limit", func() {
+				So(err, ShouldBeNil)
+				So(items, ShouldHaveLength, 2)
+				So(items[0].ID, ShouldEqual, third.ID)
+				So(items[1].ID, ShouldEqual, second.ID)
+			})
+		})
+
+		Convey("When the items are filtered by status", func() {
+			items, err := s.Recent(Filter{Status: StatusNew}, 10)
+
+			Convey("Then only items with that status are returned", func() {
+				So(err, ShouldBeNil)
+				So(items, ShouldHaveLength, 2)
+				So(items[0].ID, ShouldEqual, third.ID)
+				So(items[1].ID, ShouldEqual, first.ID)
+			})
+		})
+
+		Convey("When the items are filtered by date", func() {
+			items, err := s.Recent(Filter{Since: second.ReceivedAt, Until: third.ReceivedAt}, 10)
+
+			Convey("Then only items received in that period are returned", func() {
+				So(err, ShouldBeNil)
+				So(items, ShouldHaveLength, 1)
+				So(items[0].ID, ShouldEqual, second.ID)
+			})
+		})
+
+		Convey("When walking stops with an error", func() {
+			stop := errors.New("stop")
+			visited := 0
+			err := s.Walk(Filter{}, func(i *Item) error {
+				visited++
+				return stop
+			})
+
+			Convey("Then the error is returned and no more items are visited", func() {
+				So(err, ShouldEqual, stop)
+				So(visited, ShouldEqual, 1)
+			})
+		})
+	})
+
+	Convey("Given a store of feedback about pages and the whole website", t, func() {
+		s := newTestStore(t)
+		isGeneral, isPage := true, false
+		site, _ := s.Add(ctx, &submission.Submission{
+			Feedback: feedbackAPIModel.Feedback{Feedback: "site", IsGeneralFeedback: &isGeneral},
+		}, "en")
+		census, _ := s.Add(ctx, &submission.Submission{
+			Feedback: feedbackAPIModel.Feedback{Feedback: "census", IsGeneralFeedback: &isPage, OnsURL: "https://www.ons.gov.uk/census/maps", EmailAddress: "Jo@Example.com"},
+			Tags:     []string{"search", "download"},
+		}, "cy")
+		economy, _ := s.Add(ctx, &submission.Submission{
+			Feedback: feedbackAPIModel.Feedback{Feedback: "economy", IsGeneralFeedback: &isPage, OnsURL: "https://www.ons.gov.uk/economy"},
+			Tags:     []string{"search"},
+		}, "en")
+
+		testCases := []struct {
+			description string
+			filter      Filter
+			expected    []string
+		}{
+			{"whole website type", Filter{Type: TypeWholeSite}, []string{site.ID}},
+			{"page type", Filter{Type: TypePage}, []string{census.ID, economy.ID}},
+			{"language", Filter{Lang: "cy"}, []string{census.ID}},
+			{"URL prefix", Filter{URLPrefix: "https://www.ons.gov.uk/census"}, []string{census.ID}},
+			{"email address in any case", Filter{Email: "jo@example.com"}, []string{census.ID}},
+			{"tag", Filter{Tags: []string{"search"}}, []string{census.ID, economy.ID}},
+			{"several tags", Filter{Tags: []string{"search", "download"}}, []string{census.ID}},
+			{"language and tag", Filter{Lang: "en", Tags: []string{"search"}}, []string{economy.ID}},
+		}
+
+		for _, tc := range testCases {
+			Convey("When the items are filtered by "+tc.description, func() {
+				var ids []string
+				err := s.Walk(tc.filter, func(i *Item) error {
+					ids = append(ids, i.ID)
+					return nil
+				})
+
+				Convey("Then only the matching items are visited", func() {
+					So(err, ShouldBeNil)
+					So(ids, ShouldResemble, tc.expected)
+				})
+			})
+		}
+	})
+}
+
+func TestSharedDirectory(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given the service and the gdpr command using the same feedback store", t, func() {
+		dir := filepath.Join(t.TempDir(), "feedback")
+		service, err := New(dir)
+		So(err, ShouldBeNil)
+		command, err := New(dir)
+		So(err, ShouldBeNil)
+		item, err := service.Add(ctx, &submission.Submission{Feedback: feedbackAPIModel.Feedback{Feedback: "census", Name: "Jo", EmailAddress: "jo@example.com"}}, "en")
+		So(err, ShouldBeNil)
+
+		Convey("When the command erases the contact details while the service adds notes", func() {
+			var wg sync.WaitGroup
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					_, _ = service.Update(item.ID, func(i *Item) error {
+						i.Notes = append(i.Notes, Note{Author: "moderator@ons.gov.uk", Text: "checked"})
+						return nil
+					})
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					_, _ = command.Update(item.ID, func(i *Item) error {
+						i.Submission.Name = ""
+						i.Submission.EmailAddress = ""
+						return nil
+					})
+				}
+			}()
+			wg.Wait()
+
+			Convey("Then the erasure is not undone and no note is lost", func() {
+				got, err := command.Get(item.ID)
+				So(err, ShouldBeNil)
+				So(got.Submission.Name, ShouldBeEmpty)
+				So(got.Submission.EmailAddress, ShouldBeEmpty)
+				So(got.Notes, ShouldHaveLength, 50)
+			})
+		})
+	})
+}
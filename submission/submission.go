@@ -0,0 +1,87 @@
+package submission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
+	sdkError "github.com/ONSdigital/dp-feedback-api/sdk/errors"
+)
+
+// Submission is the feedback forwarded to the Feedback API. It extends the API's feedback model with
+// metadata derived by the controller, which is sent alongside the feedback fields.
+type Submission struct {
+	feedbackAPIModel.Feedback
+	Service   string   `json:"service,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	Topic     string   `json:"topic,omitempty"`
+	Subtopic  string   `json:"subtopic,omitempty"`
+	Team      string   `json:"team,omitempty"`
+	Sentiment *float64 `json:"sentiment,omitempty"`
+	Consent   *Consent `json:"consent,omitempty"`
+	Rating    *int     `json:"rating,omitempty"`
+	Variant   string   `json:"variant,omitempty"`
+}
+
+// Consent records a person agreeing to their contact details being used to reply to them
+type Consent struct {
+	GivenAt time.Time `json:"given_at"`
+	// Version of the wording they agreed to
+	Version string `json:"version"`
+}
+
+// Client sends submissions to the Feedback API, reusing the SDK client's URL and HTTP client
+type Client struct {
+	*feedbackAPI.Client
+}
+
+// NewClient wraps a Feedback API SDK client so that submissions, including their metadata, can be posted
+func NewClient(c *feedbackAPI.Client) *Client {
+	return &Client{Client: c}
+}
+
+// PostSubmission sends the provided submission to the Feedback API via a post call
+func (c *Client) PostSubmission(ctx context.Context, s *Submission, options feedbackAPI.Options) *sdkError.StatusError {
+	uri := fmt.Sprintf(feedbackAPI.FeedbackEndpoint, c.URL())
+
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(s); err != nil {
+		return &sdkError.StatusError{
+			Err:  fmt.Errorf("failed to encode submission: %w", err),
+			Code: http.StatusInternalServerError,
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, uri, buf)
+	if err != nil {
+		return &sdkError.StatusError{
+			Err:  fmt.Errorf("error creating request: %w", err),
+			Code: http.StatusInternalServerError,
+		}
+	}
+
+	options.SetAuth(req)
+
+	resp, err := c.Health().Client.Do(ctx, req)
+	if err != nil {
+		return &sdkError.StatusError{
+			Err:  fmt.Errorf("error sending request: %w", err),
+			Code: http.StatusInternalServerError,
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return &sdkError.StatusError{
+			Err:  fmt.Errorf("unexpected status returned from the feedback api post feedback endpoint: %d", resp.StatusCode),
+			Code: resp.StatusCode,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package submission
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	feedbackAPIModel "github.com/ONSdigital/dp-feedback-api/models"
+	feedbackAPI "github.com/ONSdigital/dp-feedback-api/sdk"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPostSubmission(t *testing.T) {
+	ctx := context.Background()
+	isGeneral := true
+	isUseful := false
+
+	s := &Submission{
+		Feedback: feedbackAPIModel.Feedback{
+			IsPageUseful:      &isUseful,
+			IsGeneralFeedback: &isGeneral,
+			Feedback:          "the search does not find CPI",
+		},
+		Tags: []string{"search"},
+	}
+
+	Convey("Given a Feedback API that accepts feedback", t, func() {
+		var gotBody map[string]interface{}
+		var gotAuth, gotPath string
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			gotAuth = req.Header.Get("Authorization")
+			gotPath = req.URL.Path
+			b, _ := io.ReadAll(req.Body)
+			_ = json.Unmarshal(b, &gotBody)
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer api.Close()
+
+		client := NewClient(feedbackAPI.New(api.URL))
+
+		Convey("When a submission is posted", func() {
+			err := client.PostSubmission(ctx, s, feedbackAPI.Options{AuthToken: "token"})
+
+			Convey("Then no error is returned", func() {
+				So(err, ShouldBeNil)
+			})
+
+			Convey("Then the feedback fields and metadata are sent together to the feedback endpoint", func() {
+				So(gotPath, ShouldEqual, "/feedback")
+				So(gotBody["feedback"], ShouldEqual, "the search does not find CPI")
+				So(gotBody["is_general_feedback"], ShouldEqual, true)
+				So(gotBody["tags"], ShouldResemble, []interface{}{"search"})
+			})
+
+			Convey("Then the service auth token is sent", func() {
+				So(gotAuth, ShouldEqual, "Bearer token")
+			})
+		})
+	})
+
+	Convey("Given a Feedback API that rejects feedback", t, func() {
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer api.Close()
+
+		client := NewClient(feedbackAPI.New(api.URL))
+
+		Convey("When a submission is posted", func() {
+			err := client.PostSubmission(ctx, s, feedbackAPI.Options{})
+
+			Convey("Then the status of the response is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Status(), ShouldEqual, http.StatusBadRequest)
+			})
+		})
+	})
+}
@@ -0,0 +1,234 @@
+package tagging
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mapper"
+)
+
+// fallbackLang is the language whose keywords are applied to every submission, as Welsh speakers often write in English
+const fallbackLang = "en"
+
+// Rule assigns Tag to feedback when any of its keywords, patterns or paths match
+type Rule struct {
+	// Tag is the topic assigned to matching feedback
+	Tag string `toml:"tag"`
+	// Keywords are words or phrases, keyed by language, matched as whole words regardless of case
+	Keywords map[string][]string `toml:"keywords"`
+	// Patterns are regular expressions matched against the description regardless of case
+	Patterns []string `toml:"patterns"`
+	// Paths are path.Match patterns for the URL path, a trailing /** also matches everything below it
+	Paths []string `toml:"paths"`
+}
+
+// DefaultRules are used when no rules file is configured
+var DefaultRules = []Rule{
+	{
+		Tag: "data error",
+		Keywords: map[string][]string{
+			"en": {"error", "errors", "incorrect", "wrong", "mistake", "typo", "inconsistent"},
+			"cy": {"gwall", "gwallau", "anghywir", "camgymeriad", "anghyson"},
+		},
+		Patterns: []string{`\bdo(es)?(n't| not) (add up|match)\b`},
+	},
+	{
+		Tag: "accessibility",
+		Keywords: map[string][]string{
+			"en": {"accessibility", "accessible", "screen reader", "screenreader", "contrast", "colour blind", "color blind", "alt text", "keyboard", "zoom"},
+			"cy": {"hygyrchedd", "hygyrch", "darllenydd sgrin", "cyferbyniad", "bysellfwrdd", "dall i liwiau"},
+		},
+	},
+	{
+		Tag: "search",
+		Keywords: map[string][]string{
+			"en": {"search", "searching", "searched", "can't find", "cannot find", "couldn't find", "could not find", "unable to find"},
+			"cy": {"chwilio", "chwiliad", "methu dod o hyd", "methu ffeindio"},
+		},
+		Paths: []string{"/search/**"},
+	},
+	{
+		Tag: "download",
+		Keywords: map[string][]string{
+			"en": {"download", "downloads", "downloading", "csv", "xls", "xlsx", "spreadsheet", "pdf"},
+			"cy": {"lawrlwytho", "lawrlwythiad", "taenlen"},
+		},
+		Paths: []string{"/file", "/*/*/datasets/**", "/*/*/*/datasets/**"},
+	},
+}
+
+type compiledRule struct {
+	tag      string
+	keywords map[string][]string
+	patterns []*regexp.Regexp
+	paths    []string
+}
+
+// Tagger assigns topic tags to feedback using a fixed set of rules
+type Tagger struct {
+	rules []compiledRule
+}
+
+// New validates and compiles rules into a Tagger
+func New(rules []Rule) (*Tagger, error) {
+	t := &Tagger{rules: make([]compiledRule, 0, len(rules))}
+
+	for i, r := range rules {
+		tag := strings.TrimSpace(r.Tag)
+		if tag == "" {
+			return nil, fmt.Errorf("tagging rule %d has no tag", i+1)
+		}
+
+		cr := compiledRule{tag: tag, keywords: make(map[string][]string, len(r.Keywords))}
+		for lang, keywords := range r.Keywords {
+			for _, keyword := range keywords {
+				if normalised := normalise(keyword); normalised != " " {
+					cr.keywords[lang] = append(cr.keywords[lang], normalised)
+				}
+			}
+		}
+		for _, p := range r.Patterns {
+			re, err := regexp.Compile("(?i)" + p)
+			if err != nil {
+				return nil, fmt.Errorf("tagging rule %q has an invalid pattern: %w", tag, err)
+			}
+			cr.patterns = append(cr.patterns, re)
+		}
+		for _, p := range r.Paths {
+			if _, err := path.Match(strings.TrimSuffix(p, "/**"), "/"); err != nil {
+				return nil, fmt.Errorf("tagging rule %q has an invalid path %q: %w", tag, p, err)
+			}
+			cr.paths = append(cr.paths, p)
+		}
+
+		if len(cr.keywords) == 0 && len(cr.patterns) == 0 && len(cr.paths) == 0 {
+			return nil, fmt.Errorf("tagging rule %q has no keywords, patterns or paths", tag)
+		}
+		t.rules = append(t.rules, cr)
+	}
+
+	return t, nil
+}
+
+// LoadRules reads rules from a TOML file containing a [[rule]] table for each rule
+func LoadRules(filename string) ([]Rule, error) {
+	var file struct {
+		Rules []Rule `toml:"rule"`
+	}
+
+	md, err := toml.DecodeFile(filename, &file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tagging rules: %w", err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown tagging rule field %q", undecoded[0].String())
+	}
+	if len(file.Rules) == 0 {
+		return nil, errors.New("tagging rules file contains no rules")
+	}
+	return file.Rules, nil
+}
+
+// Tag returns the tags whose rules match the description or URL, in rule order
+func (t *Tagger) Tag(lang, description, onsURL string) []string {
+	text := normalise(description)
+	urlPath := pathOf(onsURL)
+
+	var tags []string
+	seen := make(map[string]bool)
+	for i := range t.rules {
+		r := &t.rules[i]
+		if seen[r.tag] || !r.matches(lang, description, text, urlPath) {
+			continue
+		}
+		seen[r.tag] = true
+		tags = append(tags, r.tag)
+	}
+	return tags
+}
+
+func (r *compiledRule) matches(lang, description, text, urlPath string) bool {
+	if containsAny(text, r.keywords[lang]) {
+		return true
+	}
+	if lang != fallbackLang && containsAny(text, r.keywords[fallbackLang]) {
+		return true
+	}
+	for _, re := range r.patterns {
+		if re.MatchString(description) {
+			return true
+		}
+	}
+	if urlPath != "" {
+		for _, p := range r.paths {
+			if matchPath(p, urlPath) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsAny(text string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath matches urlPath against pattern, where a trailing /** matches the path and everything below it
+func matchPath(pattern, urlPath string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		for p := urlPath; p != "/" && p != "."; p = path.Dir(p) {
+			if ok, _ := path.Match(prefix, p); ok {
+				return true
+			}
+		}
+		return false
+	}
+	ok, _ := path.Match(pattern, urlPath)
+	return ok
+}
+
+// pathOf returns the cleaned path of onsURL, which, like the form's URL, may have no scheme
+func pathOf(onsURL string) string {
+	u, err := url.Parse(mapper.NormaliseURL(strings.TrimSpace(onsURL)))
+	if err != nil || u.Path == "" {
+		return ""
+	}
+	return path.Clean(u.Path)
+}
+
+// normalise lower-cases text and reduces it to space separated words, padded with a space at each end so
+// keywords only match whole words. Apostrophes are dropped so "can't" and "cant" are treated alike.
+func normalise(text string) string {
+	var b strings.Builder
+	b.Grow(len(text) + 2)
+	b.WriteByte(' ')
+
+	space := true
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == '\'' || r == '’':
+			continue
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			b.WriteRune(r)
+			space = false
+		case !space:
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	if !space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
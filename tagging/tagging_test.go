@@ -0,0 +1,125 @@
+package tagging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDefaultRules(t *testing.T) {
+	Convey("Given a tagger using the default rules", t, func() {
+		tagger, err := New(DefaultRules)
+		So(err, ShouldBeNil)
+
+		testCases := []struct {
+			description string
+			lang        string
+			text        string
+			url         string
+			expected    []string
+		}{
+			{"an English data error", "en", "The total for Wales is WRONG.", "", []string{"data error"}},
+			{"a Welsh data error", "cy", "Mae'r ffigur yn anghywir", "", []string{"data error"}},
+			{"a data error matched by pattern", "en", "The two tables don't match", "", []string{"data error"}},
+			{"an accessibility problem", "en", "My screen reader skips the table", "", []string{"accessibility"}},
+			{"a Welsh accessibility problem", "cy", "Nid yw'r dudalen yn hygyrch", "", []string{"accessibility"}},
+			{"English feedback on the Welsh site", "cy", "I couldn't find the download", "", []string{"search", "download"}},
+			{"a search results page", "en", "nothing useful", "https://www.ons.gov.uk/search?q=cpi", []string{"search"}},
+			{"a search results page without a scheme", "en", "nothing useful", "www.ons.gov.uk/search?q=cpi", []string{"search"}},
+			{"a dataset page", "en", "please add more years", "https://www.ons.gov.uk/economy/inflationandpriceindices/datasets/consumerpriceinflation", []string{"download"}},
+			{"a keyword inside another word", "en", "the researchers were helpful", "", nil},
+			{"feedback matching no rule", "en", "Great website, thank you", "https://www.ons.gov.uk/economy", nil},
+		}
+
+		for _, tc := range testCases {
+			Convey("When tagging "+tc.description, func() {
+				So(tagger.Tag(tc.lang, tc.text, tc.url), ShouldResemble, tc.expected)
+			})
+		}
+
+		Convey("When Welsh keywords are used on the English site", func() {
+			tags := tagger.Tag("en", "gwall", "")
+
+			Convey("Then they are not matched", func() {
+				So(tags, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestNew(t *testing.T) {
+	Convey("Given rules that cannot be compiled", t, func() {
+		testCases := []struct {
+			description string
+			rule        Rule
+		}{
+			{"a rule without a tag", Rule{Keywords: map[string][]string{"en": {"chart"}}}},
+			{"a rule with nothing to match", Rule{Tag: "empty"}},
+			{"a rule with an invalid pattern", Rule{Tag: "bad", Patterns: []string{"(unclosed"}}},
+			{"a rule with an invalid path", Rule{Tag: "bad", Paths: []string{"/[a-"}}},
+		}
+
+		for _, tc := range testCases {
+			Convey("When creating a tagger with "+tc.description, func() {
+				_, err := New([]Rule{tc.rule})
+
+				Convey("Then an error is returned", func() {
+					So(err, ShouldNotBeNil)
+				})
+			})
+		}
+	})
+}
+
+func TestLoadRules(t *testing.T) {
+	Convey("Given a rules file", t, func() {
+		filename := filepath.Join(t.TempDir(), "rules.toml")
+		err := os.WriteFile(filename, []byte(`
+[[rule]]
+tag = "census"
+paths = ["/census/**"]
+patterns = ['\bcensus 20(11|21)\b']
+
+[rule.keywords]
+en = ["census"]
+cy = ["cyfrifiad"]
+`), 0o600)
+		So(err, ShouldBeNil)
+
+		Convey("When the rules are loaded", func() {
+			rules, err := LoadRules(filename)
+
+			Convey("Then every field of the rule is read", func() {
+				So(err, ShouldBeNil)
+				So(rules, ShouldHaveLength, 1)
+				So(rules[0].Tag, ShouldEqual, "census")
+				So(rules[0].Keywords["cy"], ShouldResemble, []string{"cyfrifiad"})
+				So(rules[0].Patterns, ShouldHaveLength, 1)
+				So(rules[0].Paths, ShouldResemble, []string{"/census/**"})
+			})
+
+			Convey("Then they can be used to tag feedback", func() {
+				tagger, err := New(rules)
+				So(err, ShouldBeNil)
+				So(tagger.Tag("cy", "Data'r cyfrifiad", ""), ShouldResemble, []string{"census"})
+				So(tagger.Tag("en", "", "https://www.ons.gov.uk/census/maps"), ShouldResemble, []string{"census"})
+			})
+		})
+	})
+
+	Convey("Given a rules file with a misspelt field", t, func() {
+		filename := filepath.Join(t.TempDir(), "rules.toml")
+		err := os.WriteFile(filename, []byte("[[rule]]\ntag = \"census\"\npath = [\"/census\"]\n"), 0o600)
+		So(err, ShouldBeNil)
+
+		Convey("When the rules are loaded", func() {
+			_, err := LoadRules(filename)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
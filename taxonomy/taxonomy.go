@@ -0,0 +1,91 @@
+package taxonomy
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strings"
+
+	core "github.com/ONSdigital/dis-design-system-go/model"
+	"github.com/ONSdigital/dp-frontend-feedback-controller/mapper"
+)
+
+// lang is the language topics are reported in, so feedback from the English and Welsh sites is grouped together
+const lang = "en"
+
+//go:generate moq -out taxonomy_mock.go -pkg taxonomy . NavigationSource
+
+// NavigationSource provides the site navigation that URLs are classified against
+type NavigationSource interface {
+	GetMappedNavigationContent(ctx context.Context, lang string) ([]core.NavigationItem, error)
+}
+
+// Classification is the topic and subtopic that a URL belongs to, either may be empty
+type Classification struct {
+	Topic    string
+	Subtopic string
+}
+
+// Classifier maps URLs to the topics of the site navigation
+type Classifier struct {
+	Navigation NavigationSource
+}
+
+// NewClassifier creates a Classifier using the navigation from nav
+func NewClassifier(nav NavigationSource) *Classifier {
+	return &Classifier{Navigation: nav}
+}
+
+// Classify returns the topic and subtopic of the navigation that onsURL falls under
+func (c *Classifier) Classify(ctx context.Context, onsURL string) (Classification, error) {
+	urlPath := pathOf(onsURL)
+	if urlPath == "" {
+		return Classification{}, nil
+	}
+
+	items, err := c.Navigation.GetMappedNavigationContent(ctx, lang)
+	if err != nil {
+		return Classification{}, err
+	}
+	return Match(items, urlPath), nil
+}
+
+// Match finds the navigation item with the longest URI that contains urlPath. A match on a subtopic
+// also sets the topic it belongs to.
+func Match(items []core.NavigationItem, urlPath string) Classification {
+	var best Classification
+	bestLen := 0
+
+	for _, topic := range items {
+		if n := prefixLen(topic.Uri, urlPath); n > bestLen {
+			best, bestLen = Classification{Topic: topic.Label}, n
+		}
+		for _, subtopic := range topic.SubItems {
+			if n := prefixLen(subtopic.Uri, urlPath); n > bestLen {
+				best, bestLen = Classification{Topic: topic.Label, Subtopic: subtopic.Label}, n
+			}
+		}
+	}
+	return best
+}
+
+// prefixLen is the length of the path of uri if it is urlPath or one of its parents, otherwise 0
+func prefixLen(uri, urlPath string) int {
+	p := pathOf(uri)
+	if p == "" || p == "/" {
+		return 0
+	}
+	if urlPath == p || strings.HasPrefix(urlPath, p+"/") {
+		return len(p)
+	}
+	return 0
+}
+
+// pathOf returns the lower-cased, cleaned path of rawURL, which, like the form's URL, may have no scheme
+func pathOf(rawURL string) string {
+	u, err := url.Parse(mapper.NormaliseURL(strings.TrimSpace(rawURL)))
+	if err != nil || u.Path == "" {
+		return ""
+	}
+	return strings.ToLower(path.Clean(u.Path))
+}
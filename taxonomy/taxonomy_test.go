@@ -0,0 +1,104 @@
+package taxonomy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	core "github.com/ONSdigital/dis-design-system-go/model"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var navigation = []core.NavigationItem{
+	{
+		Uri:   "/economy",
+		Label: "Economy",
+		SubItems: []core.NavigationItem{
+			{Uri: "/economy/inflationandpriceindices", Label: "Inflation and price indices"},
+			{Uri: "/economy/grossdomesticproductgdp", Label: "Gross Domestic Product (GDP)"},
+		},
+	},
+	{
+		Uri:   "/census",
+		Label: "Census",
+		SubItems: []core.NavigationItem{
+			{Uri: "https://www.ons.gov.uk/census/maps", Label: "Census maps"},
+		},
+	},
+}
+
+func TestMatch(t *testing.T) {
+	Convey("Given the site navigation", t, func() {
+		testCases := []struct {
+			description string
+			path        string
+			expected    Classification
+		}{
+			{"a topic page", "/economy", Classification{Topic: "Economy"}},
+			{"a page below a subtopic", "/economy/inflationandpriceindices/bulletins/consumerpriceinflation/latest", Classification{Topic: "Economy", Subtopic: "Inflation and price indices"}},
+			{"a page below a topic but no subtopic", "/economy/environmentalaccounts", Classification{Topic: "Economy"}},
+			{"a subtopic with an absolute URI", "/census/maps/choropleth", Classification{Topic: "Census", Subtopic: "Census maps"}},
+			{"a page sharing a prefix with a topic", "/economyarchive", Classification{}},
+			{"a page outside the navigation", "/aboutus", Classification{}},
+		}
+
+		for _, tc := range testCases {
+			Convey("When matching "+tc.description, func() {
+				So(Match(navigation, tc.path), ShouldResemble, tc.expected)
+			})
+		}
+	})
+}
+
+func TestClassify(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a classifier backed by the navigation cache", t, func() {
+		nav := &NavigationSourceMock{
+			GetMappedNavigationContentFunc: func(ctx context.Context, lang string) ([]core.NavigationItem, error) {
+				return navigation, nil
+			},
+		}
+		classifier := NewClassifier(nav)
+
+		Convey("When a full ONS URL is classified", func() {
+			c, err := classifier.Classify(ctx, "https://www.ons.gov.uk/Economy/GrossDomesticProductGDP?edition=latest")
+
+			Convey("Then its topic and subtopic are returned using the English navigation", func() {
+				So(err, ShouldBeNil)
+				So(c, ShouldResemble, Classification{Topic: "Economy", Subtopic: "Gross Domestic Product (GDP)"})
+				So(nav.GetMappedNavigationContentCalls()[0].Lang, ShouldEqual, "en")
+			})
+		})
+
+		Convey("When an ONS URL without a scheme is classified", func() {
+			c, err := classifier.Classify(ctx, "www.ons.gov.uk/economy/inflationandpriceindices")
+
+			Convey("Then its topic and subtopic are found from its path", func() {
+				So(err, ShouldBeNil)
+				So(c, ShouldResemble, Classification{Topic: "Economy", Subtopic: "Inflation and price indices"})
+			})
+		})
+
+		Convey("When no URL is given", func() {
+			c, err := classifier.Classify(ctx, "")
+
+			Convey("Then the navigation is not read", func() {
+				So(err, ShouldBeNil)
+				So(c, ShouldResemble, Classification{})
+				So(nav.GetMappedNavigationContentCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the navigation cannot be read", func() {
+			nav.GetMappedNavigationContentFunc = func(ctx context.Context, lang string) ([]core.NavigationItem, error) {
+				return nil, errors.New("cache not ready")
+			}
+			_, err := classifier.Classify(ctx, "https://www.ons.gov.uk/economy")
+
+			Convey("Then the error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,148 @@
+package variant
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CookieName is the cookie holding the variant of the feedback form a user was given, so they see the same one
+// each time
+const CookieName = "feedback_variant"
+
+// The variants of the feedback form that can be tested against each other
+const (
+	// Control is the feedback form as it is without a test
+	Control = "control"
+	// Short leaves out the contact details and asks for the feedback in fewer words
+	Short = "short"
+)
+
+// Names are the variants of the feedback form
+var Names = []string{Control, Short}
+
+// cookiePath limits the cookie to the feedback pages
+const cookiePath = "/feedback"
+
+// cookieMaxAge keeps a user in the same variant for as long as a test is likely to run
+const cookieMaxAge = 90 * 24 * time.Hour
+
+// Variant is a variant of the feedback form given to users in proportion to its Weight
+type Variant struct {
+	Name   string
+	Weight int
+}
+
+// Assigner gives each user a variant of the feedback form, keeping it in a cookie
+type Assigner struct {
+	variants []Variant
+	total    int
+	secure   bool
+	intN     func(n int) int
+}
+
+// Parse reads variants written as `name:weight`, such as `control:50` and `short:50`. A variant with a weight of 0
+// is given to no one.
+func Parse(specs []string) ([]Variant, error) {
+	var variants []Variant
+	var errs []error
+	seen := make(map[string]bool)
+	total := 0
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		name, weight, ok := strings.Cut(spec, ":")
+		if !ok {
+			errs = append(errs, fmt.Errorf("form variant %q: expected name:weight", spec))
+			continue
+		}
+		if !slices.Contains(Names, name) {
+			errs = append(errs, fmt.Errorf("form variant %q: name must be one of %s", spec, strings.Join(Names, ", ")))
+			continue
+		}
+		if seen[name] {
+			errs = append(errs, fmt.Errorf("form variant %q: %s is defined more than once", spec, name))
+			continue
+		}
+		seen[name] = true
+
+		w, err := strconv.Atoi(weight)
+		if err != nil || w < 0 {
+			errs = append(errs, fmt.Errorf("form variant %q: weight must be a whole number of at least 0", spec))
+			continue
+		}
+		total += w
+		variants = append(variants, Variant{Name: name, Weight: w})
+	}
+	if len(variants) > 0 && total == 0 {
+		errs = append(errs, errors.New("form variants: at least one variant must have a weight above 0"))
+	}
+	return variants, errors.Join(errs...)
+}
+
+// New creates an Assigner that gives users one of variants. The cookie is only sent over https when secure is true.
+func New(variants []Variant, secure bool) *Assigner {
+	a := &Assigner{secure: secure, intN: rand.IntN}
+	for _, v := range variants {
+		if v.Weight > 0 {
+			a.variants = append(a.variants, v)
+			a.total += v.Weight
+		}
+	}
+	return a
+}
+
+// Assign returns the variant the user making req was given, giving them one if they do not have one that is still
+// being tested. It returns "" when no variants are being tested.
+func (a *Assigner) Assign(w http.ResponseWriter, req *http.Request) string {
+	if a == nil || a.total == 0 {
+		return ""
+	}
+	if c, err := req.Cookie(CookieName); err == nil && a.IsTested(c.Value) {
+		return c.Value
+	}
+
+	name := a.pick()
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    name,
+		Path:     cookiePath,
+		MaxAge:   int(cookieMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   a.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return name
+}
+
+// IsTested is true when name is a variant that is being given to users
+func (a *Assigner) IsTested(name string) bool {
+	if a == nil {
+		return false
+	}
+	for _, v := range a.variants {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// pick chooses a variant at random in proportion to the variants' weights
+func (a *Assigner) pick() string {
+	n := a.intN(a.total)
+	for _, v := range a.variants {
+		if n < v.Weight {
+			return v.Name
+		}
+		n -= v.Weight
+	}
+	return a.variants[len(a.variants)-1].Name
+}
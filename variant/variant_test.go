@@ -0,0 +1,136 @@
+package variant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParse(t *testing.T) {
+	Convey("Given variants of the form with weights", t, func() {
+		variants, err := Parse([]string{"control:50", " short:50 ", ""})
+
+		Convey("Then each variant is read", func() {
+			So(err, ShouldBeNil)
+			So(variants, ShouldResemble, []Variant{{Name: Control, Weight: 50}, {Name: Short, Weight: 50}})
+		})
+	})
+
+	Convey("Given no variants", t, func() {
+		variants, err := Parse(nil)
+
+		Convey("Then nothing is being tested", func() {
+			So(err, ShouldBeNil)
+			So(variants, ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given variants that are not valid", t, func() {
+		_, err := Parse([]string{"control", "long:10", "short:-1", "control:ten"})
+
+		Convey("Then every problem is reported", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, `"control": expected name:weight`)
+			So(err.Error(), ShouldContainSubstring, `"long:10": name must be one of control, short`)
+			So(err.Error(), ShouldContainSubstring, `"short:-1": weight must be a whole number of at least 0`)
+			So(err.Error(), ShouldContainSubstring, `"control:ten": weight must be a whole number of at least 0`)
+		})
+	})
+
+	Convey("Given a variant defined twice", t, func() {
+		_, err := Parse([]string{"control:1", "control:2"})
+
+		Convey("Then an error is returned", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, `"control:2": control is defined more than once`)
+		})
+	})
+
+	Convey("Given variants that are all given to no one", t, func() {
+		_, err := Parse([]string{"control:0", "short:0"})
+
+		Convey("Then an error is returned", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "at least one variant must have a weight above 0")
+		})
+	})
+}
+
+func TestAssign(t *testing.T) {
+	Convey("Given no variants being tested", t, func() {
+		var a *Assigner
+		w := httptest.NewRecorder()
+
+		Convey("Then users are given no variant and no cookie", func() {
+			So(a.Assign(w, httptest.NewRequest("GET", "/feedback", http.NoBody)), ShouldEqual, "")
+			So(w.Result().Cookies(), ShouldBeEmpty)
+			So(a.IsTested(Control), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given the control and short forms being tested 3 to 1", t, func() {
+		a := New([]Variant{{Name: Control, Weight: 3}, {Name: Short, Weight: 1}}, true)
+
+		Convey("Then both variants are being tested", func() {
+			So(a.IsTested(Control), ShouldBeTrue)
+			So(a.IsTested(Short), ShouldBeTrue)
+			So(a.IsTested("long"), ShouldBeFalse)
+		})
+
+		Convey("When the random number picks each variant", func() {
+			picks := map[int]string{}
+			for n := 0; n < 4; n++ {
+				a.intN = func(int) int { return n }
+				picks[n] = a.Assign(httptest.NewRecorder(), httptest.NewRequest("GET", "/feedback", http.NoBody))
+			}
+
+			Convey("Then users are split by the variants' weights", func() {
+				So(picks, ShouldResemble, map[int]string{0: Control, 1: Control, 2: Control, 3: Short})
+			})
+		})
+
+		Convey("When a new user opens the form", func() {
+			a.intN = func(int) int { return 3 }
+			w := httptest.NewRecorder()
+			name := a.Assign(w, httptest.NewRequest("GET", "/feedback", http.NoBody))
+
+			Convey("Then they are given a variant in a cookie that is only sent over https", func() {
+				So(name, ShouldEqual, Short)
+				cookies := w.Result().Cookies()
+				So(cookies, ShouldHaveLength, 1)
+				So(cookies[0].Name, ShouldEqual, CookieName)
+				So(cookies[0].Value, ShouldEqual, Short)
+				So(cookies[0].Path, ShouldEqual, "/feedback")
+				So(cookies[0].HttpOnly, ShouldBeTrue)
+				So(cookies[0].Secure, ShouldBeTrue)
+
+				Convey("And they keep it when they come back", func() {
+					a.intN = func(int) int { return 0 }
+					req := httptest.NewRequest("GET", "/feedback", http.NoBody)
+					req.AddCookie(cookies[0])
+					w := httptest.NewRecorder()
+					So(a.Assign(w, req), ShouldEqual, Short)
+					So(w.Result().Cookies(), ShouldBeEmpty)
+				})
+			})
+		})
+	})
+
+	Convey("Given a test where the short form is no longer given out", t, func() {
+		a := New([]Variant{{Name: Control, Weight: 1}, {Name: Short, Weight: 0}}, false)
+
+		Convey("When a user comes back with the short form in their cookie", func() {
+			req := httptest.NewRequest("GET", "/feedback", http.NoBody)
+			req.AddCookie(&http.Cookie{Name: CookieName, Value: Short})
+			w := httptest.NewRecorder()
+			name := a.Assign(w, req)
+
+			Convey("Then they are moved to a variant still being tested", func() {
+				So(name, ShouldEqual, Control)
+				So(w.Result().Cookies()[0].Value, ShouldEqual, Control)
+			})
+		})
+	})
+}
@@ -0,0 +1,100 @@
+// Embeds the ONS feedback form in a page. Add an element where the form should appear and include this script
+// from the feedback controller:
+//
+//   <div data-ons-feedback-widget></div>
+//   <script src="https://www.ons.gov.uk/feedback/widget.js" defer></script>
+//
+// Feedback is sent to the JSON API and a thank you message shown in place of the form. Set data-submit="form" on
+// the element to post the form to the controller's feedback page instead.
+(function () {
+  'use strict';
+
+  var script = document.currentScript;
+  if (!script || !window.fetch || !window.FormData) {
+    return;
+  }
+
+  var base = new URL(script.src).origin;
+  var lang = (document.documentElement.lang || '').slice(0, 2) === 'cy' ? 'cy' : 'en';
+
+  function load(container) {
+    var url = base + '/feedback/widget?lang=' + lang + '&url=' + encodeURIComponent(window.location.href);
+    fetch(url)
+      .then(function (res) {
+        if (!res.ok) {
+          throw new Error('feedback widget returned ' + res.status);
+        }
+        return res.text();
+      })
+      .then(function (html) {
+        container.innerHTML = html;
+        var form = container.querySelector('form');
+        form.action = base + '/feedback';
+        if (container.getAttribute('data-submit') !== 'form') {
+          form.addEventListener('submit', function (event) {
+            event.preventDefault();
+            submit(container, form);
+          });
+        }
+      })
+      .catch(function (err) {
+        console.error(err);
+      });
+  }
+
+  function submit(container, form) {
+    var data = new FormData(form);
+    var body = {
+      type: data.get('type'),
+      url: data.get('url'),
+      description: data.get('description'),
+      name: data.get('name'),
+      email: data.get('email'),
+      consent: data.get('consent') === 'true',
+      idempotency_key: data.get('idempotency-key'),
+    };
+
+    fetch(base + '/feedback/api?lang=' + lang, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(body),
+    })
+      .then(function (res) {
+        return res.json().then(function (result) {
+          return { ok: res.ok, errors: result.errors || [] };
+        });
+      })
+      .then(function (response) {
+        if (!response.ok) {
+          showErrors(container, response.errors);
+          return;
+        }
+        form.hidden = true;
+        var thanks = container.querySelector('[data-feedback-widget-thanks]');
+        thanks.hidden = false;
+        thanks.focus();
+      })
+      .catch(function () {
+        var panel = container.querySelector('[data-feedback-widget-errors]');
+        showErrors(container, [{ message: panel.getAttribute('data-send-failed') }]);
+      });
+  }
+
+  function showErrors(container, errors) {
+    var panel = container.querySelector('[data-feedback-widget-errors]');
+    var list = panel.querySelector('ul');
+    list.innerHTML = '';
+    errors.forEach(function (error) {
+      var item = document.createElement('li');
+      item.textContent = error.message;
+      list.appendChild(item);
+    });
+    panel.hidden = errors.length === 0;
+    panel.focus();
+  }
+
+  var containers = document.querySelectorAll('[data-ons-feedback-widget]');
+  for (var i = 0; i < containers.length; i++) {
+    load(containers[i]);
+  }
+})();
@@ -0,0 +1,29 @@
+package widget
+
+import (
+	_ "embed"
+	"net/url"
+	"strings"
+)
+
+// Loader is the script that other frontends include to embed the feedback widget in their pages
+//
+//go:embed loader.js
+var Loader []byte
+
+// IsAllowedOrigin reports whether pages served from origin may embed the widget and submit feedback through it.
+// Pages on siteDomain or any of its subdomains are allowed over https, as are origins listed exactly in allowed.
+func IsAllowedOrigin(origin string, allowed []string, siteDomain string) bool {
+	for _, a := range allowed {
+		if strings.EqualFold(origin, strings.TrimSuffix(a, "/")) {
+			return true
+		}
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme != "https" || u.Path != "" || siteDomain == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == siteDomain || strings.HasSuffix(host, "."+siteDomain)
+}
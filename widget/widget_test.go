@@ -0,0 +1,44 @@
+package widget
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIsAllowedOrigin(t *testing.T) {
+	Convey("Given the site domain and a list of other allowed origins", t, func() {
+		allowed := []string{"http://localhost:20000", "https://beta.example.com/"}
+
+		Convey("Then https pages on the site domain and its subdomains are allowed", func() {
+			So(IsAllowedOrigin("https://ons.gov.uk", allowed, "ons.gov.uk"), ShouldBeTrue)
+			So(IsAllowedOrigin("https://www.ons.gov.uk", allowed, "ons.gov.uk"), ShouldBeTrue)
+			So(IsAllowedOrigin("https://cy.ons.gov.uk:443", allowed, "ons.gov.uk"), ShouldBeTrue)
+		})
+
+		Convey("Then listed origins are allowed", func() {
+			So(IsAllowedOrigin("http://localhost:20000", allowed, "ons.gov.uk"), ShouldBeTrue)
+			So(IsAllowedOrigin("https://beta.example.com", allowed, "ons.gov.uk"), ShouldBeTrue)
+		})
+
+		Convey("Then other origins are not allowed", func() {
+			for _, origin := range []string{
+				"",
+				"null",
+				"http://www.ons.gov.uk",
+				"https://notons.gov.uk",
+				"https://ons.gov.uk.example.com",
+				"http://localhost:20001",
+				"https://example.com",
+			} {
+				So(IsAllowedOrigin(origin, allowed, "ons.gov.uk"), ShouldBeFalse)
+			}
+		})
+	})
+
+	Convey("Given no site domain", t, func() {
+		Convey("Then only listed origins are allowed", func() {
+			So(IsAllowedOrigin("https://www.ons.gov.uk", nil, ""), ShouldBeFalse)
+		})
+	})
+}